@@ -0,0 +1,198 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gorilla/websocket"
+)
+
+// Tiempos del heartbeat: el servidor envía un ping cada pingPeriod y corta la
+// conexión si no recibe nada (ni siquiera el pong) durante pongWait
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+	sendBuffer     = 256
+	maxNameLength  = 50
+)
+
+// newUpgrader crea el upgrader que convierte la petición HTTP en WebSocket.
+// El navegador no aplica CORS a los WebSocket, así que checkOrigin hace de
+// política de orígenes
+func newUpgrader(checkOrigin func(*http.Request) bool) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			handlers.WriteProblem(w, r, models.NewProblem(status, models.CodeUpgradeFailed,
+				"No se pudo abrir el WebSocket: "+reason.Error()))
+		},
+	}
+}
+
+// client representa una conexión WebSocket. Sus campos de presencia solo los
+// toca la goroutine del hub
+type client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+	// ctx tiene el usuario y el workspace de la conexión, para consultar el store
+	ctx context.Context
+
+	id        string
+	owner     int
+	workspace int
+	name      string
+	todoID    int
+	// audience son los usuarios que pueden ver todoID
+	audience []int
+	state    string
+}
+
+// ServeHTTP abre el canal de colaboración del usuario de la sesión. El nombre
+// que verán sus otras pestañas es el de la cuenta; sin cuenta se toma de ?name=
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if user, ok := store.UserFrom(r.Context()); ok {
+		name = user.Name
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade ya respondió el error
+		return
+	}
+
+	// La conexión dura más que la petición del upgrade, pero sigue siendo del
+	// mismo usuario en el mismo workspace
+	ctx := context.Background()
+	workspace, _ := store.WorkspaceFrom(r.Context())
+	ctx = store.WithWorkspace(ctx, workspace)
+	if user, ok := store.UserFrom(r.Context()); ok {
+		ctx = store.WithUser(ctx, user)
+	}
+
+	c := &client{
+		hub:       h,
+		conn:      conn,
+		send:      make(chan []byte, sendBuffer),
+		ctx:       ctx,
+		id:        "c" + strconv.FormatInt(h.newClientID(), 10),
+		owner:     store.UserID(r.Context()),
+		workspace: workspace.ID,
+		name:      displayName(name),
+		state:     models.PresenceOnline,
+	}
+
+	select {
+	case h.register <- c:
+	case <-h.done:
+		conn.Close()
+		return
+	}
+
+	go c.writePump()
+	go c.readPump()
+}
+
+// watchers son los usuarios que se enteran de la presencia del cliente: el
+// suyo y quienes pueden ver el todo que mira
+func (c *client) watchers() []int {
+	if contains(c.audience, c.owner) {
+		return c.audience
+	}
+	return append([]int{c.owner}, c.audience...)
+}
+
+// participant describe la presencia del cliente
+func (c *client) participant() models.Participant {
+	return models.Participant{ClientID: c.id, Name: c.name, TodoID: c.todoID, State: c.state}
+}
+
+// readPump lee los mensajes del cliente y se los pasa al hub
+func (c *client) readPump() {
+	defer func() {
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+		}
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+
+		// Un mensaje que no es JSON no corta la conexión: el hub responde un error
+		var msg models.CollabMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			msg = models.CollabMessage{}
+		}
+
+		select {
+		case c.hub.inbound <- inbound{client: c, message: msg}:
+		case <-c.hub.done:
+			return
+		}
+	}
+}
+
+// writePump envía al cliente los mensajes del hub y los pings del heartbeat
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// El hub cerró el canal: el cliente se desconectó o no daba abasto
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// displayName limpia el nombre elegido por el cliente
+func displayName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "Anónimo"
+	}
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+	return name
+}
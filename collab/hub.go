@@ -0,0 +1,480 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+	"todo-list/events"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gorilla/websocket"
+)
+
+// sweepInterval es cada cuánto se revisan los bloqueos vencidos
+const sweepInterval = time.Second
+
+// inbound es un mensaje recibido de un cliente
+type inbound struct {
+	client  *client
+	message models.CollabMessage
+}
+
+// lockKey identifica un bloqueo: hay uno por todo del workspace, lo edite
+// su dueño o cualquier miembro de su lista
+type lockKey struct {
+	workspace int
+	todoID    int
+}
+
+// heldLock es un bloqueo vigente, quiénes podían ver el todo al tomarlo y el
+// contexto de quien lo tiene, para volver a consultarlo
+type heldLock struct {
+	lock     models.EditLock
+	audience []int
+	ctx      context.Context
+}
+
+// Hub reparte los cambios, la presencia y los bloqueos de edición entre los
+// clientes conectados de quienes pueden ver cada todo: su dueño o los miembros
+// de su lista. Todo su estado lo maneja una sola goroutine (Start), así que no
+// necesita locks y escala a cientos de conexiones en el mismo proceso
+type Hub struct {
+	todos    *store.TodoStore
+	broker   *events.Broker
+	lockTTL  time.Duration
+	upgrader websocket.Upgrader
+
+	register   chan *client
+	unregister chan *client
+	inbound    chan inbound
+	done       chan struct{}
+
+	clients map[*client]struct{}
+	locks   map[lockKey]*heldLock
+	nextID  int64
+	now     func() time.Time
+}
+
+// NewHub crea un hub que retransmite los cambios publicados en broker y
+// consulta en todos quién puede ver cada todo. Un bloqueo de edición vence si
+// no se renueva dentro de lockTTL. checkOrigin decide desde qué orígenes se
+// puede abrir el canal
+func NewHub(todos *store.TodoStore, broker *events.Broker, lockTTL time.Duration, checkOrigin func(*http.Request) bool) *Hub {
+	return &Hub{
+		todos:      todos,
+		broker:     broker,
+		lockTTL:    lockTTL,
+		upgrader:   newUpgrader(checkOrigin),
+		register:   make(chan *client),
+		unregister: make(chan *client),
+		inbound:    make(chan inbound, 256),
+		done:       make(chan struct{}),
+		clients:    make(map[*client]struct{}),
+		locks:      make(map[lockKey]*heldLock),
+		now:        time.Now,
+	}
+}
+
+// Start ejecuta el hub en segundo plano hasta que se cancele ctx
+func (h *Hub) Start(ctx context.Context) {
+	go h.run(ctx)
+}
+
+// run es el bucle principal del hub
+func (h *Hub) run(ctx context.Context) {
+	sub := h.broker.Subscribe(0)
+	lastID := sub.LastID
+	sweep := time.NewTicker(sweepInterval)
+	defer func() {
+		sweep.Stop()
+		h.broker.Unsubscribe(sub)
+		for c := range h.clients {
+			delete(h.clients, c)
+			close(c.send)
+		}
+		close(h.done)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-h.register:
+			h.add(c)
+		case c := <-h.unregister:
+			h.remove(c)
+		case in := <-h.inbound:
+			h.handle(in.client, in.message)
+		case event, ok := <-sub.Events:
+			if !ok {
+				// El broker nos desconectó por lentos: retomar desde el último cambio
+				sub = h.broker.Subscribe(lastID)
+				for _, missed := range sub.Missed {
+					h.change(missed)
+					lastID = missed.ID
+				}
+				continue
+			}
+			h.change(event)
+			lastID = event.ID
+		case <-sweep.C:
+			h.expireLocks()
+		}
+	}
+}
+
+// add registra un cliente, le envía el estado actual y avisa a los demás
+func (h *Hub) add(c *client) {
+	h.clients[c] = struct{}{}
+	h.send(c, models.CollabMessage{
+		Type:         models.CollabWelcome,
+		ClientID:     c.id,
+		Participants: h.participants(c.owner),
+		Locks:        h.lockList(c.workspace, c.owner),
+	})
+	h.broadcastPresence(c, nil)
+}
+
+// remove desconecta un cliente, libera sus bloqueos y avisa a los demás
+func (h *Hub) remove(c *client) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	close(c.send)
+
+	h.refresh(c)
+	h.releaseAll(c)
+	c.state = models.PresenceLeft
+	h.broadcastPresence(c, nil)
+}
+
+// handle procesa un mensaje de un cliente
+func (h *Hub) handle(c *client, msg models.CollabMessage) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+
+	switch msg.Type {
+	case models.CollabView, models.CollabEdit, models.CollabRelease:
+		h.refresh(c)
+	}
+
+	switch msg.Type {
+	case models.CollabView:
+		audience, ok := h.audience(c, msg.TodoID)
+		if !ok {
+			return
+		}
+		if msg.TodoID != c.todoID {
+			h.releaseAll(c)
+		}
+		previous := c.audience
+		c.todoID, c.audience = msg.TodoID, audience
+		c.state = models.PresenceOnline
+		if c.todoID > 0 {
+			c.state = models.PresenceViewing
+		}
+		h.broadcastPresence(c, previous)
+	case models.CollabEdit:
+		if msg.TodoID <= 0 {
+			h.sendError(c, "todo_id es requerido para editar")
+			return
+		}
+		h.acquire(c, msg.TodoID)
+	case models.CollabRelease:
+		key := lockKey{c.workspace, msg.TodoID}
+		if held, ok := h.locks[key]; ok && held.lock.ClientID == c.id {
+			h.release(key)
+			c.state = models.PresenceViewing
+			h.broadcastPresence(c, nil)
+		}
+	case models.CollabPing:
+		h.send(c, models.CollabMessage{Type: models.CollabPong})
+	case "":
+		h.sendError(c, "Mensaje inválido: se espera un objeto JSON con type")
+	default:
+		h.sendError(c, "Tipo de mensaje desconocido: "+msg.Type)
+	}
+}
+
+// audience obtiene quiénes pueden ver el todo que c quiere mirar o editar;
+// si c no lo puede ver le avisa y retorna false. El todo 0 es ninguno
+func (h *Hub) audience(c *client, todoID int) ([]int, bool) {
+	if todoID == 0 {
+		return nil, true
+	}
+	audience, err := h.todos.Audience(c.ctx, todoID)
+	if err != nil {
+		h.sendError(c, "Todo no encontrado")
+		return nil, false
+	}
+	return audience, true
+}
+
+// acquire toma o renueva el bloqueo de edición de un todo para c; si lo
+// tiene otra persona, aunque sea de otra cuenta de la lista, le avisa a c quién
+func (h *Hub) acquire(c *client, todoID int) {
+	audience, ok := h.audience(c, todoID)
+	if !ok {
+		return
+	}
+	now := h.now()
+	key := lockKey{c.workspace, todoID}
+	if held, ok := h.locks[key]; ok && held.lock.ClientID != c.id && now.Before(held.lock.ExpiresAt) {
+		denied := held.lock
+		h.send(c, models.CollabMessage{Type: models.CollabLockDenied, TodoID: todoID, Lock: &denied})
+		return
+	}
+
+	if c.todoID != todoID {
+		h.releaseAll(c)
+	}
+	_, renewed := h.locks[key]
+	held := &heldLock{
+		lock:     models.EditLock{TodoID: todoID, ClientID: c.id, Name: c.name, ExpiresAt: now.Add(h.lockTTL)},
+		audience: audience,
+		ctx:      c.ctx,
+	}
+	h.locks[key] = held
+
+	copied := held.lock
+	h.broadcast(audience, models.CollabMessage{Type: models.CollabLock, TodoID: todoID, Lock: &copied})
+	if !renewed || c.state != models.PresenceEditing {
+		previous := c.audience
+		c.todoID, c.audience = todoID, audience
+		c.state = models.PresenceEditing
+		h.broadcastPresence(c, previous)
+	}
+}
+
+// release libera un bloqueo y avisa a quienes pueden ver el todo
+func (h *Hub) release(key lockKey) {
+	held, ok := h.locks[key]
+	if !ok {
+		return
+	}
+	delete(h.locks, key)
+	h.broadcast(h.lockAudience(key, held), models.CollabMessage{Type: models.CollabUnlock, TodoID: key.todoID})
+}
+
+// lockAudience vuelve a consultar quiénes pueden ver el todo bloqueado, porque
+// la membresía de la lista puede cambiar sin que cambie el todo. Si quien lo
+// tiene ya no lo ve (o el todo se eliminó) se usa la del momento del bloqueo
+func (h *Hub) lockAudience(key lockKey, held *heldLock) []int {
+	if audience, err := h.todos.Audience(held.ctx, key.todoID); err == nil {
+		held.audience = audience
+	}
+	return held.audience
+}
+
+// refresh vuelve a consultar quiénes pueden ver el todo que mira c. Si c lo
+// perdió de vista (salió de la lista) suelta su bloqueo y deja de mirarlo
+func (h *Hub) refresh(c *client) {
+	if c.todoID == 0 {
+		return
+	}
+	audience, err := h.todos.Audience(c.ctx, c.todoID)
+	if err != nil {
+		h.releaseAll(c)
+		c.todoID, c.audience = 0, nil
+		if c.state != models.PresenceLeft {
+			c.state = models.PresenceOnline
+		}
+		return
+	}
+	c.audience = audience
+}
+
+// releaseAll libera los bloqueos que tenga c
+func (h *Hub) releaseAll(c *client) {
+	for key, held := range h.locks {
+		if held.lock.ClientID == c.id {
+			h.release(key)
+		}
+	}
+}
+
+// expireLocks libera los bloqueos que no se renovaron a tiempo
+func (h *Hub) expireLocks() {
+	now := h.now()
+	for key, held := range h.locks {
+		if !now.Before(held.lock.ExpiresAt) {
+			h.release(key)
+			for c := range h.clients {
+				if c.id == held.lock.ClientID && c.state == models.PresenceEditing {
+					c.state = models.PresenceViewing
+					h.broadcastPresence(c, nil)
+				}
+			}
+		}
+	}
+}
+
+// change retransmite un cambio del store a los clientes de quienes pueden ver
+// el todo. Si el todo ya no está en la lista se libera su bloqueo; si cambió
+// quién lo ve (un miembro salió de la lista) se actualizan la presencia y el
+// bloqueo, y quien lo perdió de vista deja de mirarlo
+func (h *Hub) change(event models.ChangeEvent) {
+	h.broadcast(event.Audience, models.CollabMessage{Type: models.CollabChange, TodoID: event.TodoID, Event: &event})
+
+	key := lockKey{event.WorkspaceID, event.TodoID}
+	if event.Type == models.EventTodoDeleted || event.Type == models.EventTodoArchived {
+		h.release(key)
+		return
+	}
+	if held, ok := h.locks[key]; ok {
+		held.audience = event.Audience
+	}
+	for c := range h.clients {
+		if c.workspace != event.WorkspaceID || c.todoID != event.TodoID || sameUsers(c.audience, event.Audience) {
+			continue
+		}
+		previous := c.audience
+		c.audience = event.Audience
+		if !contains(event.Audience, c.owner) {
+			h.releaseAll(c)
+			c.todoID, c.audience = 0, nil
+			c.state = models.PresenceOnline
+		}
+		h.broadcastPresence(c, previous)
+	}
+}
+
+// broadcastPresence avisa qué está haciendo c a sus otras conexiones y a
+// quienes pueden ver el todo que mira. Quienes veían el todo anterior
+// (previous) pero no el actual solo se enteran de que c dejó de mirarlo
+func (h *Hub) broadcastPresence(c *client, previous []int) {
+	watchers := c.watchers()
+	participant := c.participant()
+	h.broadcast(watchers, models.CollabMessage{Type: models.CollabPresence, TodoID: c.todoID, Participant: &participant})
+
+	var gone []int
+	for _, user := range previous {
+		if !contains(watchers, user) {
+			gone = append(gone, user)
+		}
+	}
+	if len(gone) > 0 {
+		hidden := participant
+		hidden.TodoID = 0
+		if hidden.State != models.PresenceLeft {
+			hidden.State = models.PresenceOnline
+		}
+		h.broadcast(gone, models.CollabMessage{Type: models.CollabPresence, Participant: &hidden})
+	}
+}
+
+// broadcast envía msg a los clientes de los usuarios indicados. El mensaje se
+// serializa una sola vez; los clientes que no dan abasto se desconectan
+func (h *Hub) broadcast(users []int, msg models.CollabMessage) {
+	if len(users) == 0 {
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("collab: no se pudo serializar %s: %v", msg.Type, err)
+		return
+	}
+
+	var slow []*client
+	for c := range h.clients {
+		if !contains(users, c.owner) {
+			continue
+		}
+		select {
+		case c.send <- data:
+		default:
+			slow = append(slow, c)
+		}
+	}
+	for _, c := range slow {
+		h.remove(c)
+	}
+}
+
+// send envía msg solo a c
+func (h *Hub) send(c *client, msg models.CollabMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("collab: no se pudo serializar %s: %v", msg.Type, err)
+		return
+	}
+
+	select {
+	case c.send <- data:
+	default:
+		h.remove(c)
+	}
+}
+
+// sendError avisa a c que su mensaje no se pudo procesar
+func (h *Hub) sendError(c *client, message string) {
+	h.send(c, models.CollabMessage{Type: models.CollabError, Message: message})
+}
+
+// participants obtiene la presencia que puede ver user: sus otras conexiones
+// y quienes miran un todo que user también ve, ordenada por ID
+func (h *Hub) participants(user int) []models.Participant {
+	participants := make([]models.Participant, 0, len(h.clients))
+	for c := range h.clients {
+		if c.owner != user && contains(c.audience, user) {
+			h.refresh(c)
+		}
+		if contains(c.watchers(), user) {
+			participants = append(participants, c.participant())
+		}
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].ClientID < participants[j].ClientID
+	})
+	return participants
+}
+
+// lockList obtiene los bloqueos vigentes del workspace sobre todos que user
+// puede ver, ordenados por todo
+func (h *Hub) lockList(workspace, user int) []models.EditLock {
+	locks := make([]models.EditLock, 0, len(h.locks))
+	for key, held := range h.locks {
+		if key.workspace == workspace && contains(h.lockAudience(key, held), user) {
+			locks = append(locks, held.lock)
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].TodoID < locks[j].TodoID
+	})
+	return locks
+}
+
+// newClientID genera el identificador de una conexión
+func (h *Hub) newClientID() int64 {
+	return atomic.AddInt64(&h.nextID, 1)
+}
+
+// contains indica si user está entre users
+func contains(users []int, user int) bool {
+	for _, id := range users {
+		if id == user {
+			return true
+		}
+	}
+	return false
+}
+
+// sameUsers indica si a y b tienen los mismos usuarios, en cualquier orden
+func sameUsers(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, user := range a {
+		if !contains(b, user) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config representa la configuración de la aplicación leída del entorno
+type Config struct {
+	// ArchiveAfter es la antigüedad que debe tener una tarea completada
+	// para archivarse automáticamente; cero desactiva el archivado
+	ArchiveAfter time.Duration
+	// ArchiveInterval es cada cuánto se ejecuta el archivado automático
+	ArchiveInterval time.Duration
+	// RequireIfMatch exige el header If-Match en PUT/PATCH/DELETE de un todo
+	RequireIfMatch bool
+	// IdempotencyTTL es cuánto tiempo se recuerda la respuesta de cada Idempotency-Key
+	IdempotencyTTL time.Duration
+	// EventHistory es cuántos cambios recientes se guardan para retomar el feed de eventos
+	EventHistory int
+	// EventHeartbeat es cada cuánto se envía un comentario para mantener abierto el stream SSE
+	EventHeartbeat time.Duration
+	// CollabLockTTL es cuánto dura un bloqueo de edición si el cliente no lo renueva
+	CollabLockTTL time.Duration
+	// WebhookMaxAttempts es cuántas veces se intenta una entrega antes de pasarla a dead letters
+	WebhookMaxAttempts int
+	// WebhookRetryBase es la espera antes del primer reintento; se duplica en cada uno
+	WebhookRetryBase time.Duration
+	// WebhookRetryMax es la espera máxima entre reintentos
+	WebhookRetryMax time.Duration
+	// WebhookTimeout es cuánto se espera la respuesta del receptor de un webhook
+	WebhookTimeout time.Duration
+	// WebhookAllowPrivate permite webhooks a direcciones privadas, locales o
+	// de metadatos de la nube; solo para desarrollo
+	WebhookAllowPrivate bool
+	// GRPCPort es el puerto de la API gRPC; cero la desactiva
+	GRPCPort int
+	// SessionTTL es cuánto dura una sesión iniciada
+	SessionTTL time.Duration
+	// SessionSecure marca la cookie de sesión como Secure; activarlo detrás de HTTPS
+	SessionSecure bool
+	// OIDCIssuer es la URL del proveedor OIDC para el inicio de sesión único;
+	// vacía lo desactiva
+	OIDCIssuer string
+	// OIDCClientID y OIDCClientSecret identifican a la aplicación ante el
+	// proveedor; el secreto es opcional para clientes públicos con PKCE
+	OIDCClientID     string
+	OIDCClientSecret string
+	// OIDCRedirectURL es la URL de /auth/oidc/callback registrada en el proveedor
+	OIDCRedirectURL string
+	// OIDCScopes son los scopes que se piden al iniciar sesión
+	OIDCScopes []string
+	// OIDCAudience es el aud que deben traer los access tokens JWT de la API;
+	// debe ser distinto del client ID. Vacío desactiva los access tokens JWT
+	OIDCAudience string
+	// Workspaces son los workspaces (equipos cliente) que atiende el servidor,
+	// cada uno como "slug" o "slug=límite de todos"
+	Workspaces []string
+	// WorkspaceMaxTodos es el límite de todos de los workspaces que no indican
+	// uno; cero es sin límite
+	WorkspaceMaxTodos int
+	// WorkspaceDomain es el dominio base para reconocer el workspace por
+	// subdominio (acme.todo.example.com); vacío solo usa el header X-Workspace
+	WorkspaceDomain string
+	// WorkspaceDefault es el workspace de las peticiones que no indican
+	// ninguno; si no está entre Workspaces, todas deben indicarlo
+	WorkspaceDefault string
+	// CORSOrigins son los orígenes que pueden llamar a la API desde el
+	// navegador: exactos ("https://app.example.com"), con comodín de
+	// subdominio ("https://*.example.com") o "*"; vacío solo admite el mismo origen
+	CORSOrigins []string
+	// CORSMethods y CORSHeaders son los métodos y headers que se permiten
+	// en las peticiones de otro origen
+	CORSMethods []string
+	CORSHeaders []string
+	// CORSMaxAge es cuánto puede guardar el navegador la respuesta a un preflight
+	CORSMaxAge time.Duration
+	// CORSCredentials permite enviar la cookie de sesión desde otro origen;
+	// no aplica con el origen "*"
+	CORSCredentials bool
+	// RateLimitAuth es el límite de registros e inicios de sesión por IP,
+	// como "20/m"; "0" lo desactiva
+	RateLimitAuth string
+	// RateLimitAPI es el límite de peticiones de cada cliente (token, usuario
+	// o IP) a la API
+	RateLimitAPI string
+	// RateLimitWrite es el límite aparte de las peticiones que cambian datos
+	RateLimitWrite string
+	// TrustProxy toma la IP del cliente del header X-Forwarded-For; activarlo
+	// solo detrás de un proxy que lo escriba
+	TrustProxy bool
+	// MaxBodyBytes es el tamaño máximo del cuerpo de una petición; cero no
+	// lo limita
+	MaxBodyBytes int64
+}
+
+// Load lee la configuración desde las variables de entorno
+func Load() Config {
+	return Config{
+		ArchiveAfter:    time.Duration(getInt("ARCHIVE_AFTER_DAYS", 30)) * 24 * time.Hour,
+		ArchiveInterval: getDuration("ARCHIVE_INTERVAL", time.Hour),
+		RequireIfMatch:  getBool("REQUIRE_IF_MATCH", false),
+		IdempotencyTTL:  getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		EventHistory:    getInt("EVENTS_HISTORY", 1000),
+		EventHeartbeat:  getDuration("EVENTS_HEARTBEAT", 25*time.Second),
+		CollabLockTTL:   getDuration("COLLAB_LOCK_TTL", 30*time.Second),
+
+		WebhookMaxAttempts:  getInt("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookRetryBase:    getDuration("WEBHOOK_RETRY_BASE", 10*time.Second),
+		WebhookRetryMax:     getDuration("WEBHOOK_RETRY_MAX", time.Hour),
+		WebhookTimeout:      getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookAllowPrivate: getBool("WEBHOOK_ALLOW_PRIVATE", false),
+
+		GRPCPort: getInt("GRPC_PORT", 9090),
+
+		SessionTTL:    getDuration("SESSION_TTL", 7*24*time.Hour),
+		SessionSecure: getBool("SESSION_SECURE", false),
+
+		OIDCIssuer:       getString("OIDC_ISSUER", ""),
+		OIDCClientID:     getString("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getString("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getString("OIDC_REDIRECT_URL", "http://localhost:8080/auth/oidc/callback"),
+		OIDCScopes:       strings.Fields(getString("OIDC_SCOPES", "openid email profile")),
+		OIDCAudience:     getString("OIDC_AUDIENCE", ""),
+
+		Workspaces:        strings.Split(getString("WORKSPACES", "default"), ","),
+		WorkspaceMaxTodos: getInt("WORKSPACE_MAX_TODOS", 0),
+		WorkspaceDomain:   getString("WORKSPACE_DOMAIN", ""),
+		WorkspaceDefault:  getString("WORKSPACE_DEFAULT", "default"),
+
+		CORSOrigins:     getList("CORS_ORIGINS", ""),
+		CORSMethods:     getList("CORS_METHODS", "GET,POST,PUT,PATCH,DELETE"),
+		CORSHeaders:     getList("CORS_HEADERS", "Content-Type,Authorization,If-Match,If-None-Match,Idempotency-Key,X-Workspace,HX-Request,HX-Current-URL,HX-Target,HX-Trigger"),
+		CORSMaxAge:      getDuration("CORS_MAX_AGE", 10*time.Minute),
+		CORSCredentials: getBool("CORS_CREDENTIALS", true),
+
+		RateLimitAuth:  getString("RATE_LIMIT_AUTH", "20/m"),
+		RateLimitAPI:   getString("RATE_LIMIT_API", "600/m"),
+		RateLimitWrite: getString("RATE_LIMIT_WRITE", "120/m"),
+		TrustProxy:     getBool("TRUST_PROXY", false),
+
+		MaxBodyBytes: int64(getInt("MAX_BODY_BYTES", 1<<20)),
+	}
+}
+
+// getString obtiene un texto del entorno o el valor por defecto
+func getString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getList obtiene una lista separada por comas del entorno o del valor por
+// defecto, sin espacios ni elementos vacíos
+func getList(key, fallback string) []string {
+	var list []string
+	for _, item := range strings.Split(getString(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getInt obtiene un entero del entorno o el valor por defecto
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q), usando %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// getBool obtiene un booleano ("true", "1", "false", "0") del entorno o el valor por defecto
+func getBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q), usando %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getDuration obtiene una duración (ej. "1h", "15m") del entorno o el valor por defecto
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  %s inválido (%q), usando %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
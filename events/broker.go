@@ -0,0 +1,122 @@
+package events
+
+import (
+	"sync"
+	"time"
+	"todo-list/models"
+)
+
+// subscriberBuffer es cuántos eventos puede tener pendientes un cliente antes
+// de que se lo desconecte por lento; al reconectar retoma con Last-Event-ID
+const subscriberBuffer = 64
+
+// Subscription representa un cliente conectado al feed de cambios
+type Subscription struct {
+	// Events recibe los cambios nuevos; se cierra si el cliente se queda atrás
+	Events <-chan models.ChangeEvent
+	// Missed son los cambios posteriores al Last-Event-ID del cliente
+	Missed []models.ChangeEvent
+	// Reset indica que el Last-Event-ID ya no está en el historial y el
+	// cliente debe recargar el estado completo
+	Reset bool
+	// LastID es el ID del último cambio publicado al momento de suscribirse
+	LastID int64
+
+	events chan models.ChangeEvent
+}
+
+// Broker reparte los cambios del store a los clientes conectados y guarda
+// los últimos para que un cliente pueda retomar desde Last-Event-ID
+type Broker struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []models.ChangeEvent
+	historySize int
+	heartbeat   time.Duration
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBroker crea un broker que recuerda los últimos historySize cambios y
+// envía un comentario cada heartbeat para mantener abiertas las conexiones
+func NewBroker(historySize int, heartbeat time.Duration) *Broker {
+	return &Broker{
+		history:     make([]models.ChangeEvent, 0, historySize),
+		historySize: historySize,
+		heartbeat:   heartbeat,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish asigna un ID al cambio, lo guarda en el historial y lo envía a los
+// clientes conectados sin bloquear
+func (b *Broker) Publish(event models.ChangeEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event.ID = b.lastID
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+		b.history = append(b.history, event)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// El cliente no da abasto: se lo desconecta para no frenar al resto
+			close(sub.events)
+			delete(b.subscribers, sub)
+		}
+	}
+}
+
+// Subscribe registra un cliente que ya vio los cambios hasta lastID (cero si
+// es la primera conexión). Hay que llamar a Unsubscribe al terminar
+func (b *Broker) Subscribe(lastID int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan models.ChangeEvent, subscriberBuffer)
+	sub := &Subscription{Events: events, LastID: b.lastID, events: events}
+	if lastID > 0 {
+		sub.Missed, sub.Reset = b.since(lastID)
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe desconecta un cliente
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		close(sub.events)
+		delete(b.subscribers, sub)
+	}
+}
+
+// since obtiene los cambios posteriores a lastID; reset es true si faltan
+// cambios en el historial (o el ID es de antes de reiniciar el servidor).
+// Requiere tener el lock tomado
+func (b *Broker) since(lastID int64) (missed []models.ChangeEvent, reset bool) {
+	if lastID > b.lastID {
+		return nil, true
+	}
+	if lastID == b.lastID {
+		return nil, false
+	}
+	if len(b.history) == 0 || b.history[0].ID > lastID+1 {
+		return nil, true
+	}
+
+	for _, event := range b.history {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+	return missed, false
+}
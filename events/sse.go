@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// Nombres de los eventos SSE que recibe el cliente
+const (
+	// EventChange trae un models.ChangeEvent
+	EventChange = "change"
+	// EventReset avisa que se perdieron cambios y hay que recargar la lista
+	EventReset = "reset"
+)
+
+// retryInterval es cuánto espera el navegador antes de reconectar
+const retryInterval = 3 * time.Second
+
+// ServeHTTP transmite los cambios de los todos del usuario como Server-Sent
+// Events. Si el cliente reconecta con Last-Event-ID (o ?last_event_id=) recibe
+// primero los cambios que se perdió
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		handlers.WriteProblem(w, r, models.NewProblem(http.StatusInternalServerError, models.CodeInternal,
+			"El servidor no soporta streaming"))
+		return
+	}
+
+	lastID, err := lastEventID(r)
+	if err != nil {
+		problem := models.NewValidationProblem([]models.FieldError{{
+			Field:   "Last-Event-ID",
+			Code:    models.FieldInvalid,
+			Message: "Last-Event-ID debe ser un número",
+		}})
+		handlers.WriteProblem(w, r, problem)
+		return
+	}
+
+	owner := store.UserID(r.Context())
+	sub := b.Subscribe(lastID)
+	defer b.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryInterval.Milliseconds())
+	if sub.Reset {
+		writeReset(w, sub.LastID)
+	}
+	for _, event := range sub.Missed {
+		if event.VisibleTo(owner) {
+			writeChange(w, event)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(b.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			if !event.VisibleTo(owner) {
+				continue
+			}
+			writeChange(w, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// lastEventID lee el último ID que vio el cliente; cero si es la primera conexión
+func lastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// writeChange escribe un cambio en formato SSE
+func writeChange(w http.ResponseWriter, event models.ChangeEvent) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, EventChange, data)
+}
+
+// writeReset pide al cliente recargar; el id hace que la próxima reconexión
+// retome desde el último cambio publicado
+func writeReset(w http.ResponseWriter, lastID int64) {
+	id := ""
+	if lastID > 0 {
+		id = strconv.FormatInt(lastID, 10)
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: {\"last_event_id\":%d}\n\n", id, EventReset, lastID)
+}
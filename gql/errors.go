@@ -0,0 +1,85 @@
+package gql
+
+import (
+	"context"
+	"todo-list/models"
+	"todo-list/validation"
+)
+
+// contextKey es el tipo de las claves que el handler guarda en el contexto
+type contextKey int
+
+const (
+	// languageKey guarda el idioma de los mensajes de validación
+	languageKey contextKey = iota
+	// writeLimitKey guarda el límite de escrituras del cliente
+	writeLimitKey
+)
+
+// WriteLimit consume una escritura del límite del cliente; si lo superó
+// retorna false y el problema que responde la API REST
+type WriteLimit func() (models.Problem, bool)
+
+// WithWriteLimit guarda en ctx el límite de escrituras que consume cada
+// mutación. Las consultas no lo consumen aunque lleguen por POST
+func WithWriteLimit(ctx context.Context, limit WriteLimit) context.Context {
+	return context.WithValue(ctx, writeLimitKey, limit)
+}
+
+// chargeWrite consume una escritura del límite guardado en ctx, si hay uno
+func chargeWrite(ctx context.Context) error {
+	limit, ok := ctx.Value(writeLimitKey).(WriteLimit)
+	if !ok {
+		return nil
+	}
+	if problem, ok := limit(); !ok {
+		return newError(problem.Status, problem.Code, problem.Detail)
+	}
+	return nil
+}
+
+// withLanguage guarda en ctx el idioma de los mensajes
+func withLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey, lang)
+}
+
+// language obtiene el idioma de los mensajes guardado en ctx
+func language(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey).(string); ok {
+		return lang
+	}
+	return validation.DefaultLang
+}
+
+// resolverError es un error de un resolver. GraphQL lo publica en errors con
+// el código estable y el status equivalente de la API REST en extensions
+type resolverError struct {
+	status  int
+	code    string
+	message string
+	fields  []models.FieldError
+}
+
+// newError crea un error de resolver
+func newError(status int, code, message string) *resolverError {
+	return &resolverError{status: status, code: code, message: message}
+}
+
+// validationError crea el error de una entrada inválida con el detalle por campo
+func validationError(errs []models.FieldError) *resolverError {
+	problem := models.NewValidationProblem(errs)
+	return &resolverError{status: problem.Status, code: problem.Code, message: problem.Detail, fields: errs}
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+// Extensions implementa la interfaz que usa graphql-go para completar el error
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.code, "status": e.status}
+	if len(e.fields) > 0 {
+		extensions["errors"] = e.fields
+	}
+	return extensions
+}
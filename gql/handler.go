@@ -0,0 +1,78 @@
+package gql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"todo-list/events"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+	"todo-list/validation"
+
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// schemaSDL es el schema de la API en SDL; se publica tal cual en GET /graphql/schema
+//
+//go:embed schema.graphql
+var schemaSDL string
+
+// maxDepth limita el anidamiento de las consultas
+const maxDepth = 10
+
+// Handler atiende /graphql: consultas y mutaciones por POST, y suscripciones
+// (también consultas) por WebSocket con el protocolo graphql-transport-ws
+type Handler struct {
+	schema   *graphql.Schema
+	upgrader websocket.Upgrader
+}
+
+// NewHandler crea el handler de GraphQL sobre el store y el feed de cambios
+// compartidos. Con requireVersion las mutaciones de un todo exigen version,
+// igual que If-Match con REQUIRE_IF_MATCH. checkOrigin decide desde qué
+// orígenes se pueden abrir suscripciones
+func NewHandler(todoStore *store.TodoStore, broker *events.Broker, requireVersion bool, checkOrigin func(*http.Request) bool) *Handler {
+	resolver := &Resolver{store: todoStore, broker: broker, requireVersion: requireVersion}
+	return &Handler{
+		schema:   graphql.MustParseSchema(schemaSDL, resolver, graphql.MaxDepth(maxDepth)),
+		upgrader: newUpgrader(checkOrigin),
+	}
+}
+
+// ServeHTTP ejecuta una operación GraphQL o abre el canal de suscripciones
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		handlers.WriteProblem(w, r, models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody,
+			"Las operaciones GraphQL se envían por POST; las suscripciones, por WebSocket"))
+		return
+	}
+
+	var req models.GraphQLRequest
+	if err := handlers.DecodeJSON(r, &req); err != nil {
+		handlers.WriteProblem(w, r, handlers.InvalidBodyProblem(err))
+		return
+	}
+	lang := validation.Language(r.Header.Get("Accept-Language"))
+	if errs := validation.Struct(&req, lang); len(errs) > 0 {
+		handlers.WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+
+	ctx := withLanguage(r.Context(), lang)
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ServeSchema publica el schema en SDL para generar tipos en el cliente
+func ServeSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(schemaSDL))
+}
@@ -0,0 +1,391 @@
+package gql
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-list/events"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+	"todo-list/validation"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// maxPageSize es el máximo de todos por página; el valor por defecto está en el schema
+const maxPageSize = 100
+
+// maxStatsDays es el rango máximo del burndown, el mismo que en GET /stats
+const maxStatsDays = 365
+
+// cursorPrefix identifica los cursores de todos antes de codificarlos
+const cursorPrefix = "todo:"
+
+// Resolver resuelve las operaciones raíz del schema sobre el mismo store que
+// usan los handlers REST
+type Resolver struct {
+	store          *store.TodoStore
+	broker         *events.Broker
+	requireVersion bool
+}
+
+// todoFilter son los filtros de Query.todos
+type todoFilter struct {
+	Completed *bool
+	Tag       *string
+	Search    *string
+	Archived  bool
+}
+
+// todoInput es el input de createTodo y updateTodo
+type todoInput struct {
+	Title       string
+	Description *string
+	Completed   *bool
+	Estimate    *int32
+	Tags        *[]string
+	Checklist   *[]checklistInput
+	DueDate     *graphql.Time
+	ListID      *int32
+	AssigneeID  *int32
+	Watchers    *[]int32
+}
+
+// checklistInput es un paso del checklist dentro de todoInput
+type checklistInput struct {
+	Text string
+	Done *bool
+}
+
+// Todos obtiene una página de todos que cumplen el filtro, ordenados por ID
+func (r *Resolver) Todos(ctx context.Context, args struct {
+	Filter *todoFilter
+	First  int32
+	After  *string
+}) (*connectionResolver, error) {
+	first := int(args.First)
+	if first < 0 || first > maxPageSize {
+		return nil, newError(http.StatusBadRequest, models.CodeInvalidQuery,
+			"first debe estar entre 0 y "+strconv.Itoa(maxPageSize))
+	}
+	afterID := 0
+	if args.After != nil {
+		id, err := decodeCursor(*args.After)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, models.CodeInvalidQuery, "Cursor after inválido")
+		}
+		afterID = id
+	}
+
+	filter := todoFilter{}
+	if args.Filter != nil {
+		filter = *args.Filter
+	}
+	var todos []models.Todo
+	if filter.Archived {
+		todos = r.store.ListArchived(ctx, "")
+	} else {
+		todos = r.store.List(ctx)
+	}
+	matched := make([]models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if filter.matches(todo) {
+			matched = append(matched, todo)
+		}
+	}
+
+	start := 0
+	for start < len(matched) && matched[start].ID <= afterID {
+		start++
+	}
+	end := start + first
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return &connectionResolver{total: len(matched), todos: matched[start:end], hasNext: end < len(matched)}, nil
+}
+
+// Todo obtiene un todo por ID; nil si no existe
+func (r *Resolver) Todo(ctx context.Context, args struct{ ID int32 }) *todoResolver {
+	todo, err := r.store.Get(ctx, int(args.ID))
+	if err != nil {
+		return nil
+	}
+	return &todoResolver{todo}
+}
+
+// Tags cuenta cuántos todos de la lista usan cada etiqueta, en orden de aparición
+func (r *Resolver) Tags(ctx context.Context) []*tagCountResolver {
+	counts := make(map[string]*tagCountResolver)
+	tags := make([]*tagCountResolver, 0)
+	for _, todo := range r.store.List(ctx) {
+		for _, tag := range todo.Tags {
+			if count, ok := counts[tag]; ok {
+				count.count++
+				continue
+			}
+			count := &tagCountResolver{tag: tag, count: 1}
+			counts[tag] = count
+			tags = append(tags, count)
+		}
+	}
+	return tags
+}
+
+// Stats obtiene las estadísticas de estimación y avance
+func (r *Resolver) Stats(ctx context.Context, args struct{ Days int32 }) (*statsResolver, error) {
+	days := int(args.Days)
+	if days < 1 || days > maxStatsDays {
+		return nil, newError(http.StatusBadRequest, models.CodeInvalidQuery,
+			"El parámetro days debe ser un número entre 1 y "+strconv.Itoa(maxStatsDays))
+	}
+	return &statsResolver{r.store.Stats(ctx, days)}, nil
+}
+
+// CreateTodo crea un todo con las mismas reglas que POST /todos
+func (r *Resolver) CreateTodo(ctx context.Context, args struct{ Input todoInput }) (*todoResolver, error) {
+	if err := requireWrite(ctx); err != nil {
+		return nil, err
+	}
+	req := args.Input.request()
+	if errs := validation.Struct(&req, language(ctx)); len(errs) > 0 {
+		return nil, validationError(errs)
+	}
+
+	todo, err := r.store.Create(ctx, req)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &todoResolver{todo}, nil
+}
+
+// UpdateTodo reemplaza un todo con las mismas reglas que PUT /todos/{id};
+// version cumple el papel de If-Match
+func (r *Resolver) UpdateTodo(ctx context.Context, args struct {
+	ID      int32
+	Input   todoInput
+	Version *int32
+}) (*todoResolver, error) {
+	if err := requireWrite(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.checkVersion(args.Version); err != nil {
+		return nil, err
+	}
+	req := args.Input.request()
+	if errs := validation.Struct(&req, language(ctx)); len(errs) > 0 {
+		return nil, validationError(errs)
+	}
+
+	var todo models.Todo
+	var err error
+	if args.Version != nil {
+		todo, err = r.store.UpdateIf(ctx, int(args.ID), req, versionIs(*args.Version))
+	} else {
+		todo, err = r.store.Update(ctx, int(args.ID), req)
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &todoResolver{todo}, nil
+}
+
+// DeleteTodo elimina un todo como DELETE /todos/{id} y retorna su ID
+func (r *Resolver) DeleteTodo(ctx context.Context, args struct {
+	ID      int32
+	Version *int32
+}) (int32, error) {
+	if err := requireWrite(ctx); err != nil {
+		return 0, err
+	}
+	if err := r.checkVersion(args.Version); err != nil {
+		return 0, err
+	}
+
+	var err error
+	if args.Version != nil {
+		err = r.store.DeleteIf(ctx, int(args.ID), versionIs(*args.Version))
+	} else {
+		err = r.store.Delete(ctx, int(args.ID))
+	}
+	if err != nil {
+		return 0, storeError(err)
+	}
+	return args.ID, nil
+}
+
+// TodoChanged transmite los cambios de los todos del usuario hasta que el
+// cliente cancele la suscripción. Si el broker desconecta al suscriptor por lento, se retoma
+// desde el último cambio recibido
+func (r *Resolver) TodoChanged(ctx context.Context, args struct{ Types *[]string }) <-chan *changeResolver {
+	wanted := make(map[string]bool)
+	if args.Types != nil {
+		for _, eventType := range *args.Types {
+			wanted[eventType] = true
+		}
+	}
+
+	owner := store.UserID(ctx)
+	changes := make(chan *changeResolver)
+	sub := r.broker.Subscribe(0)
+	go func() {
+		defer func() {
+			r.broker.Unsubscribe(sub)
+			close(changes)
+		}()
+
+		lastID := sub.LastID
+		var backlog []models.ChangeEvent
+		for {
+			var event models.ChangeEvent
+			if len(backlog) > 0 {
+				event, backlog = backlog[0], backlog[1:]
+			} else {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-sub.Events:
+					if !ok {
+						sub = r.broker.Subscribe(lastID)
+						backlog = sub.Missed
+						continue
+					}
+					event = e
+				}
+			}
+			lastID = event.ID
+
+			if !event.VisibleTo(owner) || len(wanted) > 0 && !wanted[event.Type] {
+				continue
+			}
+			select {
+			case changes <- &changeResolver{event}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes
+}
+
+// requireWrite exige el scope todos:write a las mutaciones hechas con un token
+// personal o un JWT; con la sesión iniciada no hay scopes. Se revisa por
+// operación y no por método HTTP, como readMethods en la API gRPC, y por lo
+// mismo cada mutación consume una escritura del límite del cliente
+func requireWrite(ctx context.Context) error {
+	token, ok := store.TokenFrom(ctx)
+	if ok && !token.HasScope(models.ScopeTodosWrite) {
+		return newError(http.StatusForbidden, models.CodeInsufficientScope, "El token no tiene el scope "+models.ScopeTodosWrite)
+	}
+	return chargeWrite(ctx)
+}
+
+// checkVersion exige version cuando el servidor corre con REQUIRE_IF_MATCH
+func (r *Resolver) checkVersion(version *int32) error {
+	if r.requireVersion && version == nil {
+		return newError(http.StatusPreconditionRequired, models.CodeIfMatchRequired,
+			"Se requiere version para modificar un todo")
+	}
+	return nil
+}
+
+// versionIs es la precondición equivalente a If-Match
+func versionIs(version int32) store.Precondition {
+	return func(current models.Todo) bool {
+		return current.Version == int(version)
+	}
+}
+
+// matches indica si el todo cumple el filtro
+func (f todoFilter) matches(todo models.Todo) bool {
+	if f.Completed != nil && todo.Completed != *f.Completed {
+		return false
+	}
+	if f.Tag != nil && !hasTag(todo, *f.Tag) {
+		return false
+	}
+	if f.Search != nil {
+		search := strings.ToLower(strings.TrimSpace(*f.Search))
+		if search != "" &&
+			!strings.Contains(strings.ToLower(todo.Title), search) &&
+			!strings.Contains(strings.ToLower(todo.Description), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTag indica si el todo tiene la etiqueta
+func hasTag(todo models.Todo, tag string) bool {
+	for _, t := range todo.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// request traduce el input de GraphQL a la petición que entiende el store
+func (in todoInput) request() models.TodoRequest {
+	req := models.TodoRequest{Title: in.Title}
+	if in.Description != nil {
+		req.Description = *in.Description
+	}
+	if in.Completed != nil {
+		req.Completed = *in.Completed
+	}
+	if in.Estimate != nil {
+		req.Estimate = int(*in.Estimate)
+	}
+	if in.Tags != nil {
+		req.Tags = append([]string{}, *in.Tags...)
+	}
+	if in.Checklist != nil {
+		req.Checklist = make([]models.ChecklistItem, 0, len(*in.Checklist))
+		for _, item := range *in.Checklist {
+			req.Checklist = append(req.Checklist, models.ChecklistItem{Text: item.Text, Done: item.Done != nil && *item.Done})
+		}
+	}
+	if in.DueDate != nil {
+		dueDate := in.DueDate.Time
+		req.DueDate = &dueDate
+	}
+	if in.ListID != nil {
+		listID := int(*in.ListID)
+		req.ListID = &listID
+	}
+	if in.AssigneeID != nil {
+		assigneeID := int(*in.AssigneeID)
+		req.AssigneeID = &assigneeID
+	}
+	if in.Watchers != nil {
+		req.Watchers = make([]int, 0, len(*in.Watchers))
+		for _, watcher := range *in.Watchers {
+			req.Watchers = append(req.Watchers, int(watcher))
+		}
+	}
+	return req
+}
+
+// encodeCursor genera el cursor opaco de un todo
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+// decodeCursor obtiene el ID del todo de un cursor
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+}
+
+// storeError traduce un error del store al mismo código que usa la API REST
+func storeError(err error) error {
+	problem := handlers.ProblemFromError(err)
+	return newError(problem.Status, problem.Code, problem.Detail)
+}
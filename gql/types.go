@@ -0,0 +1,200 @@
+package gql
+
+import (
+	"strconv"
+	"time"
+	"todo-list/models"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// todoResolver expone un models.Todo con los nombres y tipos del schema
+type todoResolver struct {
+	todo models.Todo
+}
+
+func (r *todoResolver) ID() int32                  { return int32(r.todo.ID) }
+func (r *todoResolver) Title() string              { return r.todo.Title }
+func (r *todoResolver) Description() string        { return r.todo.Description }
+func (r *todoResolver) DescriptionHTML() string    { return r.todo.DescriptionHTML }
+func (r *todoResolver) Completed() bool            { return r.todo.Completed }
+func (r *todoResolver) Estimate() int32            { return int32(r.todo.Estimate) }
+func (r *todoResolver) Archived() bool             { return r.todo.Archived }
+func (r *todoResolver) Version() int32             { return int32(r.todo.Version) }
+func (r *todoResolver) DueDate() *graphql.Time     { return timePtr(r.todo.DueDate) }
+func (r *todoResolver) CompletedAt() *graphql.Time { return timePtr(r.todo.CompletedAt) }
+func (r *todoResolver) ArchivedAt() *graphql.Time  { return timePtr(r.todo.ArchivedAt) }
+func (r *todoResolver) CreatedAt() graphql.Time    { return graphql.Time{Time: r.todo.CreatedAt} }
+func (r *todoResolver) UpdatedAt() graphql.Time    { return graphql.Time{Time: r.todo.UpdatedAt} }
+
+// ListID obtiene la lista compartida del todo; null si es personal
+func (r *todoResolver) ListID() *int32 {
+	if r.todo.ListID == 0 {
+		return nil
+	}
+	id := int32(r.todo.ListID)
+	return &id
+}
+
+// AssigneeID obtiene el responsable del todo; null si no tiene
+func (r *todoResolver) AssigneeID() *int32 {
+	if r.todo.AssigneeID == 0 {
+		return nil
+	}
+	id := int32(r.todo.AssigneeID)
+	return &id
+}
+
+// Watchers obtiene los IDs de los observadores; nunca es null
+func (r *todoResolver) Watchers() []int32 {
+	watchers := make([]int32, len(r.todo.Watchers))
+	for i, watcher := range r.todo.Watchers {
+		watchers[i] = int32(watcher)
+	}
+	return watchers
+}
+
+// Tags obtiene las etiquetas; nunca es null
+func (r *todoResolver) Tags() []string {
+	if r.todo.Tags == nil {
+		return []string{}
+	}
+	return r.todo.Tags
+}
+
+// Checklist obtiene los pasos del todo; nunca es null
+func (r *todoResolver) Checklist() []*checklistResolver {
+	items := make([]*checklistResolver, 0, len(r.todo.Checklist))
+	for _, item := range r.todo.Checklist {
+		items = append(items, &checklistResolver{item})
+	}
+	return items
+}
+
+// checklistResolver expone un paso del checklist
+type checklistResolver struct {
+	item models.ChecklistItem
+}
+
+func (r *checklistResolver) Text() string { return r.item.Text }
+func (r *checklistResolver) Done() bool   { return r.item.Done }
+
+// connectionResolver es una página de Query.todos
+type connectionResolver struct {
+	total   int
+	todos   []models.Todo
+	hasNext bool
+}
+
+func (r *connectionResolver) TotalCount() int32 { return int32(r.total) }
+
+// Nodes obtiene los todos de la página
+func (r *connectionResolver) Nodes() []*todoResolver {
+	nodes := make([]*todoResolver, 0, len(r.todos))
+	for _, todo := range r.todos {
+		nodes = append(nodes, &todoResolver{todo})
+	}
+	return nodes
+}
+
+// PageInfo indica si hay más páginas y desde dónde seguir
+func (r *connectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNext: r.hasNext}
+	if len(r.todos) > 0 {
+		cursor := encodeCursor(r.todos[len(r.todos)-1].ID)
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+// pageInfoResolver describe la paginación de una página
+type pageInfoResolver struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool  { return r.hasNext }
+func (r *pageInfoResolver) EndCursor() *string { return r.endCursor }
+
+// tagCountResolver es una etiqueta con cuántos todos la usan
+type tagCountResolver struct {
+	tag   string
+	count int
+}
+
+func (r *tagCountResolver) Tag() string  { return r.tag }
+func (r *tagCountResolver) Count() int32 { return int32(r.count) }
+
+// statsResolver expone models.Stats
+type statsResolver struct {
+	stats models.Stats
+}
+
+func (r *statsResolver) Total() int32             { return int32(r.stats.Total) }
+func (r *statsResolver) Pending() int32           { return int32(r.stats.Pending) }
+func (r *statsResolver) Completed() int32         { return int32(r.stats.Completed) }
+func (r *statsResolver) EstimateTotal() int32     { return int32(r.stats.EstimateTotal) }
+func (r *statsResolver) EstimateCompleted() int32 { return int32(r.stats.EstimateCompleted) }
+func (r *statsResolver) EstimateRemaining() int32 { return int32(r.stats.EstimateRemaining) }
+
+// Throughput obtiene las tareas completadas por semana
+func (r *statsResolver) Throughput() []*throughputResolver {
+	weeks := make([]*throughputResolver, 0, len(r.stats.Throughput))
+	for _, week := range r.stats.Throughput {
+		weeks = append(weeks, &throughputResolver{week})
+	}
+	return weeks
+}
+
+// Burndown obtiene el trabajo completado y restante por día
+func (r *statsResolver) Burndown() []*burndownResolver {
+	points := make([]*burndownResolver, 0, len(r.stats.Burndown))
+	for _, point := range r.stats.Burndown {
+		points = append(points, &burndownResolver{point})
+	}
+	return points
+}
+
+// throughputResolver expone una semana del throughput
+type throughputResolver struct {
+	week models.WeeklyThroughput
+}
+
+func (r *throughputResolver) WeekStart() graphql.Time { return graphql.Time{Time: r.week.WeekStart} }
+func (r *throughputResolver) Completed() int32        { return int32(r.week.Completed) }
+func (r *throughputResolver) Points() int32           { return int32(r.week.Points) }
+
+// burndownResolver expone un día del burndown
+type burndownResolver struct {
+	point models.BurndownPoint
+}
+
+func (r *burndownResolver) Date() graphql.Time { return graphql.Time{Time: r.point.Date} }
+func (r *burndownResolver) Remaining() int32   { return int32(r.point.Remaining) }
+func (r *burndownResolver) Completed() int32   { return int32(r.point.Completed) }
+
+// changeResolver expone un cambio del feed de eventos
+type changeResolver struct {
+	event models.ChangeEvent
+}
+
+func (r *changeResolver) ID() string       { return strconv.FormatInt(r.event.ID, 10) }
+func (r *changeResolver) Type() string     { return r.event.Type }
+func (r *changeResolver) TodoID() int32    { return int32(r.event.TodoID) }
+func (r *changeResolver) At() graphql.Time { return graphql.Time{Time: r.event.At} }
+
+// Todo obtiene el estado del todo después del cambio; nil si se eliminó
+func (r *changeResolver) Todo() *todoResolver {
+	if r.event.Todo == nil {
+		return nil
+	}
+	return &todoResolver{*r.event.Todo}
+}
+
+// timePtr convierte una fecha opcional al escalar Time
+func timePtr(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
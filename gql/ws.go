@@ -0,0 +1,305 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+	"todo-list/validation"
+
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// subprotocol es el protocolo de suscripciones que se habla por el WebSocket
+// (el de la librería graphql-ws)
+const subprotocol = "graphql-transport-ws"
+
+// Tipos de mensaje de graphql-transport-ws
+const (
+	msgConnectionInit = "connection_init"
+	msgConnectionAck  = "connection_ack"
+	msgPing           = "ping"
+	msgPong           = "pong"
+	msgSubscribe      = "subscribe"
+	msgNext           = "next"
+	msgError          = "error"
+	msgComplete       = "complete"
+)
+
+// Códigos de cierre que define el protocolo
+const (
+	closeInvalidMessage     = 4400
+	closeUnauthorized       = 4401
+	closeSubprotocol        = 4406
+	closeInitTimeout        = 4408
+	closeSubscriberExists   = 4409
+	closeTooManyInitRequest = 4429
+)
+
+// Tiempos del canal: el cliente tiene initTimeout para enviar connection_init;
+// después el servidor envía un ping cada pingPeriod y corta la conexión si no
+// recibe nada durante pongWait
+const (
+	initTimeout = 10 * time.Second
+	writeWait   = 10 * time.Second
+	pongWait    = 60 * time.Second
+	pingPeriod  = pongWait * 9 / 10
+	maxMessage  = 64 << 10
+)
+
+// newUpgrader crea el upgrader, que acepta solo el subprotocolo
+// graphql-transport-ws. checkOrigin decide desde qué orígenes se puede abrir
+// el canal, ya que el navegador no aplica CORS a los WebSocket
+func newUpgrader(checkOrigin func(*http.Request) bool) websocket.Upgrader {
+	return websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		Subprotocols:    []string{subprotocol},
+		CheckOrigin:     checkOrigin,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			handlers.WriteProblem(w, r, models.NewProblem(status, models.CodeUpgradeFailed,
+				"No se pudo abrir el WebSocket: "+reason.Error()))
+		},
+	}
+}
+
+// wsMessage es el sobre de todos los mensajes del protocolo
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// session es una conexión WebSocket con sus operaciones en curso
+type session struct {
+	schema *graphql.Schema
+	conn   *websocket.Conn
+	ctx    context.Context
+
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	acked  bool
+	active map[string]context.CancelFunc
+}
+
+// serveWebSocket abre un canal graphql-transport-ws
+func (h *Handler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade ya respondió el error
+		return
+	}
+	if conn.Subprotocol() != subprotocol {
+		closeConn(conn, closeSubprotocol, "Subprotocol not acceptable")
+		return
+	}
+
+	// La sesión dura más que la petición del upgrade, pero sigue siendo del
+	// mismo usuario en el mismo workspace
+	base := context.Background()
+	if workspace, ok := store.WorkspaceFrom(r.Context()); ok {
+		base = store.WithWorkspace(base, workspace)
+	}
+	if user, ok := store.UserFrom(r.Context()); ok {
+		base = store.WithUser(base, user)
+	}
+	if token, ok := store.TokenFrom(r.Context()); ok {
+		base = store.WithToken(base, token)
+	}
+	ctx, cancel := context.WithCancel(base)
+	defer cancel()
+	s := &session{
+		schema: h.schema,
+		conn:   conn,
+		ctx:    withLanguage(ctx, validation.Language(r.Header.Get("Accept-Language"))),
+		active: make(map[string]context.CancelFunc),
+	}
+	s.run()
+}
+
+// run lee los mensajes del cliente hasta que la conexión se cierre
+func (s *session) run() {
+	defer s.conn.Close()
+
+	s.conn.SetReadLimit(maxMessage)
+	s.conn.SetReadDeadline(time.Now().Add(initTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() && !s.isAcked() {
+				closeConn(s.conn, closeInitTimeout, "Connection initialisation timeout")
+			}
+			return
+		}
+		if s.isAcked() {
+			s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		}
+
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			closeConn(s.conn, closeInvalidMessage, "Invalid message")
+			return
+		}
+		if !s.handle(msg) {
+			return
+		}
+	}
+}
+
+// handle procesa un mensaje; retorna false si la conexión debe cerrarse
+func (s *session) handle(msg wsMessage) bool {
+	switch msg.Type {
+	case msgConnectionInit:
+		s.mu.Lock()
+		already := s.acked
+		s.acked = true
+		s.mu.Unlock()
+		if already {
+			closeConn(s.conn, closeTooManyInitRequest, "Too many initialisation requests")
+			return false
+		}
+		s.write(wsMessage{Type: msgConnectionAck})
+		s.conn.SetReadDeadline(time.Now().Add(pongWait))
+		go s.keepAlive()
+	case msgPing:
+		s.write(wsMessage{Type: msgPong})
+	case msgPong:
+	case msgSubscribe:
+		if !s.isAcked() {
+			closeConn(s.conn, closeUnauthorized, "Unauthorized")
+			return false
+		}
+		var req models.GraphQLRequest
+		if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil || req.Query == "" {
+			closeConn(s.conn, closeInvalidMessage, "Invalid message")
+			return false
+		}
+		if !s.start(msg.ID, req) {
+			closeConn(s.conn, closeSubscriberExists, "Subscriber for "+msg.ID+" already exists")
+			return false
+		}
+	case msgComplete:
+		s.mu.Lock()
+		if cancel, ok := s.active[msg.ID]; ok {
+			cancel()
+			delete(s.active, msg.ID)
+		}
+		s.mu.Unlock()
+	default:
+		closeConn(s.conn, closeInvalidMessage, "Invalid message")
+		return false
+	}
+	return true
+}
+
+// start registra y lanza una operación; retorna false si el id ya está en uso
+func (s *session) start(id string, req models.GraphQLRequest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.active[id]; exists {
+		return false
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.active[id] = cancel
+	go s.execute(ctx, id, req)
+	return true
+}
+
+// execute corre una operación y envía sus resultados como next. Un error
+// antes de ejecutar (sintaxis o validación) se envía como error
+func (s *session) execute(ctx context.Context, id string, req models.GraphQLRequest) {
+	responses, err := s.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		payload, _ := json.Marshal([]models.GraphQLError{{Message: err.Error()}})
+		s.write(wsMessage{ID: id, Type: msgError, Payload: payload})
+		s.finish(id)
+		return
+	}
+
+	first := true
+	for value := range responses {
+		response, ok := value.(*graphql.Response)
+		if !ok {
+			continue
+		}
+		if first && response.Data == nil && len(response.Errors) > 0 {
+			payload, _ := json.Marshal(response.Errors)
+			s.write(wsMessage{ID: id, Type: msgError, Payload: payload})
+			s.finish(id)
+			return
+		}
+		first = false
+		payload, _ := json.Marshal(response)
+		s.write(wsMessage{ID: id, Type: msgNext, Payload: payload})
+	}
+
+	// Si el cliente envió complete no hay que avisarle que terminó
+	if s.finish(id) {
+		s.write(wsMessage{ID: id, Type: msgComplete})
+	}
+}
+
+// finish olvida una operación; retorna false si el cliente ya la había cancelado
+func (s *session) finish(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.active[id]
+	if ok {
+		cancel()
+		delete(s.active, id)
+	}
+	return ok
+}
+
+// keepAlive envía pings mientras la conexión siga abierta
+func (s *session) keepAlive() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write envía un mensaje; las escrituras se serializan porque las operaciones
+// corren en goroutines distintas
+func (s *session) write(msg wsMessage) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	s.conn.WriteJSON(msg)
+}
+
+// isAcked indica si el cliente ya envió connection_init
+func (s *session) isAcked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.acked
+}
+
+// closeConn cierra la conexión con uno de los códigos del protocolo
+func closeConn(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+	conn.Close()
+}
@@ -0,0 +1,131 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"todo-list/grpcapi/todopb"
+	"todo-list/models"
+	"todo-list/store"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// healthPrefix son los métodos del health check, que no exigen sesión
+const healthPrefix = "/grpc.health.v1."
+
+// readMethods son los métodos de TodoService que un token personal puede
+// llamar con el scope todos:read; los demás piden todos:write
+var readMethods = map[string]bool{
+	todopb.TodoService_List_FullMethodName:  true,
+	todopb.TodoService_Get_FullMethodName:   true,
+	todopb.TodoService_Watch_FullMethodName: true,
+}
+
+// authenticate obtiene el workspace de la llamada y el usuario del token que
+// llega en el metadata authorization ("Bearer <token>"), y los deja en el
+// contexto. El token puede ser el de la sesión, un token personal o un access
+// token JWT del proveedor OIDC; los dos últimos deben tener el scope que pide
+// el método
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, err := s.resolveWorkspace(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok {
+			continue
+		}
+		token = strings.TrimSpace(token)
+		if strings.HasPrefix(token, store.TokenPrefix) || (s.sso.Enabled() && strings.Count(token, ".") == 2) {
+			return s.authenticateToken(ctx, method, token)
+		}
+		user, err := s.users.SessionUser(ctx, token)
+		if err != nil {
+			break
+		}
+		return store.WithUser(ctx, user), nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "Inicia sesión para continuar")
+}
+
+// resolveWorkspace deja en el contexto el workspace del metadata
+// x-workspace o, si no viene, el del subdominio de :authority
+func (s *Server) resolveWorkspace(ctx context.Context, md metadata.MD) (context.Context, error) {
+	workspace, err := s.workspaces.Resolve(first(md, "x-workspace"), first(md, ":authority"))
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "Workspace no encontrado")
+	}
+	return store.WithWorkspace(ctx, workspace), nil
+}
+
+// first obtiene el primer valor de una clave del metadata; vacío si no viene
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// authenticateToken valida un token personal o un access token JWT y su
+// scope para el método
+func (s *Server) authenticateToken(ctx context.Context, method, value string) (context.Context, error) {
+	var user models.User
+	var token models.APIToken
+	var err error
+	if strings.HasPrefix(value, store.TokenPrefix) {
+		user, token, err = s.users.TokenUser(ctx, value)
+	} else {
+		user, token, err = s.sso.VerifyAccessToken(ctx, value)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Inicia sesión para continuar")
+	}
+	scope := models.ScopeTodosWrite
+	if readMethods[method] {
+		scope = models.ScopeTodosRead
+	}
+	if !token.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, "El token no tiene el scope "+scope)
+	}
+	return store.WithToken(store.WithUser(ctx, user), token), nil
+}
+
+// unaryAuth exige sesión o token personal en las llamadas unarias de TodoService
+func (s *Server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if strings.HasPrefix(info.FullMethod, healthPrefix) {
+		return handler(ctx, req)
+	}
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth exige sesión o token personal en las llamadas con stream de TodoService
+func (s *Server) streamAuth(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, healthPrefix) {
+		return handler(srv, stream)
+	}
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: stream, ctx: ctx})
+}
+
+// authStream es un stream cuyo contexto ya trae al usuario
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context retorna el contexto con el usuario autenticado
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
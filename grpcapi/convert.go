@@ -0,0 +1,81 @@
+package grpcapi
+
+import (
+	"time"
+	"todo-list/grpcapi/todopb"
+	"todo-list/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toProto convierte un todo del store al mensaje de la API
+func toProto(todo models.Todo) *todopb.Todo {
+	checklist := make([]*todopb.ChecklistItem, 0, len(todo.Checklist))
+	for _, item := range todo.Checklist {
+		checklist = append(checklist, &todopb.ChecklistItem{Text: item.Text, Done: item.Done})
+	}
+	return &todopb.Todo{
+		Id:              int64(todo.ID),
+		Title:           todo.Title,
+		Description:     todo.Description,
+		DescriptionHtml: todo.DescriptionHTML,
+		Completed:       todo.Completed,
+		Estimate:        int32(todo.Estimate),
+		Tags:            append([]string{}, todo.Tags...),
+		Checklist:       checklist,
+		DueDate:         timestamp(todo.DueDate),
+		CompletedAt:     timestamp(todo.CompletedAt),
+		Archived:        todo.Archived,
+		ArchivedAt:      timestamp(todo.ArchivedAt),
+		Version:         int64(todo.Version),
+		CreatedAt:       timestamppb.New(todo.CreatedAt),
+		UpdatedAt:       timestamppb.New(todo.UpdatedAt),
+	}
+}
+
+// changeToProto convierte un cambio del feed al mensaje de Watch
+func changeToProto(event models.ChangeEvent) *todopb.TodoChange {
+	change := &todopb.TodoChange{
+		Id:     event.ID,
+		Type:   event.Type,
+		TodoId: int64(event.TodoID),
+		At:     timestamppb.New(event.At),
+	}
+	if event.Todo != nil {
+		change.Todo = toProto(*event.Todo)
+	}
+	return change
+}
+
+// fromInput traduce el TodoInput a la petición que entiende el store. Al
+// actualizar, tags y checklist en nil conservan los valores actuales
+func fromInput(in *todopb.TodoInput) models.TodoRequest {
+	req := models.TodoRequest{
+		Title:       in.GetTitle(),
+		Description: in.GetDescription(),
+		Completed:   in.GetCompleted(),
+		Estimate:    int(in.GetEstimate()),
+	}
+	if len(in.GetTags()) > 0 || in.GetReplaceTags() {
+		req.Tags = append([]string{}, in.GetTags()...)
+	}
+	if len(in.GetChecklist()) > 0 || in.GetReplaceChecklist() {
+		req.Checklist = make([]models.ChecklistItem, 0, len(in.GetChecklist()))
+		for _, item := range in.GetChecklist() {
+			req.Checklist = append(req.Checklist, models.ChecklistItem{Text: item.GetText(), Done: item.GetDone()})
+		}
+	}
+	if in.GetDueDate() != nil {
+		dueDate := in.GetDueDate().AsTime()
+		req.DueDate = &dueDate
+	}
+	return req
+}
+
+// timestamp convierte una fecha opcional; nil si no tiene valor
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/validation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifica a esta API en los google.rpc.ErrorInfo
+const errorDomain = "todo-list"
+
+// grpcCodes traduce el status HTTP de un problem al código de gRPC equivalente
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:           codes.InvalidArgument,
+	http.StatusForbidden:            codes.PermissionDenied,
+	http.StatusNotFound:             codes.NotFound,
+	http.StatusConflict:             codes.FailedPrecondition,
+	http.StatusPreconditionFailed:   codes.Aborted,
+	http.StatusPreconditionRequired: codes.FailedPrecondition,
+	http.StatusUnprocessableEntity:  codes.InvalidArgument,
+	http.StatusInternalServerError:  codes.Internal,
+}
+
+// problemError convierte un problem en un error de gRPC. El code del problem
+// viaja como reason de un ErrorInfo y los errores por campo como BadRequest
+func problemError(problem models.Problem) error {
+	code, ok := grpcCodes[problem.Status]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, problem.Detail)
+
+	info := &errdetails.ErrorInfo{Reason: problem.Code, Domain: errorDomain}
+	if len(problem.Errors) == 0 {
+		if withDetails, err := st.WithDetails(info); err == nil {
+			st = withDetails
+		}
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, fieldError := range problem.Errors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fieldError.Field,
+			Description: fieldError.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(info, badRequest); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// storeError traduce un error del store al mismo código que usa la API REST
+func storeError(err error) error {
+	return problemError(handlers.ProblemFromError(err))
+}
+
+// validationError crea el error de una entrada inválida con el detalle por campo
+func validationError(errs []models.FieldError) error {
+	return problemError(models.NewValidationProblem(errs))
+}
+
+// language obtiene el idioma de los mensajes del metadata accept-language
+func language(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return validation.Language(strings.Join(md.Get("accept-language"), ","))
+}
@@ -0,0 +1,255 @@
+// Package grpcapi sirve la API gRPC de los todos (todo.v1.TodoService) sobre
+// el mismo store y feed de cambios que la API REST
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative todopb/todo.proto
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"todo-list/events"
+	"todo-list/grpcapi/todopb"
+	"todo-list/models"
+	"todo-list/oidc"
+	"todo-list/store"
+	"todo-list/validation"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Server implementa todopb.TodoServiceServer
+type Server struct {
+	todopb.UnimplementedTodoServiceServer
+
+	store          *store.TodoStore
+	broker         *events.Broker
+	users          *store.UserStore
+	sso            *oidc.Client
+	workspaces     *store.Workspaces
+	requireVersion bool
+}
+
+// NewServer crea el servicio sobre el store y el feed de cambios compartidos;
+// las llamadas se autentican con los tokens de users o, si sso no es nil, con
+// los access tokens del proveedor OIDC, dentro del workspace que indican. Con
+// requireVersion Update y Delete exigen expected_version, igual que If-Match
+// con REQUIRE_IF_MATCH
+func NewServer(todoStore *store.TodoStore, broker *events.Broker, users *store.UserStore, sso *oidc.Client, workspaces *store.Workspaces, requireVersion bool) *Server {
+	return &Server{store: todoStore, broker: broker, users: users, sso: sso, workspaces: workspaces, requireVersion: requireVersion}
+}
+
+// Serve atiende TodoService y el health check estándar (grpc.health.v1) en
+// lis hasta que ctx se cancele. TodoService exige sesión; el health check no
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)
+	todopb.RegisterTodoServiceServer(grpcServer, s)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(todopb.TodoService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+
+	go func() {
+		<-ctx.Done()
+		healthServer.Shutdown()
+		grpcServer.GracefulStop()
+	}()
+	return grpcServer.Serve(lis)
+}
+
+// List envía los todos que cumplen el filtro, ordenados por ID
+func (s *Server) List(req *todopb.ListTodosRequest, stream todopb.TodoService_ListServer) error {
+	ctx := stream.Context()
+
+	var todos []models.Todo
+	if req.GetArchived() {
+		todos = s.store.ListArchived(ctx, "")
+	} else {
+		todos = s.store.List(ctx)
+	}
+	for _, todo := range todos {
+		if !matches(req, todo) {
+			continue
+		}
+		if err := stream.Send(toProto(todo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get obtiene un todo por ID
+func (s *Server) Get(ctx context.Context, req *todopb.GetTodoRequest) (*todopb.Todo, error) {
+	todo, err := s.store.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return toProto(todo), nil
+}
+
+// Create crea un todo con las mismas reglas que POST /todos
+func (s *Server) Create(ctx context.Context, req *todopb.CreateTodoRequest) (*todopb.Todo, error) {
+	todoReq := fromInput(req.GetTodo())
+	if errs := validation.Struct(&todoReq, language(ctx)); len(errs) > 0 {
+		return nil, validationError(errs)
+	}
+
+	todo, err := s.store.Create(ctx, todoReq)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return toProto(todo), nil
+}
+
+// Update reemplaza un todo con las mismas reglas que PUT /todos/{id}
+func (s *Server) Update(ctx context.Context, req *todopb.UpdateTodoRequest) (*todopb.Todo, error) {
+	if err := s.checkVersion(req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	todoReq := fromInput(req.GetTodo())
+	if errs := validation.Struct(&todoReq, language(ctx)); len(errs) > 0 {
+		return nil, validationError(errs)
+	}
+
+	var todo models.Todo
+	var err error
+	if req.ExpectedVersion != nil {
+		todo, err = s.store.UpdateIf(ctx, int(req.GetId()), todoReq, versionIs(req.GetExpectedVersion()))
+	} else {
+		todo, err = s.store.Update(ctx, int(req.GetId()), todoReq)
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return toProto(todo), nil
+}
+
+// Delete elimina un todo como DELETE /todos/{id}
+func (s *Server) Delete(ctx context.Context, req *todopb.DeleteTodoRequest) (*todopb.DeleteTodoResponse, error) {
+	if err := s.checkVersion(req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if req.ExpectedVersion != nil {
+		err = s.store.DeleteIf(ctx, int(req.GetId()), versionIs(req.GetExpectedVersion()))
+	} else {
+		err = s.store.Delete(ctx, int(req.GetId()))
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &todopb.DeleteTodoResponse{Id: req.GetId()}, nil
+}
+
+// Watch transmite los cambios de los todos del usuario desde last_event_id
+// hasta que el cliente cancele. Si el broker desconecta al suscriptor por lento, se retoma
+// desde el último cambio enviado
+func (s *Server) Watch(req *todopb.WatchRequest, stream todopb.TodoService_WatchServer) error {
+	ctx := stream.Context()
+	owner := store.UserID(ctx)
+	wanted := make(map[string]bool)
+	for _, eventType := range req.GetTypes() {
+		wanted[eventType] = true
+	}
+
+	sub, err := s.subscribe(stream, req.GetLastEventId())
+	defer func() {
+		s.broker.Unsubscribe(sub)
+	}()
+	if err != nil {
+		return err
+	}
+
+	lastID := sub.LastID
+	backlog := sub.Missed
+	for {
+		var event models.ChangeEvent
+		if len(backlog) > 0 {
+			event, backlog = backlog[0], backlog[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				return nil
+			case e, ok := <-sub.Events:
+				if !ok {
+					if sub, err = s.subscribe(stream, lastID); err != nil {
+						return err
+					}
+					backlog = sub.Missed
+					continue
+				}
+				event = e
+			}
+		}
+		lastID = event.ID
+
+		if !event.VisibleTo(owner) || len(wanted) > 0 && !wanted[event.Type] {
+			continue
+		}
+		if err := stream.Send(changeToProto(event)); err != nil {
+			return err
+		}
+	}
+}
+
+// subscribe se suscribe al feed desde lastID y, si lastID ya no está en el
+// historial, avisa al cliente que debe volver a listar los todos
+func (s *Server) subscribe(stream todopb.TodoService_WatchServer, lastID int64) (*events.Subscription, error) {
+	sub := s.broker.Subscribe(lastID)
+	if sub.Reset {
+		if err := stream.Send(&todopb.TodoChange{Resync: true}); err != nil {
+			return sub, err
+		}
+	}
+	return sub, nil
+}
+
+// checkVersion exige expected_version cuando el servidor corre con REQUIRE_IF_MATCH
+func (s *Server) checkVersion(version *int64) error {
+	if s.requireVersion && version == nil {
+		return problemError(models.NewProblem(http.StatusPreconditionRequired, models.CodeIfMatchRequired,
+			"Se requiere expected_version para modificar un todo"))
+	}
+	return nil
+}
+
+// versionIs es la precondición equivalente a If-Match
+func versionIs(version int64) store.Precondition {
+	return func(current models.Todo) bool {
+		return int64(current.Version) == version
+	}
+}
+
+// matches indica si el todo cumple el filtro de List
+func matches(req *todopb.ListTodosRequest, todo models.Todo) bool {
+	if req.Completed != nil && todo.Completed != req.GetCompleted() {
+		return false
+	}
+	if req.GetTag() != "" && !hasTag(todo, req.GetTag()) {
+		return false
+	}
+	search := strings.ToLower(strings.TrimSpace(req.GetSearch()))
+	if search != "" &&
+		!strings.Contains(strings.ToLower(todo.Title), search) &&
+		!strings.Contains(strings.ToLower(todo.Description), search) {
+		return false
+	}
+	return true
+}
+
+// hasTag indica si el todo tiene la etiqueta
+func hasTag(todo models.Todo, tag string) bool {
+	for _, t := range todo.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/events"
+	"todo-list/grpcapi/todopb"
+	"todo-list/models"
+	"todo-list/store"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// acmeSecret marca los todos de acme; globex nunca debe recibirlo
+const acmeSecret = "acme-secreto"
+
+// tenantSession registra una cuenta en el workspace y retorna el metadata
+// con el que sus llamadas se autentican
+func tenantSession(t *testing.T, users *store.UserStore, workspaces *store.Workspaces, slug, email string) (context.Context, metadata.MD) {
+	t.Helper()
+
+	workspace, err := workspaces.Resolve(slug, "")
+	if err != nil {
+		t.Fatalf("workspace %s: %v", slug, err)
+	}
+	ctx := store.WithWorkspace(context.Background(), workspace)
+	user, err := users.Register(ctx, models.RegisterRequest{Email: email, Name: email, Password: "contraseña-segura"})
+	if err != nil {
+		t.Fatalf("registrar %s: %v", email, err)
+	}
+	token, _, err := users.CreateSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("sesión de %s: %v", email, err)
+	}
+	return store.WithUser(ctx, user), metadata.Pairs("authorization", "Bearer "+token, "x-workspace", slug)
+}
+
+func TestWorkspaceIsolation(t *testing.T) {
+	todoStore := store.NewTodoStore()
+	broker := events.NewBroker(100, time.Minute)
+	todoStore.SetPublisher(broker)
+	users := store.NewUserStore(time.Hour)
+	workspaces := store.NewWorkspaces([]models.Workspace{{Slug: "acme"}, {Slug: "globex"}}, "", "")
+
+	aliceCtx, alice := tenantSession(t, users, workspaces, "acme", "alice@example.com")
+	_, eve := tenantSession(t, users, workspaces, "globex", "eve@example.com")
+	todo, err := todoStore.Create(aliceCtx, models.TodoRequest{Title: acmeSecret, Tags: []string{acmeSecret}})
+	if err != nil {
+		t.Fatalf("crear todo de acme: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewServer(todoStore, broker, users, nil, workspaces, false).Serve(ctx, lis)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("conectar: %v", err)
+	}
+	defer conn.Close()
+	client := todopb.NewTodoServiceClient(conn)
+	as := func(md metadata.MD) context.Context {
+		return metadata.NewOutgoingContext(ctx, md)
+	}
+
+	// globex no ve, cambia ni borra el todo de acme
+	if _, err := client.Get(as(eve), &todopb.GetTodoRequest{Id: int64(todo.ID)}); status.Code(err) != codes.NotFound {
+		t.Errorf("Get desde globex: %v, se esperaba NotFound", err)
+	}
+	input := &todopb.TodoInput{Title: "pwned"}
+	if _, err := client.Update(as(eve), &todopb.UpdateTodoRequest{Id: int64(todo.ID), Todo: input}); status.Code(err) != codes.NotFound {
+		t.Errorf("Update desde globex: %v, se esperaba NotFound", err)
+	}
+	if _, err := client.Delete(as(eve), &todopb.DeleteTodoRequest{Id: int64(todo.ID)}); status.Code(err) != codes.NotFound {
+		t.Errorf("Delete desde globex: %v, se esperaba NotFound", err)
+	}
+	for _, archived := range []bool{false, true} {
+		stream, err := client.List(as(eve), &todopb.ListTodosRequest{Archived: archived})
+		if err != nil {
+			t.Fatalf("List desde globex: %v", err)
+		}
+		for {
+			got, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("List desde globex: %v", err)
+			}
+			t.Errorf("List desde globex recibió el todo %d", got.Id)
+		}
+	}
+
+	// El feed de globex no recibe los cambios de acme
+	watchCtx, stopWatch := context.WithTimeout(as(eve), 300*time.Millisecond)
+	defer stopWatch()
+	watch, err := client.Watch(watchCtx, &todopb.WatchRequest{})
+	if err != nil {
+		t.Fatalf("Watch desde globex: %v", err)
+	}
+	if _, err := todoStore.Create(aliceCtx, models.TodoRequest{Title: acmeSecret}); err != nil {
+		t.Fatalf("crear todo de acme: %v", err)
+	}
+	for {
+		change, err := watch.Recv()
+		if err != nil {
+			break
+		}
+		if strings.Contains(change.String(), acmeSecret) {
+			t.Errorf("Watch desde globex recibió un cambio de acme: %v", change)
+		}
+	}
+
+	// La sesión de acme no sirve en globex y un workspace desconocido es NotFound
+	crossed := alice.Copy()
+	crossed.Set("x-workspace", "globex")
+	if _, err := client.Get(as(crossed), &todopb.GetTodoRequest{Id: int64(todo.ID)}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("sesión de acme en globex: %v, se esperaba Unauthenticated", err)
+	}
+	crossed.Set("x-workspace", "initech")
+	if _, err := client.Get(as(crossed), &todopb.GetTodoRequest{Id: int64(todo.ID)}); status.Code(err) != codes.NotFound {
+		t.Errorf("workspace desconocido: %v, se esperaba NotFound", err)
+	}
+
+	// acme sigue viendo su todo intacto
+	got, err := client.Get(as(alice), &todopb.GetTodoRequest{Id: int64(todo.ID)})
+	if err != nil || got.Title != acmeSecret {
+		t.Errorf("Get desde acme: %v %v", got, err)
+	}
+}
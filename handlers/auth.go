@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// SessionCookie es el nombre de la cookie que guarda el token de sesión
+const SessionCookie = "session"
+
+// AuthHandler maneja el registro, el inicio y el cierre de sesión
+type AuthHandler struct {
+	users  *store.UserStore
+	secure bool
+}
+
+// NewAuthHandler crea una nueva instancia del handler de cuentas. Con secure
+// la cookie de sesión solo viaja por HTTPS
+func NewAuthHandler(userStore *store.UserStore, secure bool) *AuthHandler {
+	return &AuthHandler{
+		users:  userStore,
+		secure: secure,
+	}
+}
+
+// Register crea una cuenta e inicia su sesión
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var registerReq models.RegisterRequest
+	if err := decodeJSON(r, &registerReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &registerReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	user, err := h.users.Register(r.Context(), registerReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	if err := h.startSession(w, r, user); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	w.WriteHeader(http.StatusCreated)
+	response := models.Response{
+		Success: true,
+		Message: "Cuenta creada exitosamente",
+		Data:    user,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Login inicia una sesión con email y contraseña
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var loginReq models.LoginRequest
+	if err := decodeJSON(r, &loginReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &loginReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	user, err := h.users.Authenticate(r.Context(), loginReq.Email, loginReq.Password)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	if err := h.startSession(w, r, user); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Sesión iniciada",
+		Data:    user,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Logout cierra la sesión actual; responde igual si no había sesión
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		h.users.DeleteSession(r.Context(), cookie.Value)
+	}
+	http.SetCookie(w, expiredSessionCookie(h.secure))
+	
+	response := models.Response{
+		Success: true,
+		Message: "Sesión cerrada",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Me obtiene el usuario de la sesión actual
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	user, _ := store.UserFrom(r.Context())
+	response := models.Response{
+		Success: true,
+		Message: "Usuario obtenido exitosamente",
+		Data:    user,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// startSession crea la sesión del usuario y envía su cookie
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, user models.User) error {
+	token, expiresAt, err := h.users.CreateSession(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessionCookie(token, expiresAt, h.secure))
+	return nil
+}
+
+// sessionCookie arma la cookie de sesión. HttpOnly la oculta de JavaScript y
+// SameSite=Lax evita que se envíe en peticiones POST desde otros sitios
+func sessionCookie(token string, expiresAt time.Time, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// expiredSessionCookie arma la cookie que le pide al navegador borrar la sesión
+func expiredSessionCookie(secure bool) *http.Cookie {
+	cookie := sessionCookie("", time.Unix(0, 0), secure)
+	cookie.MaxAge = -1
+	return cookie
+}
+
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandlerGin maneja el registro, el inicio y el cierre de sesión usando Gin
+type AuthHandlerGin struct {
+	users  *store.UserStore
+	secure bool
+}
+
+// NewAuthHandlerGin crea una nueva instancia del handler de cuentas con Gin
+func NewAuthHandlerGin(userStore *store.UserStore, secure bool) *AuthHandlerGin {
+	return &AuthHandlerGin{
+		users:  userStore,
+		secure: secure,
+	}
+}
+
+// Register crea una cuenta e inicia su sesión
+func (h *AuthHandlerGin) Register(c *gin.Context) {
+	var registerReq models.RegisterRequest
+	if err := bindJSON(c, &registerReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &registerReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	user, err := h.users.Register(c.Request.Context(), registerReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	if err := h.startSession(c, user); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Cuenta creada exitosamente",
+		Data:    user,
+	})
+}
+
+// Login inicia una sesión con email y contraseña
+func (h *AuthHandlerGin) Login(c *gin.Context) {
+	var loginReq models.LoginRequest
+	if err := bindJSON(c, &loginReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &loginReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	user, err := h.users.Authenticate(c.Request.Context(), loginReq.Email, loginReq.Password)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	if err := h.startSession(c, user); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Sesión iniciada",
+		Data:    user,
+	})
+}
+
+// Logout cierra la sesión actual; responde igual si no había sesión
+func (h *AuthHandlerGin) Logout(c *gin.Context) {
+	if token, err := c.Cookie(SessionCookie); err == nil {
+		h.users.DeleteSession(c.Request.Context(), token)
+	}
+	http.SetCookie(c.Writer, expiredSessionCookie(h.secure))
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Sesión cerrada",
+	})
+}
+
+// Me obtiene el usuario de la sesión actual
+func (h *AuthHandlerGin) Me(c *gin.Context) {
+	user, _ := store.UserFrom(c.Request.Context())
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Usuario obtenido exitosamente",
+		Data:    user,
+	})
+}
+
+// startSession crea la sesión del usuario y envía su cookie
+func (h *AuthHandlerGin) startSession(c *gin.Context, user models.User) error {
+	token, expiresAt, err := h.users.CreateSession(c.Request.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(c.Writer, sessionCookie(token, expiresAt, h.secure))
+	return nil
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"todo-list/models"
+	"todo-list/oidc"
+	"todo-list/store"
+	"todo-list/templates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie guarda el state del inicio de sesión con OIDC hasta el callback
+const oidcStateCookie = "oidc_state"
+
+// AuthHandlerTempl maneja las páginas de inicio de sesión y registro
+type AuthHandlerTempl struct {
+	users  *store.UserStore
+	sso    *oidc.Client
+	secure bool
+}
+
+// NewAuthHandlerTempl crea una nueva instancia del handler de cuentas con
+// Templ; sso es nil si no hay inicio de sesión único configurado
+func NewAuthHandlerTempl(userStore *store.UserStore, sso *oidc.Client, secure bool) *AuthHandlerTempl {
+	return &AuthHandlerTempl{
+		users:  userStore,
+		sso:    sso,
+		secure: secure,
+	}
+}
+
+// GetLoginPage muestra el formulario de inicio de sesión
+func (h *AuthHandlerTempl) GetLoginPage(c *gin.Context) {
+	h.render(c, http.StatusOK, templates.AuthPageData{Title: "Todo List - Iniciar sesión"})
+}
+
+// GetRegisterPage muestra el formulario de registro
+func (h *AuthHandlerTempl) GetRegisterPage(c *gin.Context) {
+	h.render(c, http.StatusOK, templates.AuthPageData{Title: "Todo List - Crear cuenta", Register: true})
+}
+
+// Login inicia la sesión desde el formulario y vuelve a la lista
+func (h *AuthHandlerTempl) Login(c *gin.Context) {
+	loginReq := models.LoginRequest{
+		Email:    c.PostForm("email"),
+		Password: c.PostForm("password"),
+	}
+	data := templates.AuthPageData{Title: "Todo List - Iniciar sesión", Email: loginReq.Email}
+	
+	if errs := validateRequest(c.Request, &loginReq); len(errs) > 0 {
+		data.Errors = errorMessages(errs)
+		h.render(c, http.StatusBadRequest, data)
+		return
+	}
+	
+	user, err := h.users.Authenticate(c.Request.Context(), loginReq.Email, loginReq.Password)
+	if err != nil {
+		problem := problemFromError(err)
+		data.Errors = []string{problem.Detail}
+		h.render(c, problem.Status, data)
+		return
+	}
+	h.startSession(c, user)
+}
+
+// Register crea la cuenta desde el formulario, inicia su sesión y vuelve a la lista
+func (h *AuthHandlerTempl) Register(c *gin.Context) {
+	registerReq := models.RegisterRequest{
+		Email:    c.PostForm("email"),
+		Name:     c.PostForm("name"),
+		Password: c.PostForm("password"),
+	}
+	data := templates.AuthPageData{
+		Title:    "Todo List - Crear cuenta",
+		Register: true,
+		Email:    registerReq.Email,
+		Name:     registerReq.Name,
+	}
+	
+	if errs := validateRequest(c.Request, &registerReq); len(errs) > 0 {
+		data.Errors = errorMessages(errs)
+		h.render(c, http.StatusBadRequest, data)
+		return
+	}
+	
+	user, err := h.users.Register(c.Request.Context(), registerReq)
+	if err != nil {
+		problem := problemFromError(err)
+		data.Errors = []string{problem.Detail}
+		h.render(c, problem.Status, data)
+		return
+	}
+	h.startSession(c, user)
+}
+
+// Logout cierra la sesión y vuelve al formulario de inicio de sesión
+func (h *AuthHandlerTempl) Logout(c *gin.Context) {
+	if token, err := c.Cookie(SessionCookie); err == nil {
+		h.users.DeleteSession(c.Request.Context(), token)
+	}
+	http.SetCookie(c.Writer, expiredSessionCookie(h.secure))
+	c.Redirect(http.StatusSeeOther, "/login")
+}
+
+// SSOLogin redirige al proveedor OIDC con el flujo authorization code y PKCE
+func (h *AuthHandlerTempl) SSOLogin(c *gin.Context) {
+	url, state, err := h.sso.StartLogin(c.Request.Context())
+	if err != nil {
+		log.Printf("oidc: no se pudo iniciar sesión: %v", err)
+		h.render(c, http.StatusBadGateway, templates.AuthPageData{
+			Title:  "Todo List - Iniciar sesión",
+			Errors: []string{"No se pudo contactar al proveedor de inicio de sesión"},
+		})
+		return
+	}
+	http.SetCookie(c.Writer, h.stateCookie(state, 600))
+	c.Redirect(http.StatusFound, url)
+}
+
+// SSOCallback recibe al usuario de vuelta del proveedor: comprueba que el
+// state sea el de su navegador, termina el flujo e inicia su sesión
+func (h *AuthHandlerTempl) SSOCallback(c *gin.Context) {
+	state, _ := c.Cookie(oidcStateCookie)
+	http.SetCookie(c.Writer, h.stateCookie("", -1))
+	data := templates.AuthPageData{Title: "Todo List - Iniciar sesión"}
+	
+	if c.Query("error") != "" {
+		data.Errors = []string{"El proveedor no autorizó el inicio de sesión"}
+		h.render(c, http.StatusUnauthorized, data)
+		return
+	}
+	if state == "" || c.Query("state") != state {
+		data.Errors = []string{oidc.ErrLoginExpired.Error()}
+		h.render(c, http.StatusBadRequest, data)
+		return
+	}
+	
+	user, err := h.sso.FinishLogin(c.Request.Context(), state, c.Query("code"))
+	if err != nil {
+		data.Errors = []string{ssoErrorMessage(err)}
+		h.render(c, http.StatusUnauthorized, data)
+		return
+	}
+	h.startSession(c, user)
+}
+
+// stateCookie arma la cookie del state; solo viaja a /auth/oidc. SameSite=Lax
+// alcanza porque el proveedor vuelve con una navegación GET
+func (h *AuthHandlerTempl) stateCookie(state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// startSession crea la sesión del usuario, envía su cookie y redirige a la lista
+func (h *AuthHandlerTempl) startSession(c *gin.Context, user models.User) {
+	token, expiresAt, err := h.users.CreateSession(c.Request.Context(), user.ID)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	http.SetCookie(c.Writer, sessionCookie(token, expiresAt, h.secure))
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// render muestra la página de inicio de sesión o registro
+func (h *AuthHandlerTempl) render(c *gin.Context, status int, data templates.AuthPageData) {
+	data.CSRFToken = csrfToken(c)
+	data.SSO = h.sso.Enabled()
+	c.Status(status)
+	tmpl := templates.GetAuthTemplate()
+	tmpl.Execute(c.Writer, data)
+}
+
+// errorMessages obtiene los mensajes de los errores de validación
+func errorMessages(errs []models.FieldError) []string {
+	messages := make([]string, 0, len(errs))
+	for _, fieldError := range errs {
+		messages = append(messages, fieldError.Message)
+	}
+	return messages
+}
+
+// ssoErrorMessage explica por qué no se pudo terminar el inicio de sesión con OIDC
+func ssoErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, oidc.ErrLoginExpired):
+		return err.Error()
+	case errors.Is(err, store.ErrEmailNotVerified):
+		return "Tu cuenta del proveedor no tiene un email verificado"
+	case errors.Is(err, store.ErrUserNotFound):
+		return "El proveedor no envió el email de tu cuenta"
+	default:
+		log.Printf("oidc: no se pudo terminar el inicio de sesión: %v", err)
+		return "No se pudo iniciar sesión con el proveedor"
+	}
+}
@@ -0,0 +1,13 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// CSRFKey es la clave con la que el middleware CSRF deja en el contexto de
+// Gin el token de la petición
+const CSRFKey = "csrf_token"
+
+// csrfToken obtiene el token CSRF que la página debe repetir en sus
+// formularios y peticiones de htmx
+func csrfToken(c *gin.Context) string {
+	return c.GetString(CSRFKey)
+}
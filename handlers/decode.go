@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errTrailingData se retorna cuando después del objeto JSON viene otro valor
+var errTrailingData = errors.New("el cuerpo tiene datos después del objeto JSON")
+
+// unknownFieldPrefix es como empieza el error de encoding/json para un campo
+// que no existe en el destino
+const unknownFieldPrefix = "json: unknown field "
+
+// decodeJSON decodifica el cuerpo en v de forma estricta: un campo que no
+// existe o datos después del objeto son un error, para que una errata como
+// "complete" no se ignore en silencio
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return err
+		}
+		return errTrailingData
+	}
+	return nil
+}
+
+// DecodeJSON es decodeJSON para los transportes que no pasan por estos
+// handlers, como GraphQL
+func DecodeJSON(r *http.Request, v interface{}) error {
+	return decodeJSON(r, v)
+}
+
+// bindJSON es decodeJSON para los handlers de Gin; reemplaza a ShouldBindJSON,
+// que acepta campos desconocidos
+func bindJSON(c *gin.Context, v interface{}) error {
+	return decodeJSON(c.Request, v)
+}
+
+// bindJSONOrForm decodifica el cuerpo como JSON estricto o, si la petición
+// no declara JSON, como formulario
+func bindJSONOrForm(c *gin.Context, v interface{}) error {
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		return bindJSON(c, v)
+	}
+	return c.ShouldBind(v)
+}
+
+// InvalidBodyProblem traduce un error al leer o decodificar el cuerpo, para
+// los transportes que no pasan por estos handlers (GraphQL, idempotencia)
+func InvalidBodyProblem(err error) models.Problem {
+	return invalidBodyProblem(err)
+}
+
+// invalidBodyProblem se usa cuando el cuerpo no se puede leer o decodificar:
+// 413 si supera el máximo y 400 con el motivo en los demás casos
+func invalidBodyProblem(err error) models.Problem {
+	var tooLarge *http.MaxBytesError
+	var syntax *json.SyntaxError
+	var wrongType *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return models.NewProblem(http.StatusRequestEntityTooLarge, models.CodeBodyTooLarge,
+			fmt.Sprintf("El cuerpo supera el máximo de %d bytes", tooLarge.Limit))
+	case errors.Is(err, io.EOF):
+		return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "El cuerpo está vacío; se esperaba un objeto JSON")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "El JSON del cuerpo está incompleto")
+	case errors.As(err, &syntax):
+		return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody,
+			fmt.Sprintf("JSON mal formado cerca del byte %d", syntax.Offset))
+	case errors.As(err, &wrongType):
+		if wrongType.Field == "" {
+			return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "El cuerpo debe ser un objeto JSON")
+		}
+		message := fmt.Sprintf("El campo %s debe ser %s", wrongType.Field, jsonTypeName(wrongType.Type.Kind().String()))
+		problem := models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, message)
+		problem.Errors = []models.FieldError{{Field: wrongType.Field, Code: models.FieldType, Message: message}}
+		return problem
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field, unquoteErr := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		if unquoteErr != nil {
+			field = strings.TrimPrefix(err.Error(), unknownFieldPrefix)
+		}
+		message := fmt.Sprintf("El campo %q no existe en esta petición", field)
+		problem := models.NewProblem(http.StatusBadRequest, models.CodeUnknownField, message)
+		problem.Errors = []models.FieldError{{Field: field, Code: models.FieldUnknown, Message: message}}
+		return problem
+	case errors.Is(err, errTrailingData):
+		return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "El cuerpo debe tener un solo objeto JSON")
+	default:
+		return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "Datos inválidos: "+err.Error())
+	}
+}
+
+// jsonTypeName describe en el mensaje el tipo que espera un campo
+func jsonTypeName(kind string) string {
+	switch {
+	case kind == "string":
+		return "un texto"
+	case kind == "bool":
+		return "true o false"
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"):
+		return "un número entero"
+	case strings.HasPrefix(kind, "float"):
+		return "un número"
+	case kind == "slice", kind == "array":
+		return "una lista"
+	default:
+		return "un objeto"
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// todoETag calcula el ETag de un todo a partir de su versión
+func todoETag(todo models.Todo) string {
+	return fmt.Sprintf(`"v%d"`, todo.Version)
+}
+
+// listETag calcula el ETag de una lista a partir de los IDs y versiones que contiene
+func listETag(todos []models.Todo) string {
+	h := fnv.New64a()
+	for _, todo := range todos {
+		fmt.Fprintf(h, "%d:%d;", todo.ID, todo.Version)
+	}
+	return fmt.Sprintf(`"l%x"`, h.Sum64())
+}
+
+// ifMatchCondition crea la precondición para el header If-Match; retorna nil
+// si el header no viene, en cuyo caso la escritura no es condicional
+func ifMatchCondition(header string) store.Precondition {
+	if strings.TrimSpace(header) == "" {
+		return nil
+	}
+	return func(current models.Todo) bool {
+		return matchesETag(header, todoETag(current), false)
+	}
+}
+
+// notModified indica si el header If-None-Match coincide con el ETag actual
+func notModified(header, etag string) bool {
+	if strings.TrimSpace(header) == "" {
+		return false
+	}
+	return matchesETag(header, etag, true)
+}
+
+// matchesETag compara una lista de ETags de un header contra etag. If-Match
+// usa comparación fuerte y If-None-Match comparación débil (RFC 9110)
+func matchesETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo-list/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAllLists obtiene las listas compartidas del usuario
+func (h *TodoHandler) GetAllLists(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Listas obtenidas exitosamente",
+		Data:    h.store.Lists(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetListByID obtiene una lista con sus miembros
+func (h *TodoHandler) GetListByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	list, err := h.store.GetList(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Lista encontrada",
+		Data:    list,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateList crea una lista compartida
+func (h *TodoHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var listReq models.ListRequest
+	if err := decodeJSON(r, &listReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &listReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.CreateList(r.Context(), listReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Lista creada exitosamente",
+		Data:    list,
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateList renombra una lista
+func (h *TodoHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var listReq models.ListRequest
+	if err := decodeJSON(r, &listReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &listReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.UpdateList(r.Context(), id, listReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Lista actualizada exitosamente",
+		Data:    list,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteList elimina una lista y sus todos
+func (h *TodoHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteList(r.Context(), id); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Lista eliminada exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// InviteToList invita a un email a una lista
+func (h *TodoHandler) InviteToList(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var inviteReq models.InvitationRequest
+	if err := decodeJSON(r, &inviteReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &inviteReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	invitation, err := h.store.Invite(r.Context(), id, inviteReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Invitación enviada exitosamente",
+		Data:    invitation,
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateListMember cambia el rol de un miembro de la lista
+func (h *TodoHandler) UpdateListMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var memberReq models.MemberRequest
+	if err := decodeJSON(r, &memberReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &memberReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.UpdateMember(r.Context(), id, userID, memberReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Rol actualizado exitosamente",
+		Data:    list,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// RemoveListMember quita a un miembro de la lista, o saca de ella al usuario
+func (h *TodoHandler) RemoveListMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.RemoveMember(r.Context(), id, userID); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Miembro quitado exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetInvitations obtiene las invitaciones pendientes del usuario
+func (h *TodoHandler) GetInvitations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Invitaciones obtenidas exitosamente",
+		Data:    h.store.Invitations(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// AcceptInvitation acepta una invitación y une al usuario a la lista
+func (h *TodoHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var acceptReq models.InvitationAcceptRequest
+	if err := decodeJSON(r, &acceptReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &acceptReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.AcceptInvitation(r.Context(), id, acceptReq.Token)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Invitación aceptada exitosamente",
+		Data:    list,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteInvitation rechaza o cancela una invitación
+func (h *TodoHandler) DeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteInvitation(r.Context(), id); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Invitación eliminada exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllLists obtiene las listas compartidas del usuario
+func (h *TodoHandlerGin) GetAllLists(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Listas obtenidas exitosamente",
+		Data:    h.store.Lists(c.Request.Context()),
+	})
+}
+
+// GetListByID obtiene una lista con sus miembros
+func (h *TodoHandlerGin) GetListByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	list, err := h.store.GetList(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Lista encontrada",
+		Data:    list,
+	})
+}
+
+// CreateList crea una lista compartida
+func (h *TodoHandlerGin) CreateList(c *gin.Context) {
+	var listReq models.ListRequest
+	if err := bindJSON(c, &listReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &listReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.CreateList(c.Request.Context(), listReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Lista creada exitosamente",
+		Data:    list,
+	})
+}
+
+// UpdateList renombra una lista
+func (h *TodoHandlerGin) UpdateList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var listReq models.ListRequest
+	if err := bindJSON(c, &listReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &listReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.UpdateList(c.Request.Context(), id, listReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Lista actualizada exitosamente",
+		Data:    list,
+	})
+}
+
+// DeleteList elimina una lista y sus todos
+func (h *TodoHandlerGin) DeleteList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteList(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Lista eliminada exitosamente",
+	})
+}
+
+// InviteToList invita a un email a una lista
+func (h *TodoHandlerGin) InviteToList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var inviteReq models.InvitationRequest
+	if err := bindJSON(c, &inviteReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &inviteReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	invitation, err := h.store.Invite(c.Request.Context(), id, inviteReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Invitación enviada exitosamente",
+		Data:    invitation,
+	})
+}
+
+// UpdateListMember cambia el rol de un miembro de la lista
+func (h *TodoHandlerGin) UpdateListMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var memberReq models.MemberRequest
+	if err := bindJSON(c, &memberReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &memberReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.UpdateMember(c.Request.Context(), id, userID, memberReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Rol actualizado exitosamente",
+		Data:    list,
+	})
+}
+
+// RemoveListMember quita a un miembro de la lista, o saca de ella al usuario
+func (h *TodoHandlerGin) RemoveListMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Miembro quitado exitosamente",
+	})
+}
+
+// GetInvitations obtiene las invitaciones pendientes del usuario
+func (h *TodoHandlerGin) GetInvitations(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Invitaciones obtenidas exitosamente",
+		Data:    h.store.Invitations(c.Request.Context()),
+	})
+}
+
+// AcceptInvitation acepta una invitación y une al usuario a la lista
+func (h *TodoHandlerGin) AcceptInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var acceptReq models.InvitationAcceptRequest
+	if err := bindJSON(c, &acceptReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &acceptReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	list, err := h.store.AcceptInvitation(c.Request.Context(), id, acceptReq.Token)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Invitación aceptada exitosamente",
+		Data:    list,
+	})
+}
+
+// DeleteInvitation rechaza o cancela una invitación
+func (h *TodoHandlerGin) DeleteInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteInvitation(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Invitación eliminada exitosamente",
+	})
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"strconv"
+	"todo-list/models"
+	"todo-list/store"
+	"todo-list/templates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetListsPage muestra la página de listas compartidas
+func (h *TodoHandlerTempl) GetListsPage(c *gin.Context) {
+	tmpl := templates.GetListsTemplate()
+	tmpl.Execute(c.Writer, h.listsData(c))
+}
+
+// CreateList crea una lista compartida (para HTMX)
+func (h *TodoHandlerTempl) CreateList(c *gin.Context) {
+	listReq := models.ListRequest{Name: c.PostForm("name")}
+	if errs := validateRequest(c.Request, &listReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	if _, err := h.store.CreateList(c.Request.Context(), listReq); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// DeleteList elimina una lista y sus todos (para HTMX)
+func (h *TodoHandlerTempl) DeleteList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteList(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// InviteToList invita a un email a una lista (para HTMX)
+func (h *TodoHandlerTempl) InviteToList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	inviteReq := models.InvitationRequest{
+		Email: c.PostForm("email"),
+		Role:  c.PostForm("role"),
+	}
+	if errs := validateRequest(c.Request, &inviteReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	invitation, err := h.store.Invite(c.Request.Context(), id, inviteReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	// El código solo se muestra esta vez; el owner se lo pasa al invitado
+	data := h.listsData(c)
+	data.NewInvitation = &invitation
+	templates.GetListsPanelTemplate().Execute(c.Writer, data)
+}
+
+// UpdateListMember cambia el rol de un miembro (para HTMX)
+func (h *TodoHandlerTempl) UpdateListMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	memberReq := models.MemberRequest{Role: c.PostForm("role")}
+	if errs := validateRequest(c.Request, &memberReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	if _, err := h.store.UpdateMember(c.Request.Context(), id, userID, memberReq); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// RemoveListMember quita a un miembro o saca al usuario de la lista (para HTMX)
+func (h *TodoHandlerTempl) RemoveListMember(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	userID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// AcceptInvitation acepta una invitación (para HTMX)
+func (h *TodoHandlerTempl) AcceptInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	acceptReq := models.InvitationAcceptRequest{Token: c.PostForm("token")}
+	if errs := validateRequest(c.Request, &acceptReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	if _, err := h.store.AcceptInvitation(c.Request.Context(), id, acceptReq.Token); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// DeleteInvitation rechaza o cancela una invitación (para HTMX)
+func (h *TodoHandlerTempl) DeleteInvitation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteInvitation(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	h.renderListsPanel(c)
+}
+
+// renderListsPanel devuelve el panel de listas actualizado
+func (h *TodoHandlerTempl) renderListsPanel(c *gin.Context) {
+	tmpl := templates.GetListsPanelTemplate()
+	tmpl.Execute(c.Writer, h.listsData(c))
+}
+
+// listsData arma los datos de la página y el panel de listas
+func (h *TodoHandlerTempl) listsData(c *gin.Context) templates.ListsPageData {
+	user, _ := store.UserFrom(c.Request.Context())
+	return templates.ListsPageData{
+		Title:       "Todo List - Listas compartidas",
+		CSRFToken:   csrfToken(c),
+		User:        user,
+		Lists:       h.store.Lists(c.Request.Context()),
+		Invitations: h.store.Invitations(c.Request.Context()),
+	}
+}
+
+// listNames obtiene el nombre de cada lista del usuario, para etiquetar los todos
+func (h *TodoHandlerTempl) listNames(c *gin.Context) map[int]string {
+	names := make(map[int]string)
+	for _, list := range h.store.Lists(c.Request.Context()) {
+		names[list.ID] = list.Name
+	}
+	return names
+}
+
+// assigneeNames obtiene el nombre de las personas que pueden ser responsables
+// de los todos del usuario: él mismo y los miembros de sus listas
+func (h *TodoHandlerTempl) assigneeNames(c *gin.Context) map[int]string {
+	user, _ := store.UserFrom(c.Request.Context())
+	names := map[int]string{user.ID: user.Name}
+	for _, list := range h.store.Lists(c.Request.Context()) {
+		for _, member := range list.Members {
+			names[member.UserID] = member.Name
+		}
+	}
+	return names
+}
+
+// assigneeOptions obtiene a quiénes se puede asignar el todo: los miembros
+// de su lista o, si es personal, solo el usuario
+func (h *TodoHandlerTempl) assigneeOptions(c *gin.Context, todo models.Todo) []models.ListMember {
+	if todo.ListID != 0 {
+		if list, err := h.store.GetList(c.Request.Context(), todo.ListID); err == nil {
+			return list.Members
+		}
+	}
+	user, _ := store.UserFrom(c.Request.Context())
+	return []models.ListMember{{UserID: user.ID, Name: user.Name, Email: user.Email}}
+}
+
+// writableLists obtiene las listas en las que el usuario puede crear todos
+func (h *TodoHandlerTempl) writableLists(c *gin.Context) []models.List {
+	var lists []models.List
+	for _, list := range h.store.Lists(c.Request.Context()) {
+		if models.RoleRank(list.Role) >= models.RoleRank(models.RoleEditor) {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WriteProblem escribe un error problem+json; si no trae instance usa la ruta pedida
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem models.Problem) {
+	if problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", models.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// AbortWithProblem escribe un error problem+json y corta la cadena de Gin
+func AbortWithProblem(c *gin.Context, problem models.Problem) {
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", models.ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// invalidIDProblem se usa cuando el ID de la ruta no es un número
+func invalidIDProblem() models.Problem {
+	return models.NewProblem(http.StatusBadRequest, models.CodeInvalidID, "ID inválido")
+}
+
+// ProblemFromError traduce un error del store a su problema equivalente, para
+// los transportes que no pasan por estos handlers (GraphQL, gRPC)
+func ProblemFromError(err error) models.Problem {
+	return problemFromError(err)
+}
+
+// problemFromError traduce los errores del store a su problema equivalente
+func problemFromError(err error) models.Problem {
+	var missing *store.MissingValuesError
+	var invalid *store.InvalidValuesError
+	switch {
+	case errors.As(err, &missing):
+		fieldErrors := make([]models.FieldError, 0, len(missing.Names))
+		for _, name := range missing.Names {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   "values." + name,
+				Code:    models.FieldRequired,
+				Message: "Falta el valor para el marcador " + name,
+			})
+		}
+		problem := models.NewProblem(http.StatusBadRequest, models.CodeMissingValues,
+			"Faltan valores para los marcadores: "+strings.Join(missing.Names, ", "))
+		problem.Errors = fieldErrors
+		return problem
+	case errors.As(err, &invalid):
+		problem := models.NewProblem(http.StatusBadRequest, models.CodeInvalidValues,
+			"Los valores de estos marcadores dejan tareas inválidas: "+strings.Join(invalid.Names, ", "))
+		problem.Errors = invalid.Errors
+		return problem
+	case errors.Is(err, store.ErrNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeTodoNotFound, "Todo no encontrado")
+	case errors.Is(err, store.ErrWebhookNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeWebhookNotFound, "Webhook no encontrado")
+	case errors.Is(err, store.ErrWebhookTarget):
+		return models.NewValidationProblem([]models.FieldError{{
+			Field:   "url",
+			Code:    models.FieldInvalid,
+			Message: "La URL no puede apuntar a una dirección privada, local o de metadatos",
+		}})
+	case errors.Is(err, store.ErrDeliveryNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeDeliveryNotFound, "Entrega no encontrada")
+	case errors.Is(err, store.ErrDeliveryNotDead):
+		return models.NewProblem(http.StatusConflict, models.CodeDeliveryNotDead, "Solo se pueden reentregar las entregas en dead letters")
+	case errors.Is(err, store.ErrTemplateNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeTemplateNotFound, "Plantilla no encontrada")
+	case errors.Is(err, store.ErrPreconditionFailed):
+		return models.NewProblem(http.StatusPreconditionFailed, models.CodeVersionMismatch,
+			"El todo fue modificado por otra persona; recárgalo e inténtalo de nuevo")
+	case errors.Is(err, store.ErrEmailTaken):
+		return models.NewProblem(http.StatusConflict, models.CodeEmailTaken, "Ya existe una cuenta con ese email")
+	case errors.Is(err, store.ErrInvalidCredentials):
+		return models.NewProblem(http.StatusUnauthorized, models.CodeInvalidCredentials, "Email o contraseña incorrectos")
+	case errors.Is(err, store.ErrTokenNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeTokenNotFound, "Token no encontrado")
+	case errors.Is(err, store.ErrListNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeListNotFound, "Lista no encontrada")
+	case errors.Is(err, store.ErrForbidden):
+		return models.NewProblem(http.StatusForbidden, models.CodeForbidden, "Tu rol en la lista no permite esta operación")
+	case errors.Is(err, store.ErrInvitationNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeInvitationNotFound, "Invitación no encontrada")
+	case errors.Is(err, store.ErrMemberNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeMemberNotFound, "Miembro no encontrado")
+	case errors.Is(err, store.ErrAlreadyMember):
+		return models.NewProblem(http.StatusConflict, models.CodeAlreadyMember, "Esa persona ya es miembro de la lista")
+	case errors.Is(err, store.ErrLastOwner):
+		return models.NewProblem(http.StatusConflict, models.CodeLastOwner, "La lista debe tener al menos un owner")
+	case errors.Is(err, store.ErrWorkspaceNotFound):
+		return models.NewProblem(http.StatusNotFound, models.CodeWorkspaceNotFound, "Workspace no encontrado")
+	case errors.Is(err, store.ErrQuotaExceeded):
+		return models.NewProblem(http.StatusForbidden, models.CodeQuotaExceeded, "El workspace alcanzó su límite de tareas")
+	case errors.Is(err, store.ErrInvalidAssignee):
+		return models.NewProblem(http.StatusUnprocessableEntity, models.CodeInvalidAssignee,
+			"El responsable y los observadores deben poder ver la tarea: su dueño o un miembro de la lista")
+	case errors.Is(err, store.ErrNotCompleted):
+		return models.NewProblem(http.StatusConflict, models.CodeTodoNotCompleted, "Solo se pueden archivar tareas completadas")
+	default:
+		return models.NewProblem(http.StatusInternalServerError, models.CodeInternal, "Error interno del servidor")
+	}
+}
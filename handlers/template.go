@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo-list/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetAllTemplates obtiene todas las plantillas
+func (h *TodoHandler) GetAllTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Plantillas obtenidas exitosamente",
+		Data:    h.store.ListTemplates(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTemplateByID obtiene una plantilla por ID
+func (h *TodoHandler) GetTemplateByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	tmpl, err := h.store.GetTemplate(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Plantilla encontrada",
+		Data:    tmpl,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateTemplate crea una nueva plantilla
+func (h *TodoHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var tmplReq models.TodoTemplateRequest
+	if err := decodeJSON(r, &tmplReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &tmplReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	tmpl, err := h.store.CreateTemplate(r.Context(), tmplReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Plantilla creada exitosamente",
+		Data:    tmpl,
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateTemplate actualiza una plantilla existente
+func (h *TodoHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var tmplReq models.TodoTemplateRequest
+	if err := decodeJSON(r, &tmplReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &tmplReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	tmpl, err := h.store.UpdateTemplate(r.Context(), id, tmplReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Plantilla actualizada exitosamente",
+		Data:    tmpl,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteTemplate elimina una plantilla
+func (h *TodoHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteTemplate(r.Context(), id); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Plantilla eliminada exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// InstantiateTemplate crea los todos definidos en una plantilla
+func (h *TodoHandler) InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var instReq models.InstantiateRequest
+	if err := decodeJSON(r, &instReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &instReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	todos, err := h.store.InstantiateTemplate(r.Context(), id, instReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Todos creados desde la plantilla exitosamente",
+		Data:    todos,
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
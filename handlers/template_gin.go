@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllTemplates obtiene todas las plantillas
+func (h *TodoHandlerGin) GetAllTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantillas obtenidas exitosamente",
+		Data:    h.store.ListTemplates(c.Request.Context()),
+	})
+}
+
+// GetTemplateByID obtiene una plantilla por ID
+func (h *TodoHandlerGin) GetTemplateByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	tmpl, err := h.store.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantilla encontrada",
+		Data:    tmpl,
+	})
+}
+
+// CreateTemplate crea una nueva plantilla
+func (h *TodoHandlerGin) CreateTemplate(c *gin.Context) {
+	var tmplReq models.TodoTemplateRequest
+	if err := bindJSON(c, &tmplReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &tmplReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	tmpl, err := h.store.CreateTemplate(c.Request.Context(), tmplReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Plantilla creada exitosamente",
+		Data:    tmpl,
+	})
+}
+
+// UpdateTemplate actualiza una plantilla existente
+func (h *TodoHandlerGin) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var tmplReq models.TodoTemplateRequest
+	if err := bindJSON(c, &tmplReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &tmplReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	tmpl, err := h.store.UpdateTemplate(c.Request.Context(), id, tmplReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantilla actualizada exitosamente",
+		Data:    tmpl,
+	})
+}
+
+// DeleteTemplate elimina una plantilla
+func (h *TodoHandlerGin) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteTemplate(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantilla eliminada exitosamente",
+	})
+}
+
+// InstantiateTemplate crea los todos definidos en una plantilla
+func (h *TodoHandlerGin) InstantiateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var instReq models.InstantiateRequest
+	if err := bindJSON(c, &instReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &instReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	todos, err := h.store.InstantiateTemplate(c.Request.Context(), id, instReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Todos creados desde la plantilla exitosamente",
+		Data:    todos,
+	})
+}
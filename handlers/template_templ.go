@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list/models"
+	"todo-list/store"
+	"todo-list/templates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAllTemplates obtiene todas las plantillas
+func (h *TodoHandlerTempl) GetAllTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantillas obtenidas exitosamente",
+		Data:    h.store.ListTemplates(c.Request.Context()),
+	})
+}
+
+// CreateTemplate crea una nueva plantilla
+func (h *TodoHandlerTempl) CreateTemplate(c *gin.Context) {
+	var tmplReq models.TodoTemplateRequest
+	if err := bindJSON(c, &tmplReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &tmplReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	tmpl, err := h.store.CreateTemplate(c.Request.Context(), tmplReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Plantilla creada exitosamente",
+		Data:    tmpl,
+	})
+}
+
+// DeleteTemplate elimina una plantilla
+func (h *TodoHandlerTempl) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.DeleteTemplate(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Plantilla eliminada exitosamente",
+	})
+}
+
+// GetTemplateForm muestra los campos para instanciar la plantilla elegida (HTMX)
+func (h *TodoHandlerTempl) GetTemplateForm(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("template_id"))
+	if err != nil {
+		// Sin plantilla seleccionada no hay campos que mostrar
+		c.String(http.StatusOK, "")
+		return
+	}
+	
+	tmpl, err := h.store.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	data := templates.TemplateFormData{
+		Template:     tmpl,
+		Placeholders: store.Placeholders(tmpl),
+	}
+	
+	form := templates.GetTemplateFormTemplate()
+	form.Execute(c.Writer, data)
+}
+
+// InstantiateTemplate crea los todos de una plantilla (para HTMX)
+func (h *TodoHandlerTempl) InstantiateTemplate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	// Los valores llegan como campos values[nombre] del formulario
+	instReq := models.InstantiateRequest{
+		Values: c.PostFormMap("values"),
+	}
+	if startDate := c.PostForm("start_date"); startDate != "" {
+		start, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			AbortWithProblem(c, models.NewValidationProblem([]models.FieldError{{
+				Field:   "start_date",
+				Code:    models.FieldInvalid,
+				Message: "Fecha de inicio inválida",
+			}}))
+			return
+		}
+		instReq.StartDate = &start
+	}
+	
+	if errs := validateRequest(c.Request, &instReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	if _, err := h.store.InstantiateTemplate(c.Request.Context(), id, instReq); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	// Redirigir para recargar la página
+	c.Redirect(http.StatusSeeOther, "/")
+}
@@ -1,37 +1,59 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
 	"todo-list/models"
+	"todo-list/store"
 
 	"github.com/gorilla/mux"
 )
 
+// defaultStatsDays es el rango por defecto del burndown en días
+const defaultStatsDays = 14
+
+// maxStatsDays es el rango máximo permitido para el burndown
+const maxStatsDays = 365
+
 // TodoHandler maneja las operaciones CRUD de todos
 type TodoHandler struct {
-	todos []models.Todo
-	nextID int
+	store *store.TodoStore
 }
 
 // NewTodoHandler crea una nueva instancia del handler
-func NewTodoHandler() *TodoHandler {
+func NewTodoHandler(todoStore *store.TodoStore) *TodoHandler {
 	return &TodoHandler{
-		todos:  make([]models.Todo, 0),
-		nextID: 1,
+		store: todoStore,
 	}
 }
 
-// GetAllTodos obtiene todos los todos
+// GetAllTodos obtiene todos los todos; ?assignee= filtra por responsable
 func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
+	assignee, filtered, err := parseAssignee(r.Context(), r.URL.Query().Get("assignee"))
+	if err != nil {
+		WriteProblem(w, r, invalidAssigneeProblem())
+		return
+	}
+	
+	todos := h.store.List(r.Context())
+	if filtered {
+		todos = assignedTo(todos, assignee)
+	}
+	etag := listETag(todos)
+	w.Header().Set("ETag", etag)
+	if notModified(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	
 	response := models.Response{
 		Success: true,
 		Message: "Todos obtenidos exitosamente",
-		Data:    h.todos,
+		Data:    todos,
 	}
 	
 	json.NewEncoder(w).Encode(response)
@@ -44,27 +66,28 @@ func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		WriteProblem(w, r, invalidIDProblem())
 		return
 	}
 	
-	for _, todo := range h.todos {
-		if todo.ID == id {
-			response := models.Response{
-				Success: true,
-				Message: "Todo encontrado",
-				Data:    todo,
-			}
-			json.NewEncoder(w).Encode(response)
-			return
-		}
+	todo, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	etag := todoETag(todo)
+	w.Header().Set("ETag", etag)
+	if notModified(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 	
 	response := models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+		Success: true,
+		Message: "Todo encontrado",
+		Data:    todo,
 	}
-	w.WriteHeader(http.StatusNotFound)
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -73,38 +96,23 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	
 	var todoReq models.TodoRequest
-	if err := json.NewDecoder(r.Body).Decode(&todoReq); err != nil {
-		response := models.Response{
-			Success: false,
-			Message: "Datos inválidos",
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(response)
+	if err := decodeJSON(r, &todoReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
 		return
 	}
 	
-	if todoReq.Title == "" {
-		response := models.Response{
-			Success: false,
-			Message: "El título es requerido",
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(response)
+	if errs := validateRequest(r, &todoReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
 		return
 	}
 	
-	todo := models.Todo{
-		ID:          h.nextID,
-		Title:       todoReq.Title,
-		Description: todoReq.Description,
-		Completed:   todoReq.Completed,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	todo, err := h.store.Create(r.Context(), todoReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
 	}
 	
-	h.todos = append(h.todos, todo)
-	h.nextID++
-	
+	w.Header().Set("ETag", todoETag(todo))
 	response := models.Response{
 		Success: true,
 		Message: "Todo creado exitosamente",
@@ -122,43 +130,70 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		WriteProblem(w, r, invalidIDProblem())
 		return
 	}
 	
 	var todoReq models.TodoRequest
-	if err := json.NewDecoder(r.Body).Decode(&todoReq); err != nil {
-		response := models.Response{
-			Success: false,
-			Message: "Datos inválidos",
-		}
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
-	
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos[i].Title = todoReq.Title
-			h.todos[i].Description = todoReq.Description
-			h.todos[i].Completed = todoReq.Completed
-			h.todos[i].UpdatedAt = time.Now()
-			
-			response := models.Response{
-				Success: true,
-				Message: "Todo actualizado exitosamente",
-				Data:    h.todos[i],
-			}
-			json.NewEncoder(w).Encode(response)
-			return
-		}
+	if err := decodeJSON(r, &todoReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &todoReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	todo, err := h.store.UpdateIf(r.Context(), id, todoReq, ifMatchCondition(r.Header.Get("If-Match")))
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	w.Header().Set("ETag", todoETag(todo))
+	response := models.Response{
+		Success: true,
+		Message: "Todo actualizado exitosamente",
+		Data:    todo,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// PatchTodo actualiza parcialmente un todo existente
+func (h *TodoHandler) PatchTodo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var patch models.TodoPatch
+	if err := decodeJSON(r, &patch); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &patch); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
 	}
 	
+	todo, err := h.store.PatchIf(r.Context(), id, patch, ifMatchCondition(r.Header.Get("If-Match")))
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	w.Header().Set("ETag", todoETag(todo))
 	response := models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+		Success: true,
+		Message: "Todo actualizado exitosamente",
+		Data:    todo,
 	}
-	w.WriteHeader(http.StatusNotFound)
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -169,27 +204,217 @@ func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "ID inválido", http.StatusBadRequest)
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	err = h.store.DeleteIf(r.Context(), id, ifMatchCondition(r.Header.Get("If-Match")))
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Todo eliminado exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStats obtiene las estadísticas de estimación, throughput y burndown
+func (h *TodoHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	days, err := parseStatsDays(r.URL.Query().Get("days"))
+	if err != nil {
+		WriteProblem(w, r, invalidDaysProblem())
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Estadísticas obtenidas exitosamente",
+		Data:    h.store.Stats(r.Context(), days),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetTodoHistory obtiene el historial de cambios de un todo
+func (h *TodoHandler) GetTodoHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	entries, err := h.store.History(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
 		return
 	}
 	
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos = append(h.todos[:i], h.todos[i+1:]...)
-			
-			response := models.Response{
-				Success: true,
-				Message: "Todo eliminado exitosamente",
-			}
-			json.NewEncoder(w).Encode(response)
-			return
+	response := models.Response{
+		Success: true,
+		Message: "Historial obtenido exitosamente",
+		Data:    entries,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseStatsDays interpreta el rango en días del burndown
+func parseStatsDays(value string) (int, error) {
+	if value == "" {
+		return defaultStatsDays, nil
+	}
+	
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 || days > maxStatsDays {
+		return 0, strconv.ErrRange
+	}
+	return days, nil
+}
+
+// invalidDaysProblem se usa cuando el parámetro days está fuera de rango
+func invalidDaysProblem() models.Problem {
+	return models.NewValidationProblem([]models.FieldError{{
+		Field:   "days",
+		Code:    models.FieldOutOfRange,
+		Message: "El parámetro days debe estar entre 1 y " + strconv.Itoa(maxStatsDays),
+	}})
+}
+
+// parseAssignee interpreta el filtro assignee: "me" es el usuario de la
+// sesión, "none" los todos sin responsable y un número el ID de una cuenta.
+// filtered es false si no se pidió filtrar
+func parseAssignee(ctx context.Context, value string) (assignee int, filtered bool, err error) {
+	switch value {
+	case "":
+		return 0, false, nil
+	case "me":
+		return store.UserID(ctx), true, nil
+	case "none":
+		return 0, true, nil
+	}
+	
+	assignee, err = strconv.Atoi(value)
+	if err != nil || assignee < 1 {
+		return 0, false, strconv.ErrSyntax
+	}
+	return assignee, true, nil
+}
+
+// assignedTo deja los todos cuyo responsable es assignee; cero son los que
+// no tienen responsable
+func assignedTo(todos []models.Todo, assignee int) []models.Todo {
+	matched := make([]models.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if todo.AssigneeID == assignee {
+			matched = append(matched, todo)
 		}
 	}
+	return matched
+}
+
+// invalidAssigneeProblem se usa cuando el parámetro assignee no es válido
+func invalidAssigneeProblem() models.Problem {
+	return models.NewValidationProblem([]models.FieldError{{
+		Field:   "assignee",
+		Code:    models.FieldInvalid,
+		Message: `El parámetro assignee debe ser "me", "none" o el ID de una cuenta`,
+	}})
+}
+
+// GetArchivedTodos obtiene los todos archivados, filtrados por el parámetro q
+func (h *TodoHandler) GetArchivedTodos(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Todos archivados obtenidos exitosamente",
+		Data:    h.store.ListArchived(r.Context(), r.URL.Query().Get("q")),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// ArchiveTodo archiva un todo completado
+func (h *TodoHandler) ArchiveTodo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	todo, err := h.store.Archive(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
 	
 	response := models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+		Success: true,
+		Message: "Todo archivado exitosamente",
+		Data:    todo,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// UnarchiveTodo devuelve un todo archivado a la lista
+func (h *TodoHandler) UnarchiveTodo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	todo, err := h.store.Unarchive(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Todo desarchivado exitosamente",
+		Data:    todo,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// BatchTodos ejecuta varias operaciones sobre todos de forma atómica
+func (h *TodoHandler) BatchTodos(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var batchReq models.BatchRequest
+	if err := decodeJSON(r, &batchReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &batchReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	results, err := h.store.Batch(r.Context(), batchReq.Operations)
+	if err != nil {
+		problem := models.NewProblem(http.StatusUnprocessableEntity, models.CodeBatchFailed, "Ninguna operación fue aplicada porque al menos una falló")
+		problem.Results = results
+		WriteProblem(w, r, problem)
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Lote aplicado exitosamente",
+		Data:    results,
 	}
-	w.WriteHeader(http.StatusNotFound)
 	json.NewEncoder(w).Encode(response)
 }
@@ -3,32 +3,47 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"time"
 	"todo-list/models"
+	"todo-list/store"
 
 	"github.com/gin-gonic/gin"
 )
 
 // TodoHandlerGin maneja las operaciones CRUD de todos usando Gin
 type TodoHandlerGin struct {
-	todos  []models.Todo
-	nextID int
+	store *store.TodoStore
 }
 
 // NewTodoHandlerGin crea una nueva instancia del handler con Gin
-func NewTodoHandlerGin() *TodoHandlerGin {
+func NewTodoHandlerGin(todoStore *store.TodoStore) *TodoHandlerGin {
 	return &TodoHandlerGin{
-		todos:  make([]models.Todo, 0),
-		nextID: 1,
+		store: todoStore,
 	}
 }
 
-// GetAllTodos obtiene todos los todos
+// GetAllTodos obtiene todos los todos; ?assignee= filtra por responsable
 func (h *TodoHandlerGin) GetAllTodos(c *gin.Context) {
+	assignee, filtered, err := parseAssignee(c.Request.Context(), c.Query("assignee"))
+	if err != nil {
+		AbortWithProblem(c, invalidAssigneeProblem())
+		return
+	}
+	
+	todos := h.store.List(c.Request.Context())
+	if filtered {
+		todos = assignedTo(todos, assignee)
+	}
+	etag := listETag(todos)
+	c.Header("ETag", etag)
+	if notModified(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	
 	response := models.Response{
 		Success: true,
 		Message: "Todos obtenidos exitosamente",
-		Data:    h.todos,
+		Data:    todos,
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -39,62 +54,50 @@ func (h *TodoHandlerGin) GetTodoByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "ID inválido",
-		})
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
-	for _, todo := range h.todos {
-		if todo.ID == id {
-			response := models.Response{
-				Success: true,
-				Message: "Todo encontrado",
-				Data:    todo,
-			}
-			c.JSON(http.StatusOK, response)
-			return
-		}
+	todo, err := h.store.Get(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	etag := todoETag(todo)
+	c.Header("ETag", etag)
+	if notModified(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
 	}
 	
-	c.JSON(http.StatusNotFound, models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo encontrado",
+		Data:    todo,
 	})
 }
 
 // CreateTodo crea un nuevo todo
 func (h *TodoHandlerGin) CreateTodo(c *gin.Context) {
 	var todoReq models.TodoRequest
-	if err := c.ShouldBindJSON(&todoReq); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "Datos inválidos: " + err.Error(),
-		})
+	if err := bindJSON(c, &todoReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
 		return
 	}
 	
-	if todoReq.Title == "" {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "El título es requerido",
-		})
+	if errs := validateRequest(c.Request, &todoReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
 		return
 	}
 	
-	todo := models.Todo{
-		ID:          h.nextID,
-		Title:       todoReq.Title,
-		Description: todoReq.Description,
-		Completed:   todoReq.Completed,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	todo, err := h.store.Create(c.Request.Context(), todoReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	h.todos = append(h.todos, todo)
-	h.nextID++
-	
+	c.Header("ETag", todoETag(todo))
 	response := models.Response{
 		Success: true,
 		Message: "Todo creado exitosamente",
@@ -109,42 +112,66 @@ func (h *TodoHandlerGin) UpdateTodo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "ID inválido",
-		})
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
 	var todoReq models.TodoRequest
-	if err := c.ShouldBindJSON(&todoReq); err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "Datos inválidos: " + err.Error(),
-		})
-		return
-	}
-	
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos[i].Title = todoReq.Title
-			h.todos[i].Description = todoReq.Description
-			h.todos[i].Completed = todoReq.Completed
-			h.todos[i].UpdatedAt = time.Now()
-			
-			response := models.Response{
-				Success: true,
-				Message: "Todo actualizado exitosamente",
-				Data:    h.todos[i],
-			}
-			c.JSON(http.StatusOK, response)
-			return
-		}
-	}
-	
-	c.JSON(http.StatusNotFound, models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+	if err := bindJSON(c, &todoReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &todoReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	todo, err := h.store.UpdateIf(c.Request.Context(), id, todoReq, ifMatchCondition(c.GetHeader("If-Match")))
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.Header("ETag", todoETag(todo))
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo actualizado exitosamente",
+		Data:    todo,
+	})
+}
+
+// PatchTodo actualiza parcialmente un todo existente
+func (h *TodoHandlerGin) PatchTodo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var patch models.TodoPatch
+	if err := bindJSON(c, &patch); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &patch); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	todo, err := h.store.PatchIf(c.Request.Context(), id, patch, ifMatchCondition(c.GetHeader("If-Match")))
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.Header("ETag", todoETag(todo))
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo actualizado exitosamente",
+		Data:    todo,
 	})
 }
 
@@ -153,29 +180,136 @@ func (h *TodoHandlerGin) DeleteTodo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.Response{
-			Success: false,
-			Message: "ID inválido",
-		})
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	err = h.store.DeleteIf(c.Request.Context(), id, ifMatchCondition(c.GetHeader("If-Match")))
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo eliminado exitosamente",
+	})
+}
+
+// GetStats obtiene las estadísticas de estimación, throughput y burndown
+func (h *TodoHandlerGin) GetStats(c *gin.Context) {
+	days, err := parseStatsDays(c.Query("days"))
+	if err != nil {
+		AbortWithProblem(c, invalidDaysProblem())
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Estadísticas obtenidas exitosamente",
+		Data:    h.store.Stats(c.Request.Context(), days),
+	})
+}
+
+// GetTodoHistory obtiene el historial de cambios de un todo
+func (h *TodoHandlerGin) GetTodoHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	entries, err := h.store.History(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Historial obtenido exitosamente",
+		Data:    entries,
+	})
+}
+
+// GetArchivedTodos obtiene los todos archivados, filtrados por el parámetro q
+func (h *TodoHandlerGin) GetArchivedTodos(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todos archivados obtenidos exitosamente",
+		Data:    h.store.ListArchived(c.Request.Context(), c.Query("q")),
+	})
+}
+
+// ArchiveTodo archiva un todo completado
+func (h *TodoHandlerGin) ArchiveTodo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos = append(h.todos[:i], h.todos[i+1:]...)
-			
-			response := models.Response{
-				Success: true,
-				Message: "Todo eliminado exitosamente",
-			}
-			c.JSON(http.StatusOK, response)
-			return
-		}
+	todo, err := h.store.Archive(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	c.JSON(http.StatusNotFound, models.Response{
-		Success: false,
-		Message: "Todo no encontrado",
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo archivado exitosamente",
+		Data:    todo,
+	})
+}
+
+// UnarchiveTodo devuelve un todo archivado a la lista
+func (h *TodoHandlerGin) UnarchiveTodo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	todo, err := h.store.Unarchive(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo desarchivado exitosamente",
+		Data:    todo,
+	})
+}
+
+// BatchTodos ejecuta varias operaciones sobre todos de forma atómica
+func (h *TodoHandlerGin) BatchTodos(c *gin.Context) {
+	var batchReq models.BatchRequest
+	if err := bindJSON(c, &batchReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &batchReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	results, err := h.store.Batch(c.Request.Context(), batchReq.Operations)
+	if err != nil {
+		problem := models.NewProblem(http.StatusUnprocessableEntity, models.CodeBatchFailed, "Ninguna operación fue aplicada porque al menos una falló")
+		problem.Results = results
+		AbortWithProblem(c, problem)
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Lote aplicado exitosamente",
+		Data:    results,
 	})
 }
 
@@ -3,33 +3,39 @@ package handlers
 import (
 	"net/http"
 	"strconv"
-	"time"
 	"todo-list/models"
+	"todo-list/store"
 	"todo-list/templates"
 	"github.com/gin-gonic/gin"
 )
 
 // TodoHandlerTempl maneja las operaciones CRUD usando Templ y HTMX
 type TodoHandlerTempl struct {
-	todos  []models.Todo
-	nextID int
+	store *store.TodoStore
 }
 
 // NewTodoHandlerTempl crea una nueva instancia del handler con Templ
-func NewTodoHandlerTempl() *TodoHandlerTempl {
+func NewTodoHandlerTempl(todoStore *store.TodoStore) *TodoHandlerTempl {
 	return &TodoHandlerTempl{
-		todos:  make([]models.Todo, 0),
-		nextID: 1,
+		store: todoStore,
 	}
 }
 
 // GetHomePage muestra la página principal
 func (h *TodoHandlerTempl) GetHomePage(c *gin.Context) {
-	stats := h.calculateStats()
+	stats := h.calculateStats(c)
+	user, _ := store.UserFrom(c.Request.Context())
 	data := templates.PageData{
-		Title: "Todo List - Gestor de Tareas",
-		Todos: h.todos,
-		Stats: stats,
+		Title:     "Todo List - Gestor de Tareas",
+		CSRFToken: csrfToken(c),
+		User:      user,
+		Todos:     h.store.List(c.Request.Context()),
+		Stats:     stats,
+		Burndown:  templates.NewBurndownChart(h.store.Stats(c.Request.Context(), defaultStatsDays).Burndown),
+		Templates: h.store.ListTemplates(c.Request.Context()),
+		Lists:     h.writableLists(c),
+		ListNames: h.listNames(c),
+		Assignees: h.assigneeNames(c),
 	}
 	
 	tmpl := templates.GetLayoutTemplate()
@@ -39,51 +45,48 @@ func (h *TodoHandlerTempl) GetHomePage(c *gin.Context) {
 // GetAllTodos obtiene todos los todos (para HTMX)
 func (h *TodoHandlerTempl) GetAllTodos(c *gin.Context) {
 	filter := c.Query("filter")
-	filteredTodos := h.getFilteredTodos(filter)
+	filteredTodos := h.getFilteredTodos(c, filter)
 	
 	data := templates.TodoListData{
-		Todos: filteredTodos,
+		Todos:     filteredTodos,
+		ListNames: h.listNames(c),
+		Assignees: h.assigneeNames(c),
 	}
 	
 	tmpl := templates.GetTodoListTemplate()
 	tmpl.Execute(c.Writer, data)
 }
 
+// GetStats renderiza el bloque de estadísticas (para HTMX)
+func (h *TodoHandlerTempl) GetStats(c *gin.Context) {
+	tmpl := templates.GetStatsTemplate()
+	tmpl.Execute(c.Writer, h.calculateStats(c))
+}
+
 // CreateTodo crea un nuevo todo (para HTMX)
 func (h *TodoHandlerTempl) CreateTodo(c *gin.Context) {
 	var todoReq models.TodoRequest
 	var err error
 	
-	// Intentar bindear como JSON primero
-	err = c.ShouldBindJSON(&todoReq)
+	// JSON estricto, o form data si la petición no es JSON
+	err = bindJSONOrForm(c, &todoReq)
 	if err != nil {
-		// Si falla JSON, intentar como form data
-		err = c.ShouldBind(&todoReq)
-		if err != nil {
-			c.String(http.StatusBadRequest, "No se pudo procesar los datos: "+err.Error())
-			return
-		}
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
 	}
 	
 	// Validar datos
-	if todoReq.Title == "" {
-		c.String(http.StatusBadRequest, "El título es requerido")
+	if errs := validateRequest(c.Request, &todoReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
 		return
 	}
 	
 	// Crear el todo
-	todo := models.Todo{
-		ID:          h.nextID,
-		Title:       todoReq.Title,
-		Description: todoReq.Description,
-		Completed:   todoReq.Completed,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	if _, err := h.store.Create(c.Request.Context(), todoReq); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	h.todos = append(h.todos, todo)
-	h.nextID++
-	
 	// Redirigir para recargar la página
 	c.Redirect(http.StatusSeeOther, "/")
 }
@@ -93,22 +96,21 @@ func (h *TodoHandlerTempl) GetTodoByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.String(http.StatusBadRequest, "ID inválido")
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
-	for _, todo := range h.todos {
-		if todo.ID == id {
-			c.JSON(http.StatusOK, models.Response{
-				Success: true,
-				Message: "Todo encontrado",
-				Data:    todo,
-			})
-			return
-		}
+	todo, err := h.store.Get(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	c.String(http.StatusNotFound, "Todo no encontrado")
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Todo encontrado",
+		Data:    todo,
+	})
 }
 
 // UpdateTodo actualiza un todo existente (para HTMX)
@@ -116,44 +118,34 @@ func (h *TodoHandlerTempl) UpdateTodo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.String(http.StatusBadRequest, "ID inválido")
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
 	var todoReq models.TodoRequest
 	
-	// Intentar bindear como JSON primero
-	err = c.ShouldBindJSON(&todoReq)
+	// JSON estricto, o form data si la petición no es JSON
+	err = bindJSONOrForm(c, &todoReq)
 	if err != nil {
-		// Si falla JSON, intentar como form data
-		err = c.ShouldBind(&todoReq)
-		if err != nil {
-			c.String(http.StatusBadRequest, "No se pudo procesar los datos: "+err.Error())
-			return
-		}
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
 	}
 	
 	// Validar datos
-	if todoReq.Title == "" {
-		c.String(http.StatusBadRequest, "El título es requerido")
+	if errs := validateRequest(c.Request, &todoReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
 		return
 	}
 	
-	// Buscar y actualizar el todo
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos[i].Title = todoReq.Title
-			h.todos[i].Description = todoReq.Description
-			h.todos[i].Completed = todoReq.Completed
-			h.todos[i].UpdatedAt = time.Now()
-			
-			// Redirigir para recargar la página
-			c.Redirect(http.StatusSeeOther, "/")
-			return
-		}
+	// Buscar y actualizar el todo, respetando If-Match si viene
+	_, err = h.store.UpdateIf(c.Request.Context(), id, todoReq, ifMatchCondition(c.GetHeader("If-Match")))
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	c.String(http.StatusNotFound, "Todo no encontrado")
+	// Redirigir para recargar la página
+	c.Redirect(http.StatusSeeOther, "/")
 }
 
 // DeleteTodo elimina un todo (para HTMX)
@@ -161,21 +153,17 @@ func (h *TodoHandlerTempl) DeleteTodo(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.String(http.StatusBadRequest, "ID inválido")
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
-	for i, todo := range h.todos {
-		if todo.ID == id {
-			h.todos = append(h.todos[:i], h.todos[i+1:]...)
-			
-			// Redirigir para recargar la página
-			c.Redirect(http.StatusSeeOther, "/")
-			return
-		}
+	if err := h.store.Delete(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	c.String(http.StatusNotFound, "Todo no encontrado")
+	// Redirigir para recargar la página
+	c.Redirect(http.StatusSeeOther, "/")
 }
 
 // GetEditModal muestra el modal de edición
@@ -183,19 +171,85 @@ func (h *TodoHandlerTempl) GetEditModal(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		c.String(http.StatusBadRequest, "ID inválido")
+		AbortWithProblem(c, invalidIDProblem())
 		return
 	}
 	
-	for _, todo := range h.todos {
-		if todo.ID == id {
-			tmpl := templates.GetEditModalTemplate()
-			tmpl.Execute(c.Writer, todo)
-			return
-		}
+	todo, err := h.store.Get(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	c.String(http.StatusNotFound, "Todo no encontrado")
+	data := templates.EditModalData{
+		Todo:      todo,
+		Assignees: h.assigneeOptions(c, todo),
+	}
+	
+	tmpl := templates.GetEditModalTemplate()
+	tmpl.Execute(c.Writer, data)
+}
+
+// GetArchivePage muestra la página de tareas archivadas
+func (h *TodoHandlerTempl) GetArchivePage(c *gin.Context) {
+	query := c.Query("q")
+	user, _ := store.UserFrom(c.Request.Context())
+	data := templates.ArchivePageData{
+		Title:     "Todo List - Archivo",
+		CSRFToken: csrfToken(c),
+		User:      user,
+		Query:     query,
+		Todos:     h.store.ListArchived(c.Request.Context(), query),
+	}
+	
+	tmpl := templates.GetArchiveTemplate()
+	tmpl.Execute(c.Writer, data)
+}
+
+// GetArchivedTodos obtiene los todos archivados filtrados (para HTMX)
+func (h *TodoHandlerTempl) GetArchivedTodos(c *gin.Context) {
+	data := templates.TodoListData{
+		Todos: h.store.ListArchived(c.Request.Context(), c.Query("q")),
+	}
+	
+	tmpl := templates.GetArchiveListTemplate()
+	tmpl.Execute(c.Writer, data)
+}
+
+// ArchiveTodo archiva un todo completado (para HTMX)
+func (h *TodoHandlerTempl) ArchiveTodo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if _, err := h.store.Archive(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	// Redirigir para recargar la página
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// UnarchiveTodo devuelve un todo archivado a la lista (para HTMX)
+func (h *TodoHandlerTempl) UnarchiveTodo(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if _, err := h.store.Unarchive(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	// Devolver la lista de archivados actualizada
+	h.GetArchivedTodos(c)
 }
 
 // CloseModal cierra el modal
@@ -219,42 +273,26 @@ func (h *TodoHandlerTempl) CreateTodoFlexible(c *gin.Context) {
 	var todoReq models.TodoRequest
 	var err error
 	
-	// Intentar bindear como JSON primero
-	err = c.ShouldBindJSON(&todoReq)
+	// JSON estricto, o form data si la petición no es JSON
+	err = bindJSONOrForm(c, &todoReq)
 	if err != nil {
-		// Si falla JSON, intentar como form data
-		err = c.ShouldBind(&todoReq)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "No se pudo procesar los datos. Acepta JSON o Form Data",
-				"json_error": c.ShouldBindJSON(&todoReq).Error(),
-				"form_error": err.Error(),
-			})
-			return
-		}
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
 	}
 	
 	// Validar datos
-	if todoReq.Title == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "El título es requerido",
-		})
+	if errs := validateRequest(c.Request, &todoReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
 		return
 	}
 	
 	// Crear el todo
-	todo := models.Todo{
-		ID:          h.nextID,
-		Title:       todoReq.Title,
-		Description: todoReq.Description,
-		Completed:   todoReq.Completed,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	todo, err := h.store.Create(c.Request.Context(), todoReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
 	}
 	
-	h.todos = append(h.todos, todo)
-	h.nextID++
-	
 	// Devolver el todo creado
 	c.JSON(http.StatusCreated, models.Response{
 		Success: true,
@@ -266,11 +304,12 @@ func (h *TodoHandlerTempl) CreateTodoFlexible(c *gin.Context) {
 // Funciones auxiliares
 
 // calculateStats calcula las estadísticas del todo list
-func (h *TodoHandlerTempl) calculateStats() templates.TodoStats {
-	total := len(h.todos)
+func (h *TodoHandlerTempl) calculateStats(c *gin.Context) templates.TodoStats {
+	todos := h.store.List(c.Request.Context())
+	total := len(todos)
 	completed := 0
 	
-	for _, todo := range h.todos {
+	for _, todo := range todos {
 		if todo.Completed {
 			completed++
 		}
@@ -284,11 +323,12 @@ func (h *TodoHandlerTempl) calculateStats() templates.TodoStats {
 }
 
 // getFilteredTodos obtiene todos filtrados
-func (h *TodoHandlerTempl) getFilteredTodos(filter string) []models.Todo {
+func (h *TodoHandlerTempl) getFilteredTodos(c *gin.Context, filter string) []models.Todo {
+	todos := h.store.List(c.Request.Context())
 	switch filter {
 	case "completed":
 		var completed []models.Todo
-		for _, todo := range h.todos {
+		for _, todo := range todos {
 			if todo.Completed {
 				completed = append(completed, todo)
 			}
@@ -296,13 +336,15 @@ func (h *TodoHandlerTempl) getFilteredTodos(filter string) []models.Todo {
 		return completed
 	case "pending":
 		var pending []models.Todo
-		for _, todo := range h.todos {
+		for _, todo := range todos {
 			if !todo.Completed {
 				pending = append(pending, todo)
 			}
 		}
 		return pending
+	case "mine":
+		return assignedTo(todos, store.UserID(c.Request.Context()))
 	default:
-		return h.todos
+		return todos
 	}
 }
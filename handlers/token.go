@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gorilla/mux"
+)
+
+// TokenHandler maneja los tokens personales de acceso a la API
+type TokenHandler struct {
+	users *store.UserStore
+}
+
+// NewTokenHandler crea una nueva instancia del handler de tokens
+func NewTokenHandler(userStore *store.UserStore) *TokenHandler {
+	return &TokenHandler{
+		users: userStore,
+	}
+}
+
+// GetAllTokens obtiene los tokens del usuario, sin su valor
+func (h *TokenHandler) GetAllTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Tokens obtenidos exitosamente",
+		Data:    h.users.ListTokens(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateToken crea un token personal; la respuesta incluye su valor
+func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var tokenReq models.APITokenRequest
+	if err := decodeJSON(r, &tokenReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &tokenReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	token, err := h.users.CreateToken(r.Context(), tokenReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	w.WriteHeader(http.StatusCreated)
+	response := models.Response{
+		Success: true,
+		Message: "Token creado exitosamente; guárdalo, no se volverá a mostrar",
+		Data:    token,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeToken revoca un token personal
+func (h *TokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.users.RevokeToken(r.Context(), id); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Token revocado exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
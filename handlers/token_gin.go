@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandlerGin maneja los tokens personales de acceso a la API usando Gin
+type TokenHandlerGin struct {
+	users *store.UserStore
+}
+
+// NewTokenHandlerGin crea una nueva instancia del handler de tokens con Gin
+func NewTokenHandlerGin(userStore *store.UserStore) *TokenHandlerGin {
+	return &TokenHandlerGin{
+		users: userStore,
+	}
+}
+
+// GetAllTokens obtiene los tokens del usuario, sin su valor
+func (h *TokenHandlerGin) GetAllTokens(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Tokens obtenidos exitosamente",
+		Data:    h.users.ListTokens(c.Request.Context()),
+	})
+}
+
+// CreateToken crea un token personal; la respuesta incluye su valor
+func (h *TokenHandlerGin) CreateToken(c *gin.Context) {
+	var tokenReq models.APITokenRequest
+	if err := bindJSON(c, &tokenReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &tokenReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	token, err := h.users.CreateToken(c.Request.Context(), tokenReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Token creado exitosamente; guárdalo, no se volverá a mostrar",
+		Data:    token,
+	})
+}
+
+// RevokeToken revoca un token personal
+func (h *TokenHandlerGin) RevokeToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.users.RevokeToken(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Token revocado exitosamente",
+	})
+}
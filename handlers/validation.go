@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-list/models"
+	"todo-list/validation"
+)
+
+// validateRequest valida v con las reglas de sus tags y retorna los errores
+// en el idioma que pide el header Accept-Language
+func validateRequest(r *http.Request, v interface{}) []models.FieldError {
+	return validation.Struct(v, validation.Language(r.Header.Get("Accept-Language")))
+}
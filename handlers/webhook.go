@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler maneja las suscripciones de webhooks y su registro de entregas
+type WebhookHandler struct {
+	store *store.WebhookStore
+}
+
+// NewWebhookHandler crea una nueva instancia del handler de webhooks
+func NewWebhookHandler(webhookStore *store.WebhookStore) *WebhookHandler {
+	return &WebhookHandler{
+		store: webhookStore,
+	}
+}
+
+// GetAllWebhooks obtiene todos los webhooks
+func (h *WebhookHandler) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Webhooks obtenidos exitosamente",
+		Data:    h.store.List(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetWebhookByID obtiene un webhook por ID
+func (h *WebhookHandler) GetWebhookByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	webhook, err := h.store.Get(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Webhook encontrado",
+		Data:    webhook,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateWebhook registra un nuevo webhook; la respuesta incluye el secreto
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	var webhookReq models.WebhookRequest
+	if err := decodeJSON(r, &webhookReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &webhookReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	webhook, err := h.store.Create(r.Context(), webhookReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Webhook creado exitosamente; guarda el secreto, no se volverá a mostrar",
+		Data:    webhook,
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateWebhook actualiza un webhook existente
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	var webhookReq models.WebhookRequest
+	if err := decodeJSON(r, &webhookReq); err != nil {
+		WriteProblem(w, r, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(r, &webhookReq); len(errs) > 0 {
+		WriteProblem(w, r, models.NewValidationProblem(errs))
+		return
+	}
+	
+	webhook, err := h.store.Update(r.Context(), id, webhookReq)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Webhook actualizado exitosamente",
+		Data:    webhook,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteWebhook elimina un webhook y su registro de entregas
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.Delete(r.Context(), id); err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Webhook eliminado exitosamente",
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDeliveries obtiene el registro de entregas de un webhook
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	deliveries, err := h.store.Deliveries(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Entregas obtenidas exitosamente",
+		Data:    deliveries,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDeadLetters obtiene las entregas que agotaron sus reintentos
+func (h *WebhookHandler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Dead letters obtenidas exitosamente",
+		Data:    h.store.DeadLetters(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// RedeliverDelivery vuelve a poner en cola una entrega de dead letters
+func (h *WebhookHandler) RedeliverDelivery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		WriteProblem(w, r, invalidIDProblem())
+		return
+	}
+	
+	delivery, err := h.store.Redeliver(r.Context(), id)
+	if err != nil {
+		WriteProblem(w, r, problemFromError(err))
+		return
+	}
+	
+	response := models.Response{
+		Success: true,
+		Message: "Entrega puesta en cola nuevamente",
+		Data:    delivery,
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandlerGin maneja las suscripciones de webhooks usando Gin
+type WebhookHandlerGin struct {
+	store *store.WebhookStore
+}
+
+// NewWebhookHandlerGin crea una nueva instancia del handler de webhooks con Gin
+func NewWebhookHandlerGin(webhookStore *store.WebhookStore) *WebhookHandlerGin {
+	return &WebhookHandlerGin{
+		store: webhookStore,
+	}
+}
+
+// GetAllWebhooks obtiene todos los webhooks
+func (h *WebhookHandlerGin) GetAllWebhooks(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Webhooks obtenidos exitosamente",
+		Data:    h.store.List(c.Request.Context()),
+	})
+}
+
+// GetWebhookByID obtiene un webhook por ID
+func (h *WebhookHandlerGin) GetWebhookByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	webhook, err := h.store.Get(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Webhook encontrado",
+		Data:    webhook,
+	})
+}
+
+// CreateWebhook registra un nuevo webhook; la respuesta incluye el secreto
+func (h *WebhookHandlerGin) CreateWebhook(c *gin.Context) {
+	var webhookReq models.WebhookRequest
+	if err := bindJSON(c, &webhookReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &webhookReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	webhook, err := h.store.Create(c.Request.Context(), webhookReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusCreated, models.Response{
+		Success: true,
+		Message: "Webhook creado exitosamente; guarda el secreto, no se volverá a mostrar",
+		Data:    webhook,
+	})
+}
+
+// UpdateWebhook actualiza un webhook existente
+func (h *WebhookHandlerGin) UpdateWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	var webhookReq models.WebhookRequest
+	if err := bindJSON(c, &webhookReq); err != nil {
+		AbortWithProblem(c, invalidBodyProblem(err))
+		return
+	}
+	
+	if errs := validateRequest(c.Request, &webhookReq); len(errs) > 0 {
+		AbortWithProblem(c, models.NewValidationProblem(errs))
+		return
+	}
+	
+	webhook, err := h.store.Update(c.Request.Context(), id, webhookReq)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Webhook actualizado exitosamente",
+		Data:    webhook,
+	})
+}
+
+// DeleteWebhook elimina un webhook y su registro de entregas
+func (h *WebhookHandlerGin) DeleteWebhook(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	if err := h.store.Delete(c.Request.Context(), id); err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Webhook eliminado exitosamente",
+	})
+}
+
+// GetDeliveries obtiene el registro de entregas de un webhook
+func (h *WebhookHandlerGin) GetDeliveries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	deliveries, err := h.store.Deliveries(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Entregas obtenidas exitosamente",
+		Data:    deliveries,
+	})
+}
+
+// GetDeadLetters obtiene las entregas que agotaron sus reintentos
+func (h *WebhookHandlerGin) GetDeadLetters(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Dead letters obtenidas exitosamente",
+		Data:    h.store.DeadLetters(c.Request.Context()),
+	})
+}
+
+// RedeliverDelivery vuelve a poner en cola una entrega de dead letters
+func (h *WebhookHandlerGin) RedeliverDelivery(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		AbortWithProblem(c, invalidIDProblem())
+		return
+	}
+	
+	delivery, err := h.store.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		AbortWithProblem(c, problemFromError(err))
+		return
+	}
+	
+	c.JSON(http.StatusAccepted, models.Response{
+		Success: true,
+		Message: "Entrega puesta en cola nuevamente",
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo-list/models"
+)
+
+// GetWorkspace obtiene el workspace de la petición y cuántos todos usa de su límite
+func (h *TodoHandler) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	
+	response := models.Response{
+		Success: true,
+		Message: "Workspace obtenido exitosamente",
+		Data:    h.store.Workspace(r.Context()),
+	}
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkspace obtiene el workspace de la petición y cuántos todos usa de su límite
+func (h *TodoHandlerGin) GetWorkspace(c *gin.Context) {
+	c.JSON(http.StatusOK, models.Response{
+		Success: true,
+		Message: "Workspace obtenido exitosamente",
+		Data:    h.store.Workspace(c.Request.Context()),
+	})
+}
@@ -0,0 +1,135 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HeaderKey es el header con el que el cliente identifica una petición reintentable
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed se agrega a las respuestas repetidas desde el caché
+const HeaderReplayed = "Idempotent-Replayed"
+
+// MaxKeyLength es el largo máximo permitido para una clave
+const MaxKeyLength = 255
+
+// State indica qué hacer con una petición que trae clave de idempotencia
+type State int
+
+const (
+	// StateNew indica que la clave no se había visto y la petición debe ejecutarse
+	StateNew State = iota
+	// StateReplay indica que hay una respuesta guardada que debe repetirse
+	StateReplay
+	// StateMismatch indica que la clave se reutilizó con un cuerpo distinto
+	StateMismatch
+	// StateInFlight indica que otra petición con la misma clave aún se está procesando
+	StateInFlight
+)
+
+// Record representa la respuesta guardada para una clave
+type Record struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// entry representa una clave conocida por el caché
+type entry struct {
+	fingerprint string
+	record      *Record
+	expiresAt   time.Time
+}
+
+// Cache guarda la primera respuesta de cada clave durante una ventana de tiempo
+type Cache struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewCache crea un caché que recuerda las respuestas durante ttl
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		entries: make(map[string]*entry),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Fingerprint calcula la huella de una petición para detectar reutilización de claves
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin registra el inicio de una petición con clave. Si la clave ya tiene
+// respuesta guardada y la misma huella, retorna StateReplay con el registro
+func (c *Cache) Begin(key, fingerprint string) (State, *Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	if e, ok := c.entries[key]; ok && now.Before(e.expiresAt) {
+		switch {
+		case e.fingerprint != fingerprint:
+			return StateMismatch, nil
+		case e.record == nil:
+			return StateInFlight, nil
+		default:
+			return StateReplay, e.record
+		}
+	}
+
+	c.entries[key] = &entry{
+		fingerprint: fingerprint,
+		expiresAt:   now.Add(c.ttl),
+	}
+	return StateNew, nil
+}
+
+// Complete guarda la respuesta de una petición iniciada con Begin
+func (c *Cache) Complete(key string, record Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.record = &record
+	}
+}
+
+// Abort libera la clave para que el cliente pueda reintentar, por ejemplo
+// cuando la petición falló con un error del servidor
+func (c *Cache) Abort(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// sweep elimina las claves vencidas como máximo una vez por minuto; requiere tener el lock tomado
+func (c *Cache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
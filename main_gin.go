@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"todo-list/config"
 	"todo-list/routes"
 )
 
 func main() {
 	// Configurar rutas con Gin
-	router := routes.SetupRoutesGin()
+	router, grpcServer := routes.SetupRoutesGin()
+	
+	// Iniciar la API gRPC antes que el servidor HTTP: si su puerto está
+	// ocupado el servidor no arranca
+	if err := grpcServer.Start(); err != nil {
+		log.Fatal(err)
+	}
 	
 	// Obtener puerto del entorno o usar 8080 por defecto
 	port := os.Getenv("PORT")
@@ -20,20 +33,75 @@ func main() {
 	// Mostrar información de inicio
 	fmt.Printf("🚀 Todo List API con Gin Framework iniciando en puerto %s\n", port)
 	fmt.Println("📋 Endpoints disponibles:")
+	fmt.Println("  POST   /api/v1/auth/register - Crear una cuenta e iniciar sesión")
+	fmt.Println("  POST   /api/v1/auth/login - Iniciar sesión (POST /api/v1/auth/logout para salir)")
+	fmt.Println("  GET    /api/v1/auth/me  - Usuario de la sesión actual")
+	fmt.Println("  GET    /api/v1/workspace - Workspace actual y su límite de todos (header X-Workspace o subdominio)")
+	fmt.Println("  GET    /api/v1/tokens   - Listar tokens personales (POST para crear)")
+	fmt.Println("  DELETE /api/v1/tokens/{id} - Revocar un token personal")
 	fmt.Println("  GET    /api/v1/todos     - Obtener todos los todos")
 	fmt.Println("  POST   /api/v1/todos     - Crear un nuevo todo")
+	fmt.Println("  POST   /api/v1/todos/batch - Crear/actualizar/completar/eliminar en lote")
 	fmt.Println("  GET    /api/v1/todos/{id} - Obtener un todo por ID")
 	fmt.Println("  PUT    /api/v1/todos/{id} - Actualizar un todo")
+	fmt.Println("  PATCH  /api/v1/todos/{id} - Actualizar parcialmente un todo")
 	fmt.Println("  DELETE /api/v1/todos/{id} - Eliminar un todo")
+	fmt.Println("  GET    /api/v1/todos/{id}/history - Historial de cambios del todo")
+	fmt.Println("  GET    /api/v1/lists     - Listas compartidas (POST para crear)")
+	fmt.Println("  GET    /api/v1/lists/{id} - Obtener lista con miembros (PUT/DELETE)")
+	fmt.Println("  POST   /api/v1/lists/{id}/invitations - Invitar a un email a la lista")
+	fmt.Println("  PUT    /api/v1/lists/{id}/members/{userID} - Cambiar rol (DELETE para quitar)")
+	fmt.Println("  GET    /api/v1/invitations - Invitaciones pendientes")
+	fmt.Println("  POST   /api/v1/invitations/{id}/accept - Aceptar una invitación")
+	fmt.Println("  GET    /api/v1/templates - Listar plantillas (POST para crear)")
+	fmt.Println("  GET    /api/v1/templates/{id} - Obtener plantilla (PUT/DELETE)")
+	fmt.Println("  POST   /api/v1/templates/{id}/instantiate - Crear todos desde una plantilla")
+	fmt.Println("  GET    /api/v1/events    - Cambios en tiempo real (Server-Sent Events)")
+	fmt.Println("  GET    /api/v1/ws        - Colaboración: presencia y bloqueos de edición (WebSocket)")
+	fmt.Println("  GET    /api/v1/webhooks  - Listar webhooks (POST para registrar)")
+	fmt.Println("  GET    /api/v1/webhooks/{id} - Obtener webhook (PUT/DELETE)")
+	fmt.Println("  GET    /api/v1/webhooks/{id}/deliveries - Registro de entregas de un webhook")
+	fmt.Println("  GET    /api/v1/webhooks/dead-letters - Entregas que agotaron sus reintentos")
+	fmt.Println("  POST   /api/v1/webhooks/deliveries/{id}/redeliver - Reintentar una dead letter")
+	fmt.Println("  POST   /api/v1/graphql   - Consultas y mutaciones GraphQL (suscripciones por WebSocket)")
+	fmt.Println("  GET    /api/v1/graphql/schema - Schema GraphQL en SDL")
+	fmt.Println("  GET    /api/v1/stats     - Estimaciones, throughput y burndown")
+	fmt.Println("  GET    /api/v1/archive?q= - Buscar todos archivados")
+	fmt.Println("  POST   /api/v1/todos/{id}/archive   - Archivar un todo completado")
+	fmt.Println("  POST   /api/v1/todos/{id}/unarchive - Desarchivar un todo")
 	fmt.Println("  GET    /api/v1/health    - Health check")
+	fmt.Println("  GET    /api/v1/openapi.json - Especificación OpenAPI 3.1")
+	fmt.Println("  GET    /api/v1/docs      - Documentación interactiva de la API")
 	fmt.Println("")
 	fmt.Println("🌐 Página web disponible en:")
 	fmt.Printf("  http://localhost:%s\n", port)
 	fmt.Println("")
+	if grpcPort := config.Load().GRPCPort; grpcPort > 0 {
+		fmt.Printf("🔌 API gRPC (todo.v1.TodoService) en: localhost:%d\n", grpcPort)
+		fmt.Println("")
+	}
 	fmt.Println("📁 Archivos estáticos servidos desde: ./web/")
 	fmt.Println("⚡ Framework: Gin v1.9.1")
 	fmt.Printf("🌐 Servidor corriendo en: http://localhost:%s\n", port)
 	
 	// Iniciar servidor con Gin
-	log.Fatal(router.Run(":" + port))
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	stopped := make(chan struct{})
+	go func() {
+		// Con SIGINT o SIGTERM se detienen la API gRPC y el servidor HTTP,
+		// dejando terminar las peticiones en curso
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		<-ctx.Done()
+		grpcServer.Stop()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+		close(stopped)
+	}()
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		grpcServer.Stop()
+		log.Fatal(err)
+	}
+	<-stopped
 }
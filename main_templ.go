@@ -20,6 +20,10 @@ func main() {
 	// Mostrar información de inicio
 	fmt.Printf("🚀 Todo List con Templ + HTMX iniciando en puerto %s\n", port)
 	fmt.Println("📋 Endpoints disponibles:")
+	fmt.Println("  GET    /login            - Iniciar sesión (POST para enviar el formulario)")
+	fmt.Println("  GET    /register         - Crear una cuenta (POST para enviar el formulario)")
+	fmt.Println("  POST   /logout           - Cerrar la sesión")
+	fmt.Println("  GET    /auth/oidc/login  - Inicio de sesión único con OIDC (si OIDC_ISSUER está definido)")
 	fmt.Println("  GET    /                 - Página principal del Todo List")
 	fmt.Println("  GET    /api/todos        - Obtener todos los todos (HTMX)")
 	fmt.Println("  POST   /api/todos        - Crear un nuevo todo (HTMX)")
@@ -28,7 +32,16 @@ func main() {
 	fmt.Println("  PUT    /api/todos/{id}    - Actualizar un todo (HTMX)")
 	fmt.Println("  DELETE /api/todos/{id}   - Eliminar un todo (HTMX)")
 	fmt.Println("  GET    /api/todos/{id}/edit - Modal de edición (HTMX)")
+	fmt.Println("  GET    /archive          - Página de tareas archivadas")
+	fmt.Println("  GET    /api/archive?q=   - Buscar archivados (HTMX)")
+	fmt.Println("  POST   /api/todos/{id}/archive   - Archivar un todo (HTMX)")
+	fmt.Println("  POST   /api/todos/{id}/unarchive - Desarchivar un todo (HTMX)")
 	fmt.Println("  GET    /api/close-modal  - Cerrar modal (HTMX)")
+	fmt.Println("  GET    /lists            - Página de listas compartidas")
+	fmt.Println("  GET    /api/templates    - Listar plantillas (POST para crear)")
+	fmt.Println("  POST   /api/templates/{id}/instantiate - Crear desde plantilla (HTMX)")
+	fmt.Println("  GET    /api/stats        - Bloque de estadísticas (HTMX)")
+	fmt.Println("  GET    /api/events       - Cambios en tiempo real (SSE, hx-ext sse)")
 	fmt.Println("  GET    /api/health       - Health check")
 	fmt.Println("")
 	fmt.Println("🌐 Página web disponible en:")
@@ -0,0 +1,40 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+)
+
+// renderer convierte CommonMark a HTML sin permitir HTML crudo en la entrada
+var renderer = goldmark.New()
+
+// policy limpia el HTML generado: sin scripts, estilos ni atributos de eventos,
+// y con enlaces restringidos a esquemas seguros
+var policy = newPolicy()
+
+// newPolicy crea la política de sanitización para las descripciones
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render convierte una descripción en CommonMark a HTML seguro para mostrar
+func Render(source string) string {
+	if source == "" {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(source), &buf); err != nil {
+		// Si falla el parseo, mostrar el texto escapado tal cual
+		return policy.Sanitize("<p>" + bluemonday.StrictPolicy().Sanitize(source) + "</p>")
+	}
+	return policy.Sanitize(buf.String())
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de mensaje que el cliente envía por el canal de colaboración
+const (
+	// CollabView indica qué todo está mirando el cliente (todo_id 0 para ninguno)
+	CollabView = "view"
+	// CollabEdit pide (o renueva) el bloqueo de edición de un todo
+	CollabEdit = "edit"
+	// CollabRelease libera el bloqueo de edición de un todo
+	CollabRelease = "release"
+	// CollabPing permite al cliente comprobar que la conexión sigue viva
+	CollabPing = "ping"
+)
+
+// Tipos de mensaje que el servidor envía por el canal de colaboración
+const (
+	CollabWelcome    = "welcome"
+	CollabChange     = "change"
+	CollabPresence   = "presence"
+	CollabLock       = "lock"
+	CollabUnlock     = "unlock"
+	CollabLockDenied = "lock_denied"
+	CollabPong       = "pong"
+	CollabError      = "error"
+)
+
+// Estados de presencia de un participante
+const (
+	PresenceOnline  = "online"
+	PresenceViewing = "viewing"
+	PresenceEditing = "editing"
+	PresenceLeft    = "left"
+)
+
+// Participant representa a una persona conectada y qué está haciendo
+type Participant struct {
+	ClientID string `json:"client_id"`
+	Name     string `json:"name"`
+	TodoID   int    `json:"todo_id,omitempty"`
+	State    string `json:"state"`
+}
+
+// EditLock representa un bloqueo de edición blando: avisa a los demás que
+// alguien está editando, pero la API no rechaza sus escrituras
+type EditLock struct {
+	TodoID    int       `json:"todo_id"`
+	ClientID  string    `json:"client_id"`
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CollabMessage es el sobre de todos los mensajes del canal de colaboración;
+// los campos usados dependen de Type
+type CollabMessage struct {
+	Type         string        `json:"type"`
+	TodoID       int           `json:"todo_id,omitempty"`
+	ClientID     string        `json:"client_id,omitempty"`
+	Participant  *Participant  `json:"participant,omitempty"`
+	Participants []Participant `json:"participants,omitempty"`
+	Lock         *EditLock     `json:"lock,omitempty"`
+	Locks        []EditLock    `json:"locks,omitempty"`
+	Event        *ChangeEvent  `json:"event,omitempty"`
+	Message      string        `json:"message,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// Tipos de cambio publicados en el feed de eventos. Una actualización que
+// marca el todo como completado se publica como EventTodoCompleted
+const (
+	EventTodoCreated    = "todo.created"
+	EventTodoUpdated    = "todo.updated"
+	EventTodoCompleted  = "todo.completed"
+	EventTodoDeleted    = "todo.deleted"
+	EventTodoArchived   = "todo.archived"
+	EventTodoUnarchived = "todo.unarchived"
+)
+
+// ChangeEvent representa un cambio sobre un todo publicado en GET /api/v1/events.
+// Todo trae el estado después del cambio y es nil cuando el todo se eliminó.
+// Audience son los usuarios que pueden ver el todo: su dueño o, si está en
+// una lista compartida, los miembros de la lista
+type ChangeEvent struct {
+	ID          int64     `json:"id"`
+	Type        string    `json:"type"`
+	TodoID      int       `json:"todo_id"`
+	OwnerID     int       `json:"-"`
+	WorkspaceID int       `json:"-"`
+	ListID      int       `json:"list_id,omitempty"`
+	Audience    []int     `json:"-"`
+	Todo        *Todo     `json:"todo,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// VisibleTo indica si el cambio se le entrega al usuario
+func (e ChangeEvent) VisibleTo(user int) bool {
+	for _, id := range e.Audience {
+		if id == user {
+			return true
+		}
+	}
+	return false
+}
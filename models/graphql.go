@@ -0,0 +1,24 @@
+package models
+
+// GraphQLRequest representa una operación enviada a /graphql, por POST o en
+// el payload de un mensaje subscribe del WebSocket
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse representa la respuesta de /graphql. No usa el sobre
+// Response: sigue el formato de la especificación de GraphQL
+type GraphQLResponse struct {
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Errors []GraphQLError         `json:"errors,omitempty"`
+}
+
+// GraphQLError representa un error de GraphQL. En extensions.code viaja el
+// mismo código estable que usan los errores problem+json de la API REST
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+)
+
+// Roles de un miembro en una lista compartida, de menor a mayor permiso
+const (
+	RoleViewer = "viewer" // ve los todos de la lista
+	RoleEditor = "editor" // además crea, modifica y elimina todos
+	RoleOwner  = "owner"  // además invita, cambia roles y administra la lista
+)
+
+// RoleRank ordena los roles por permiso; cero si el rol no existe
+func RoleRank(role string) int {
+	switch role {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// List representa una lista de tareas compartida. Role es el rol de quien la
+// consulta; Invitations solo se muestran a los owners
+type List struct {
+	ID          int          `json:"id"`
+	WorkspaceID int          `json:"-"`
+	Name        string       `json:"name"`
+	Role        string       `json:"role"`
+	Members     []ListMember `json:"members"`
+	Invitations []Invitation `json:"invitations,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ListMember representa a un usuario con acceso a una lista
+type ListMember struct {
+	UserID   int       `json:"user_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Invitation representa una invitación pendiente a una lista, dirigida a un
+// email. Token solo viaja en la respuesta de Invite: es el código que el
+// invitado presenta para aceptarla
+type Invitation struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"-"`
+	ListID      int       `json:"list_id"`
+	ListName    string    `json:"list_name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	InvitedBy   string    `json:"invited_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	Token       string    `json:"token,omitempty"`
+	TokenHash   string    `json:"-"`
+}
+
+// ListRequest representa la estructura para crear o renombrar una lista
+type ListRequest struct {
+	Name string `json:"name" validate:"required,max=100" mod:"trim"`
+}
+
+// InvitationRequest representa la estructura para invitar a alguien a una lista
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email,max=254" mod:"trim"`
+	Role  string `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+// InvitationAcceptRequest representa la estructura para aceptar una
+// invitación con su código
+type InvitationAcceptRequest struct {
+	Token string `json:"token" validate:"required,max=100" mod:"trim"`
+}
+
+// MemberRequest representa la estructura para cambiar el rol de un miembro
+type MemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor owner"`
+}
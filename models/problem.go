@@ -0,0 +1,105 @@
+package models
+
+import (
+	"net/http"
+)
+
+// ProblemContentType es el media type de los errores (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBase es el prefijo del URI que identifica cada tipo de error
+const problemTypeBase = "urn:todo-list:problem:"
+
+// Códigos de error estables que los clientes pueden usar para decidir qué hacer
+const (
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidBody          = "invalid_body"
+	CodeUnknownField         = "unknown_field"
+	CodeBodyTooLarge         = "body_too_large"
+	CodeInvalidQuery         = "invalid_query"
+	CodeValidationFailed     = "validation_failed"
+	CodeTodoNotFound         = "todo_not_found"
+	CodeTemplateNotFound     = "template_not_found"
+	CodeRouteNotFound        = "route_not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeTodoNotCompleted     = "todo_not_completed"
+	CodeVersionMismatch      = "version_mismatch"
+	CodeIfMatchRequired      = "if_match_required"
+	CodeMissingValues        = "missing_placeholder_values"
+	CodeInvalidValues        = "invalid_placeholder_values"
+	CodeBatchFailed          = "batch_failed"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeIdempotencyInFlight  = "idempotency_request_in_flight"
+	CodeUpgradeFailed        = "websocket_upgrade_failed"
+	CodeWebhookNotFound      = "webhook_not_found"
+	CodeDeliveryNotFound     = "webhook_delivery_not_found"
+	CodeDeliveryNotDead      = "webhook_delivery_not_dead"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeEmailTaken           = "email_taken"
+	CodeTokenNotFound        = "token_not_found"
+	CodeInsufficientScope    = "insufficient_scope"
+	CodeListNotFound         = "list_not_found"
+	CodeForbidden            = "forbidden"
+	CodeInvitationNotFound   = "invitation_not_found"
+	CodeMemberNotFound       = "member_not_found"
+	CodeAlreadyMember        = "already_member"
+	CodeLastOwner            = "last_owner"
+	CodeWorkspaceNotFound    = "workspace_not_found"
+	CodeQuotaExceeded        = "quota_exceeded"
+	CodeInvalidAssignee      = "invalid_assignee"
+	CodeOriginNotAllowed     = "origin_not_allowed"
+	CodeCSRFFailed           = "csrf_failed"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+)
+
+// Códigos de error de un campo en particular
+const (
+	FieldRequired   = "required"
+	FieldMin        = "min"
+	FieldMax        = "max"
+	FieldTooLong    = "too_long"
+	FieldOutOfRange = "out_of_range"
+	FieldEnum       = "enum"
+	FieldInvalid    = "invalid"
+	FieldUnknown    = "unknown"
+	FieldType       = "type"
+)
+
+// FieldError describe por qué un campo de la petición no es válido
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem representa un error de la API en formato problem+json (RFC 7807)
+type Problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Code     string        `json:"code"`
+	Errors   []FieldError  `json:"errors,omitempty"`
+	Results  []BatchResult `json:"results,omitempty"`
+}
+
+// NewProblem crea un problema con el código estable y el detalle para el usuario
+func NewProblem(status int, code, detail string) Problem {
+	return Problem{
+		Type:   problemTypeBase + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// NewValidationProblem crea un problema de validación con los errores por campo
+func NewValidationProblem(errs []FieldError) Problem {
+	problem := NewProblem(http.StatusBadRequest, CodeValidationFailed, errs[0].Message)
+	problem.Errors = errs
+	return problem
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// Acciones registradas en la auditoría de todos
+const (
+	AuditCreated    = "created"
+	AuditUpdated    = "updated"
+	AuditCompleted  = "completed"
+	AuditReopened   = "reopened"
+	AuditArchived   = "archived"
+	AuditUnarchived = "unarchived"
+	AuditDeleted    = "deleted"
+	AuditReassigned = "reassigned"
+)
+
+// AuditEntry representa un cambio registrado sobre un todo. AssigneeID es el
+// responsable del todo después del cambio; en las reasignaciones
+// PreviousAssigneeID es el anterior (cero es sin responsable)
+type AuditEntry struct {
+	TodoID             int       `json:"todo_id"`
+	OwnerID            int       `json:"-"`
+	WorkspaceID        int       `json:"-"`
+	Action             string    `json:"action"`
+	Estimate           int       `json:"estimate"`
+	AssigneeID         int       `json:"assignee_id,omitempty"`
+	PreviousAssigneeID int       `json:"previous_assignee_id,omitempty"`
+	At                 time.Time `json:"at"`
+}
+
+// WeeklyThroughput representa las tareas completadas en una semana
+type WeeklyThroughput struct {
+	WeekStart time.Time `json:"week_start"`
+	Completed int       `json:"completed"`
+	Points    int       `json:"points"`
+}
+
+// BurndownPoint representa el trabajo completado y restante al cierre de un día
+type BurndownPoint struct {
+	Date      time.Time `json:"date"`
+	Remaining int       `json:"remaining"`
+	Completed int       `json:"completed"`
+}
+
+// Stats representa las estadísticas de estimación y avance
+type Stats struct {
+	Total             int                `json:"total"`
+	Pending           int                `json:"pending"`
+	Completed         int                `json:"completed"`
+	EstimateTotal     int                `json:"estimate_total"`
+	EstimateCompleted int                `json:"estimate_completed"`
+	EstimateRemaining int                `json:"estimate_remaining"`
+	Throughput        []WeeklyThroughput `json:"throughput"`
+	Burndown          []BurndownPoint    `json:"burndown"`
+}
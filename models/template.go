@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// TodoTemplate representa una plantilla reutilizable para crear un conjunto de todos
+type TodoTemplate struct {
+	ID          int            `json:"id"`
+	OwnerID     int            `json:"-"`
+	WorkspaceID int            `json:"-"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Items       []TemplateItem `json:"items"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TemplateItem representa un todo dentro de una plantilla. Los textos pueden
+// contener marcadores como {{name}} que se reemplazan al instanciar
+type TemplateItem struct {
+	Title         string   `json:"title" validate:"required,max=200" mod:"trim"`
+	Description   string   `json:"description" validate:"max=10000" mod:"trim"`
+	Estimate      int      `json:"estimate" validate:"min=0"`
+	Checklist     []string `json:"checklist" validate:"dive,required,max=500" mod:"trim"`
+	Tags          []string `json:"tags" validate:"dive,required,max=50" mod:"trim"`
+	DueOffsetDays *int     `json:"due_offset_days,omitempty" validate:"omitempty,min=0"`
+}
+
+// TodoTemplateRequest representa la estructura para crear/actualizar una plantilla
+type TodoTemplateRequest struct {
+	Name        string         `json:"name" validate:"required,max=100" mod:"trim"`
+	Description string         `json:"description" validate:"max=1000" mod:"trim"`
+	Items       []TemplateItem `json:"items" validate:"min=1,dive"`
+}
+
+// InstantiateRequest representa los valores para instanciar una plantilla
+type InstantiateRequest struct {
+	Values    map[string]string `json:"values" validate:"dive,max=500"`
+	StartDate *time.Time        `json:"start_date"`
+}
@@ -4,21 +4,54 @@ import (
 	"time"
 )
 
-// Todo representa una tarea en la lista
+// Todo representa una tarea en la lista de un usuario. Description se guarda
+// en Markdown (CommonMark) y DescriptionHTML contiene su versión renderizada y
+// sanitizada
 type Todo struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int             `json:"id"`
+	OwnerID         int             `json:"owner_id"`
+	WorkspaceID     int             `json:"-"`
+	ListID          int             `json:"list_id,omitempty"`
+	AssigneeID      int             `json:"assignee_id,omitempty"`
+	Watchers        []int           `json:"watchers"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	DescriptionHTML string          `json:"description_html"`
+	Completed       bool            `json:"completed"`
+	Estimate        int             `json:"estimate"`
+	Tags            []string        `json:"tags"`
+	Checklist       []ChecklistItem `json:"checklist"`
+	DueDate         *time.Time      `json:"due_date,omitempty"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	Archived        bool            `json:"archived"`
+	ArchivedAt      *time.Time      `json:"archived_at,omitempty"`
+	Version         int             `json:"version"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
 }
 
-// TodoRequest representa la estructura para crear/actualizar un todo
+// ChecklistItem representa un paso dentro de un todo
+type ChecklistItem struct {
+	Text string `json:"text" validate:"required,max=500" mod:"trim"`
+	Done bool   `json:"done"`
+}
+
+// TodoRequest representa la estructura para crear/actualizar un todo.
+// Al actualizar, Tags, Checklist, DueDate, ListID, AssigneeID y Watchers en
+// nil conservan el valor actual; ListID en cero deja el todo en la lista
+// personal y AssigneeID en cero lo deja sin responsable.
+// Las reglas de los tags validate y mod se aplican con el paquete validation
 type TodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Completed   bool   `json:"completed"`
+	Title       string          `json:"title" validate:"required,max=200" mod:"trim"`
+	Description string          `json:"description" validate:"max=10000" mod:"trim"`
+	Completed   bool            `json:"completed"`
+	Estimate    int             `json:"estimate" validate:"min=0"`
+	Tags        []string        `json:"tags" validate:"dive,required,max=50" mod:"trim"`
+	Checklist   []ChecklistItem `json:"checklist" validate:"dive"`
+	DueDate     *time.Time      `json:"due_date"`
+	ListID      *int            `json:"list_id" validate:"omitnil,min=0"`
+	AssigneeID  *int            `json:"assignee_id" validate:"omitnil,min=0"`
+	Watchers    []int           `json:"watchers" validate:"max=50,dive,min=1"`
 }
 
 // Response representa la respuesta estándar de la API
@@ -27,3 +60,49 @@ type Response struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
+
+// TodoPatch representa una actualización parcial; los campos en nil no se modifican
+type TodoPatch struct {
+	Title       *string          `json:"title" validate:"omitnil,notblank,max=200" mod:"trim"`
+	Description *string          `json:"description" validate:"omitnil,max=10000" mod:"trim"`
+	Completed   *bool            `json:"completed"`
+	Estimate    *int             `json:"estimate" validate:"omitnil,min=0"`
+	Tags        *[]string        `json:"tags" validate:"omitnil,dive,required,max=50" mod:"trim"`
+	Checklist   *[]ChecklistItem `json:"checklist" validate:"omitnil,dive"`
+	DueDate     *time.Time       `json:"due_date"`
+	ListID      *int             `json:"list_id" validate:"omitnil,min=0"`
+	AssigneeID  *int             `json:"assignee_id" validate:"omitnil,min=0"`
+	Watchers    *[]int           `json:"watchers" validate:"omitnil,max=50,dive,min=1"`
+}
+
+// Operaciones permitidas en un lote
+const (
+	BatchCreate   = "create"
+	BatchUpdate   = "update"
+	BatchDelete   = "delete"
+	BatchComplete = "complete"
+)
+
+// BatchOperation representa una operación dentro de un lote
+type BatchOperation struct {
+	Op   string       `json:"op" validate:"required,oneof=create update delete complete"`
+	ID   int          `json:"id,omitempty"`
+	Todo *TodoRequest `json:"todo,omitempty" validate:"required_if=Op create,required_if=Op update"`
+}
+
+// BatchRequest representa la estructura para ejecutar varias operaciones a la
+// vez; el máximo coincide con store.MaxBatchOperations
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"min=1,max=100,dive"`
+}
+
+// BatchResult representa el resultado de una operación del lote
+type BatchResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	ID      int    `json:"id,omitempty"`
+	Status  int    `json:"status"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Data    *Todo  `json:"data,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// Scopes que puede tener un token personal
+const (
+	// ScopeTodosRead permite las peticiones de lectura (GET)
+	ScopeTodosRead = "todos:read"
+	// ScopeTodosWrite permite las peticiones que crean, cambian o eliminan datos
+	ScopeTodosWrite = "todos:write"
+)
+
+// APIToken representa un token personal para usar la API desde scripts. El
+// valor del token solo se muestra al crearlo; el store guarda su hash
+type APIToken struct {
+	ID          int        `json:"id"`
+	OwnerID     int        `json:"-"`
+	WorkspaceID int        `json:"-"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	Token       string     `json:"token,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// HasScope indica si el token tiene el scope indicado
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenRequest representa la estructura para crear un token personal. Sin
+// scopes el token puede leer y escribir; sin expires_in_days no vence
+type APITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100" mod:"trim"`
+	Scopes        []string `json:"scopes,omitempty" validate:"omitempty,dive,oneof=todos:read todos:write"`
+	ExpiresInDays *int     `json:"expires_in_days,omitempty" validate:"omitnil,min=1,max=365"`
+}
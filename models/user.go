@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// User representa una cuenta. La contraseña solo se guarda como hash en el store
+type User struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"-"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RegisterRequest representa la estructura para crear una cuenta. bcrypt solo
+// usa los primeros 72 bytes de la contraseña, así que no se aceptan más largas
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,max=254" mod:"trim"`
+	Name     string `json:"name" validate:"required,max=100" mod:"trim"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// LoginRequest representa la estructura para iniciar sesión
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,max=254" mod:"trim"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// Identity representa una cuenta de un proveedor externo (OIDC), identificada
+// por su issuer y su subject
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
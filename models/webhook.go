@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+)
+
+// Estados de una entrega de webhook
+const (
+	// DeliveryPending espera su próximo intento (el primero o un reintento)
+	DeliveryPending = "pending"
+	// DeliverySending tiene un intento en curso
+	DeliverySending = "sending"
+	// DeliverySucceeded recibió una respuesta 2xx
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead agotó los reintentos y quedó en la lista de dead letters
+	DeliveryDead = "dead"
+)
+
+// Webhook representa una suscripción a los cambios de los todos. El secreto
+// solo se muestra al crear la suscripción
+type Webhook struct {
+	ID          int       `json:"id"`
+	OwnerID     int       `json:"-"`
+	WorkspaceID int       `json:"-"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookRequest representa la estructura para crear/actualizar un webhook.
+// Si no se envía secret se genera uno al crear y se conserva el actual al actualizar
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2000" mod:"trim"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=200"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// WebhookAttempt registra un intento de entrega
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// WebhookDelivery representa el envío de un cambio a un webhook junto con el
+// registro de sus intentos. Attempts cuenta los intentos desde la última
+// reentrega; Log los guarda todos
+type WebhookDelivery struct {
+	ID            int              `json:"id"`
+	WebhookID     int              `json:"webhook_id"`
+	Event         string           `json:"event"`
+	EventID       int64            `json:"event_id"`
+	TodoID        int              `json:"todo_id"`
+	Status        string           `json:"status"`
+	Attempts      int              `json:"attempts"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	Log           []WebhookAttempt `json:"log"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	Payload       []byte           `json:"-"`
+}
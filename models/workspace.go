@@ -0,0 +1,11 @@
+package models
+
+// Workspace representa a un equipo cliente (tenant). Cada workspace tiene sus
+// propias cuentas y datos, que no se ven desde los demás. MaxTodos es el
+// límite de todos del workspace, contando los archivados; cero es sin límite
+type Workspace struct {
+	ID       int    `json:"id"`
+	Slug     string `json:"slug"`
+	MaxTodos int    `json:"max_todos"`
+	Todos    int    `json:"todos"`
+}
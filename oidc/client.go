@@ -0,0 +1,288 @@
+// Package oidc implementa el inicio de sesión único con un proveedor OpenID
+// Connect: el flujo authorization code con PKCE para la interfaz Templ y la
+// validación de access tokens JWT para la API.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"todo-list/models"
+	"todo-list/store"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrLoginExpired se retorna cuando el callback no corresponde a un inicio de
+// sesión pendiente: el state no existe, ya se usó o venció
+var ErrLoginExpired = errors.New("el inicio de sesión venció, vuelve a intentarlo")
+
+// ErrNonceMismatch se retorna cuando el ID token no trae el nonce del inicio de sesión
+var ErrNonceMismatch = errors.New("el nonce del ID token no coincide")
+
+// ErrNoAudience se retorna al validar un access token cuando no hay una
+// audiencia propia de la API: sin ella los ID tokens de la app, que traen el
+// client ID como aud, servirían como access tokens
+var ErrNoAudience = errors.New("OIDC_AUDIENCE no está configurado o es el client ID; los access tokens JWT están desactivados")
+
+// flowTTL es cuánto tiempo tiene el usuario para volver del proveedor
+const flowTTL = 10 * time.Minute
+
+// Config es la configuración del proveedor OIDC
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// Audience es el aud que deben traer los access tokens de la API; debe ser
+	// distinto del client ID. Vacío desactiva los access tokens JWT
+	Audience string
+}
+
+// AccessTokens indica si la configuración admite access tokens JWT: hace
+// falta una audiencia distinta del client ID
+func (cfg Config) AccessTokens() bool {
+	return cfg.Audience != "" && cfg.Audience != cfg.ClientID
+}
+
+// flow guarda lo necesario para terminar un inicio de sesión pendiente
+type flow struct {
+	nonce     string
+	verifier  string
+	expiresAt time.Time
+}
+
+// Client habla con el proveedor OIDC y vincula sus identidades con las
+// cuentas del UserStore. Es seguro para uso concurrente
+type Client struct {
+	cfg        Config
+	users      *store.UserStore
+	httpClient *http.Client
+
+	discoverMu sync.Mutex
+	discovered *discovery
+
+	mu    sync.Mutex
+	flows map[string]flow
+	now   func() time.Time
+}
+
+// discovery guarda la configuración del proveedor y los verificadores de tokens
+type discovery struct {
+	provider *gooidc.Provider
+	idTokens *gooidc.IDTokenVerifier
+	access   *gooidc.IDTokenVerifier
+}
+
+// NewClient crea el cliente OIDC; retorna nil si no hay issuer configurado.
+// El documento de discovery se pide la primera vez que hace falta, así el
+// servidor arranca aunque el proveedor no esté disponible
+func NewClient(cfg Config, users *store.UserStore) *Client {
+	if cfg.Issuer == "" {
+		return nil
+	}
+	return &Client{
+		cfg:        cfg,
+		users:      users,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		flows:      make(map[string]flow),
+		now:        time.Now,
+	}
+}
+
+// Enabled indica si el inicio de sesión único está configurado
+func (c *Client) Enabled() bool {
+	return c != nil
+}
+
+// StartLogin inicia el flujo authorization code con PKCE. Retorna la URL del
+// proveedor a la que hay que redirigir y el state, que el llamador debe
+// guardar en una cookie para comprobarlo en el callback
+func (c *Client) StartLogin(ctx context.Context) (string, string, error) {
+	d, err := c.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	c.mu.Lock()
+	now := c.now()
+	for key, pending := range c.flows {
+		if !now.Before(pending.expiresAt) {
+			delete(c.flows, key)
+		}
+	}
+	c.flows[state] = flow{nonce: nonce, verifier: verifier, expiresAt: now.Add(flowTTL)}
+	c.mu.Unlock()
+
+	url := c.oauth2Config(d.provider).AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	return url, state, nil
+}
+
+// FinishLogin termina el flujo: canjea el code por tokens con el verificador
+// PKCE, valida el ID token y su nonce, y obtiene la cuenta vinculada
+func (c *Client) FinishLogin(ctx context.Context, state, code string) (models.User, error) {
+	c.mu.Lock()
+	pending, ok := c.flows[state]
+	delete(c.flows, state)
+	c.mu.Unlock()
+	if !ok || !c.now().Before(pending.expiresAt) {
+		return models.User{}, ErrLoginExpired
+	}
+
+	d, err := c.discover(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	ctx = gooidc.ClientContext(ctx, c.httpClient)
+	token, err := c.oauth2Config(d.provider).Exchange(ctx, code, oauth2.VerifierOption(pending.verifier))
+	if err != nil {
+		return models.User{}, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.User{}, errors.New("el proveedor no envió un id_token")
+	}
+
+	idToken, err := d.idTokens.Verify(ctx, rawIDToken)
+	if err != nil {
+		return models.User{}, err
+	}
+	if idToken.Nonce != pending.nonce {
+		return models.User{}, ErrNonceMismatch
+	}
+
+	var claims tokenClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return models.User{}, err
+	}
+	return c.users.ExternalUser(ctx, claims.identity(idToken.Issuer, idToken.Subject))
+}
+
+// VerifyAccessToken valida un access token JWT de la API: firma contra las
+// claves del JWKS del proveedor (que go-oidc guarda y vuelve a pedir cuando
+// aparece un kid desconocido), issuer, audiencia y vencimiento. Retorna la
+// cuenta vinculada y un token con los scopes que trae el JWT. Sin una
+// audiencia propia de la API retorna ErrNoAudience
+func (c *Client) VerifyAccessToken(ctx context.Context, raw string) (models.User, models.APIToken, error) {
+	if !c.cfg.AccessTokens() {
+		return models.User{}, models.APIToken{}, ErrNoAudience
+	}
+	d, err := c.discover(ctx)
+	if err != nil {
+		return models.User{}, models.APIToken{}, err
+	}
+	token, err := d.access.Verify(ctx, raw)
+	if err != nil {
+		return models.User{}, models.APIToken{}, err
+	}
+
+	var claims tokenClaims
+	if err := token.Claims(&claims); err != nil {
+		return models.User{}, models.APIToken{}, err
+	}
+	user, err := c.users.ExternalUser(ctx, claims.identity(token.Issuer, token.Subject))
+	if err != nil {
+		return models.User{}, models.APIToken{}, err
+	}
+	apiToken := models.APIToken{
+		OwnerID:   user.ID,
+		Name:      "OIDC",
+		Scopes:    claims.scopes(),
+		ExpiresAt: &token.Expiry,
+		CreatedAt: token.IssuedAt,
+	}
+	return user, apiToken, nil
+}
+
+// discover obtiene la configuración del proveedor la primera vez y la guarda;
+// si falla se vuelve a intentar en la siguiente llamada
+func (c *Client) discover(ctx context.Context) (*discovery, error) {
+	c.discoverMu.Lock()
+	defer c.discoverMu.Unlock()
+
+	if c.discovered != nil {
+		return c.discovered, nil
+	}
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, c.httpClient), c.cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	c.discovered = &discovery{
+		provider: provider,
+		idTokens: provider.Verifier(&gooidc.Config{ClientID: c.cfg.ClientID}),
+		access:   provider.Verifier(&gooidc.Config{ClientID: c.cfg.Audience}),
+	}
+	return c.discovered, nil
+}
+
+// oauth2Config arma la configuración OAuth2 con los endpoints del proveedor
+func (c *Client) oauth2Config(provider *gooidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       c.cfg.Scopes,
+	}
+}
+
+// tokenClaims son los claims que se leen del ID token y del access token
+type tokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Scope         string `json:"scope"`
+}
+
+// identity arma la identidad externa a partir de los claims
+func (claims tokenClaims) identity(issuer, subject string) models.Identity {
+	return models.Identity{
+		Issuer:        issuer,
+		Subject:       subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}
+}
+
+// scopes obtiene los scopes de la API que trae el claim scope del access
+// token (separados por espacios). Si no trae ninguno el token no tiene acceso
+// a la API: el proveedor tiene que otorgarlos explícitamente
+func (claims tokenClaims) scopes() []string {
+	granted := strings.Fields(claims.Scope)
+	scopes := make([]string, 0, 2)
+	for _, scope := range []string{models.ScopeTodosRead, models.ScopeTodosWrite} {
+		for _, g := range granted {
+			if g == scope {
+				scopes = append(scopes, scope)
+				break
+			}
+		}
+	}
+	return scopes
+}
+
+// randomString genera un valor aleatorio para el state y el nonce
+func randomString() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
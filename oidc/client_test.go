@@ -0,0 +1,252 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// setup crea un proveedor de prueba y un cliente apuntando a él
+func setup(t *testing.T) (*mockIssuer, *Client, *store.UserStore) {
+	t.Helper()
+
+	issuer := newMockIssuer(t)
+	users := store.NewUserStore(time.Hour)
+	client := NewClient(Config{
+		Issuer:      issuer.URL(),
+		ClientID:    "todo-list",
+		RedirectURL: "http://localhost:8080/auth/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+		Audience:    "todo-api",
+	}, users)
+	return issuer, client, users
+}
+
+// login hace el flujo completo con las claims indicadas para el ID token
+func login(t *testing.T, issuer *mockIssuer, client *Client, claims map[string]interface{}) (models.User, error) {
+	t.Helper()
+
+	authURL, state, err := client.StartLogin(context.Background())
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	code := issuer.authorize(authURL, claims)
+	return client.FinishLogin(context.Background(), state, code)
+}
+
+func TestNewClientDisabledWithoutIssuer(t *testing.T) {
+	client := NewClient(Config{}, store.NewUserStore(time.Hour))
+	if client.Enabled() {
+		t.Fatal("sin issuer el cliente no debería estar habilitado")
+	}
+}
+
+func TestLoginWithPKCE(t *testing.T) {
+	issuer, client, _ := setup(t)
+
+	authURL, state, err := client.StartLogin(context.Background())
+	if err != nil {
+		t.Fatalf("StartLogin: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if query.Get("state") != state || query.Get("nonce") == "" || query.Get("client_id") != "todo-list" {
+		t.Fatalf("URL de autorización inesperada: %s", authURL)
+	}
+
+	code := issuer.authorize(authURL, map[string]interface{}{
+		"sub":            "ana-123",
+		"email":          "Ana@Example.com",
+		"email_verified": true,
+		"name":           "Ana",
+	})
+	user, err := client.FinishLogin(context.Background(), state, code)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if user.Email != "ana@example.com" || user.Name != "Ana" {
+		t.Fatalf("usuario inesperado: %+v", user)
+	}
+
+	// El state es de un solo uso
+	if _, err := client.FinishLogin(context.Background(), state, code); !errors.Is(err, ErrLoginExpired) {
+		t.Fatalf("reusar el state: esperaba ErrLoginExpired, obtuve %v", err)
+	}
+
+	// La misma identidad vuelve a la misma cuenta
+	again, err := login(t, issuer, client, map[string]interface{}{"sub": "ana-123"})
+	if err != nil {
+		t.Fatalf("segundo login: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Fatalf("segundo login creó otra cuenta: %d != %d", again.ID, user.ID)
+	}
+}
+
+func TestFinishLoginRejectsUnknownState(t *testing.T) {
+	_, client, _ := setup(t)
+
+	if _, err := client.FinishLogin(context.Background(), "desconocido", "code"); !errors.Is(err, ErrLoginExpired) {
+		t.Fatalf("esperaba ErrLoginExpired, obtuve %v", err)
+	}
+}
+
+func TestFinishLoginRejectsWrongNonce(t *testing.T) {
+	issuer, client, _ := setup(t)
+
+	_, err := login(t, issuer, client, map[string]interface{}{
+		"sub":            "ana-123",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"nonce":          "otro",
+	})
+	if !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("esperaba ErrNonceMismatch, obtuve %v", err)
+	}
+}
+
+func TestLoginLinksVerifiedEmailOnly(t *testing.T) {
+	issuer, client, users := setup(t)
+
+	existing, err := users.Register(context.Background(), models.RegisterRequest{
+		Email: "ana@example.com", Name: "Ana", Password: "una-clave-larga",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = login(t, issuer, client, map[string]interface{}{
+		"sub": "ana-123", "email": "ana@example.com", "email_verified": false,
+	})
+	if !errors.Is(err, store.ErrEmailNotVerified) {
+		t.Fatalf("email sin verificar: esperaba ErrEmailNotVerified, obtuve %v", err)
+	}
+
+	user, err := login(t, issuer, client, map[string]interface{}{
+		"sub": "ana-123", "email": "ana@example.com", "email_verified": true,
+	})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Fatalf("esperaba la cuenta existente %d, obtuve %d", existing.ID, user.ID)
+	}
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	issuer, client, _ := setup(t)
+	ctx := context.Background()
+
+	raw := issuer.sign(map[string]interface{}{
+		"sub":            "ana-123",
+		"aud":            "todo-api",
+		"email":          "ana@example.com",
+		"email_verified": true,
+		"scope":          "openid todos:read",
+	})
+	user, token, err := client.VerifyAccessToken(ctx, raw)
+	if err != nil {
+		t.Fatalf("VerifyAccessToken: %v", err)
+	}
+	if user.Email != "ana@example.com" || token.OwnerID != user.ID {
+		t.Fatalf("usuario inesperado: %+v, token %+v", user, token)
+	}
+	if !token.HasScope(models.ScopeTodosRead) || token.HasScope(models.ScopeTodosWrite) {
+		t.Fatalf("scopes inesperados: %v", token.Scopes)
+	}
+
+	// Sin scopes de la API el token no puede leer ni escribir
+	_, token, err = client.VerifyAccessToken(ctx, issuer.sign(map[string]interface{}{"sub": "ana-123", "aud": "todo-api"}))
+	if err != nil {
+		t.Fatalf("token sin scopes: %v", err)
+	}
+	if token.HasScope(models.ScopeTodosRead) || token.HasScope(models.ScopeTodosWrite) {
+		t.Fatalf("scopes inesperados sin claim scope: %v", token.Scopes)
+	}
+}
+
+func TestVerifyAccessTokenRequiresOwnAudience(t *testing.T) {
+	issuer := newMockIssuer(t)
+	claims := map[string]interface{}{"sub": "ana-123", "aud": "todo-list", "scope": "todos:read todos:write"}
+
+	// El ID token de la app trae el client ID como aud; sin OIDC_AUDIENCE, o
+	// con el client ID como audiencia, no sirve como access token
+	for name, audience := range map[string]string{"sin audiencia": "", "client ID": "todo-list"} {
+		client := NewClient(Config{Issuer: issuer.URL(), ClientID: "todo-list", Audience: audience}, store.NewUserStore(time.Hour))
+		if _, _, err := client.VerifyAccessToken(context.Background(), issuer.sign(claims)); !errors.Is(err, ErrNoAudience) {
+			t.Errorf("%s: err = %v, se esperaba ErrNoAudience", name, err)
+		}
+	}
+}
+
+func TestVerifyAccessTokenRejectsInvalidTokens(t *testing.T) {
+	issuer, client, _ := setup(t)
+	ctx := context.Background()
+
+	stranger, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := map[string]interface{}{
+		"sub": "ana-123", "aud": "todo-api", "email": "ana@example.com", "email_verified": true,
+	}
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	cases := map[string]string{
+		"otra audiencia": issuer.sign(with("aud", "otra-api")),
+		"otro issuer":    issuer.sign(with("iss", "https://otro.example.com")),
+		"vencido":        issuer.sign(with("exp", time.Now().Add(-time.Minute).Unix())),
+		"clave ajena":    signWith(t, "k1", stranger, issuer.URL(), valid),
+		"no es un JWT":   "abc",
+	}
+	for name, raw := range cases {
+		if _, _, err := client.VerifyAccessToken(ctx, raw); err == nil {
+			t.Errorf("%s: esperaba un error", name)
+		}
+	}
+}
+
+func TestVerifyAccessTokenCachesAndRefreshesJWKS(t *testing.T) {
+	issuer, client, _ := setup(t)
+	ctx := context.Background()
+	claims := map[string]interface{}{
+		"sub": "ana-123", "aud": "todo-api", "email": "ana@example.com", "email_verified": true,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := client.VerifyAccessToken(ctx, issuer.sign(claims)); err != nil {
+			t.Fatalf("VerifyAccessToken: %v", err)
+		}
+	}
+	issuer.mu.Lock()
+	hits := issuer.jwksHits
+	issuer.mu.Unlock()
+	if hits != 1 {
+		t.Fatalf("el JWKS debería pedirse una vez, se pidió %d", hits)
+	}
+
+	// Un kid desconocido obliga a pedir el JWKS otra vez
+	issuer.rotateKey("k2")
+	if _, _, err := client.VerifyAccessToken(ctx, issuer.sign(claims)); err != nil {
+		t.Fatalf("después de rotar la clave: %v", err)
+	}
+	issuer.mu.Lock()
+	hits = issuer.jwksHits
+	issuer.mu.Unlock()
+	if hits != 2 {
+		t.Fatalf("después de rotar el JWKS debería pedirse de nuevo, se pidió %d veces", hits)
+	}
+}
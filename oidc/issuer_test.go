@@ -0,0 +1,192 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockIssuer es un proveedor OIDC local para las pruebas: discovery, JWKS y
+// token endpoint con PKCE. Firma con RS256
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu    sync.Mutex
+	keys  map[string]*rsa.PrivateKey
+	kid   string
+	codes map[string]grant
+	// jwksHits cuenta cuántas veces se pidió el JWKS
+	jwksHits int
+}
+
+// grant es un code emitido por el authorization endpoint
+type grant struct {
+	challenge string
+	clientID  string
+	claims    map[string]interface{}
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	issuer := &mockIssuer{
+		t:     t,
+		keys:  make(map[string]*rsa.PrivateKey),
+		codes: make(map[string]grant),
+	}
+	issuer.rotateKey("k1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.discovery)
+	mux.HandleFunc("/jwks", issuer.jwks)
+	mux.HandleFunc("/token", issuer.token)
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// URL es el issuer del proveedor
+func (m *mockIssuer) URL() string {
+	return m.server.URL
+}
+
+// rotateKey agrega una clave nueva y firma con ella desde ese momento
+func (m *mockIssuer) rotateKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	m.mu.Lock()
+	m.keys[kid] = key
+	m.kid = kid
+	m.mu.Unlock()
+}
+
+func (m *mockIssuer) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"issuer":                                m.URL(),
+		"authorization_endpoint":                m.URL() + "/authorize",
+		"token_endpoint":                        m.URL() + "/token",
+		"jwks_uri":                              m.URL() + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockIssuer) jwks(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.jwksHits++
+	keys := make([]interface{}, 0, len(m.keys))
+	for kid, key := range m.keys {
+		keys = append(keys, map[string]interface{}{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": kid,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+}
+
+// authorize hace lo que haría el authorization endpoint después de que el
+// usuario inicia sesión: guarda el code_challenge y emite un code. claims son
+// los del ID token que se entregará; el nonce se copia de la petición
+func (m *mockIssuer) authorize(authURL string, claims map[string]interface{}) string {
+	m.t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		m.t.Fatalf("la URL no pide PKCE S256: %s", authURL)
+	}
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = query.Get("nonce")
+	}
+
+	code := "code-" + query.Get("state")
+	m.mu.Lock()
+	m.codes[code] = grant{
+		challenge: query.Get("code_challenge"),
+		clientID:  query.Get("client_id"),
+		claims:    claims,
+	}
+	m.mu.Unlock()
+	return code
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	m.mu.Lock()
+	g, ok := m.codes[r.Form.Get("code")]
+	delete(m.codes, r.Form.Get("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	claims := map[string]interface{}{"aud": g.clientID}
+	for key, value := range g.claims {
+		claims[key] = value
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "opaque",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     m.sign(claims),
+	})
+}
+
+// sign firma un JWT con la clave actual. Completa iss, iat y exp si faltan
+func (m *mockIssuer) sign(claims map[string]interface{}) string {
+	m.mu.Lock()
+	kid, key := m.kid, m.keys[m.kid]
+	m.mu.Unlock()
+	return signWith(m.t, kid, key, m.URL(), claims)
+}
+
+// signWith firma un JWT RS256 con key
+func signWith(t *testing.T, kid string, key *rsa.PrivateKey, issuer string, claims map[string]interface{}) string {
+	t.Helper()
+
+	now := time.Now()
+	full := map[string]interface{}{
+		"iss": issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		full[k] = v
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(full)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
@@ -0,0 +1,230 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"todo-list/models"
+)
+
+// Version es la versión de la API que se publica en la especificación
+const Version = "1.0.0"
+
+//go:embed viewer.html
+var viewerHTML []byte
+
+// specOnce genera la especificación una sola vez
+var (
+	specOnce sync.Once
+	specJSON []byte
+)
+
+// Document genera la especificación OpenAPI 3.1 a partir de Operations y de
+// los tipos de models
+func Document() map[string]interface{} {
+	registry := newSchemaRegistry()
+	responseRef := registry.ref(models.Response{})
+	problemRef := registry.ref(models.Problem{})
+
+	paths := make(map[string]interface{})
+	for _, op := range Operations {
+		item, ok := paths[op.Path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = operation(registry, op, responseRef)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "Todo List API",
+			"version":     Version,
+			"description": "API REST para gestionar tareas, plantillas y estadísticas. Cada workspace tiene sus propias cuentas y datos, y cada usuario solo ve los suyos; la sesión viaja en la cookie session o, desde scripts, como token personal en el header Authorization. Los errores usan application/problem+json (RFC 7807).",
+		},
+		"security": []interface{}{
+			map[string]interface{}{"sessionCookie": []string{}},
+			map[string]interface{}{"bearerToken": []string{}},
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": "/api/v1"},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": registry.schemas,
+			"securitySchemes": map[string]interface{}{
+				"sessionCookie": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "cookie",
+					"name":        "session",
+					"description": "Token de sesión que envían POST /auth/login y POST /auth/register",
+				},
+				"bearerToken": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "tdl_… o JWT",
+					"description":  "Token personal creado con POST /tokens o, con OIDC configurado, access token JWT del proveedor. todos:read permite las peticiones GET y todos:write las demás; responde 403 si falta el scope",
+				},
+			},
+			"responses": map[string]interface{}{
+				"Problem": map[string]interface{}{
+					"description": "Error en formato problem+json",
+					"content": map[string]interface{}{
+						models.ProblemContentType: map[string]interface{}{"schema": problemRef},
+					},
+				},
+			},
+		},
+	}
+}
+
+// operation genera el objeto Operation de OpenAPI para un endpoint
+func operation(registry *schemaRegistry, op Operation, responseRef map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		"operationId": operationID(op),
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+	}
+
+	// Las operaciones públicas anulan el security global y las de solo sesión
+	// no aceptan tokens personales
+	if op.Public {
+		result["security"] = []interface{}{}
+	} else if op.SessionOnly {
+		result["security"] = []interface{}{
+			map[string]interface{}{"sessionCookie": []string{}},
+		}
+	}
+
+	opParams := op.Params
+	if !op.Global {
+		opParams = append(append([]Param(nil), op.Params...), workspaceParam)
+	}
+	if len(opParams) > 0 {
+		params := make([]interface{}, 0, len(opParams))
+		for _, p := range opParams {
+			params = append(params, map[string]interface{}{
+				"name":        p.Name,
+				"in":          p.In,
+				"description": p.Description,
+				"required":    p.Required,
+				"schema":      map[string]interface{}{"type": p.Type},
+			})
+		}
+		result["parameters"] = params
+	}
+
+	if op.Body != nil {
+		result["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": registry.ref(op.Body)},
+			},
+		}
+	}
+
+	success := map[string]interface{}{
+		"description": http.StatusText(op.Status),
+	}
+	// Un 101 (WebSocket) no lleva cuerpo: los mensajes viajan por el socket
+	if op.Status != http.StatusSwitchingProtocols {
+		success["content"] = map[string]interface{}{
+			contentType(op): map[string]interface{}{"schema": responseSchema(registry, op, responseRef)},
+		}
+	}
+	if op.ETag {
+		success["headers"] = map[string]interface{}{
+			"ETag": map[string]interface{}{
+				"description": "Versión actual del recurso",
+				"schema":      map[string]interface{}{"type": "string"},
+			},
+		}
+	}
+
+	responses := map[string]interface{}{
+		strconv.Itoa(op.Status): success,
+	}
+	if op.NotModified {
+		responses[strconv.Itoa(http.StatusNotModified)] = map[string]interface{}{
+			"description": http.StatusText(http.StatusNotModified),
+		}
+	}
+	for _, status := range op.Errors {
+		responses[strconv.Itoa(status)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+	}
+	// Un cuerpo que supera MAX_BODY_BYTES se rechaza con 413
+	if op.Body != nil {
+		responses[strconv.Itoa(http.StatusRequestEntityTooLarge)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+	}
+	// Las rutas del workspace responden 404 si no existe y 429 al superar el
+	// límite de peticiones
+	if !op.Global {
+		responses[strconv.Itoa(http.StatusNotFound)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+		responses[strconv.Itoa(http.StatusTooManyRequests)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+	}
+	if !op.Public {
+		responses[strconv.Itoa(http.StatusUnauthorized)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+		responses[strconv.Itoa(http.StatusForbidden)] = map[string]interface{}{"$ref": "#/components/responses/Problem"}
+	}
+	result["responses"] = responses
+
+	return result
+}
+
+// responseSchema describe la respuesta exitosa: el sobre models.Response con
+// el tipo de data de la operación, o un schema libre si la respuesta es cruda
+func responseSchema(registry *schemaRegistry, op Operation, responseRef map[string]interface{}) map[string]interface{} {
+	if op.Raw {
+		if op.Data != nil {
+			return registry.ref(op.Data)
+		}
+		if op.ContentType == "text/html" {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "object"}
+	}
+	if op.Data == nil {
+		return responseRef
+	}
+	return map[string]interface{}{
+		"allOf": []interface{}{
+			responseRef,
+			map[string]interface{}{
+				"properties": map[string]interface{}{"data": registry.ref(op.Data)},
+			},
+		},
+	}
+}
+
+// contentType retorna el media type de la respuesta exitosa
+func contentType(op Operation) string {
+	if op.ContentType != "" {
+		return op.ContentType
+	}
+	return "application/json"
+}
+
+// operationID genera un identificador estable como "get_todos_id"
+func operationID(op Operation) string {
+	replacer := strings.NewReplacer("/", "_", "{", "", "}", "", ".", "_")
+	return strings.ToLower(op.Method) + strings.TrimRight(replacer.Replace(op.Path), "_")
+}
+
+// ServeSpec sirve la especificación en JSON
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(func() {
+		specJSON, _ = json.MarshalIndent(Document(), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(specJSON)
+}
+
+// ServeViewer sirve el visor local de la especificación
+func ServeViewer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(viewerHTML)
+}
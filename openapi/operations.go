@@ -0,0 +1,462 @@
+package openapi
+
+import (
+	"net/http"
+	"todo-list/models"
+)
+
+// Param describe un parámetro de ruta, query o header
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Type        string
+	Required    bool
+}
+
+// Operation describe un endpoint de la API
+type Operation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Params  []Param
+	// Body es un valor del tipo que se recibe en el cuerpo; nil si no lleva cuerpo
+	Body interface{}
+	// Status es el código de la respuesta exitosa
+	Status int
+	// Data es un valor del tipo que viaja en el campo data de models.Response;
+	// en las respuestas crudas (Raw) describe el cuerpo o cada evento del stream
+	Data interface{}
+	// Raw indica que la respuesta no usa el sobre models.Response
+	Raw         bool
+	ContentType string
+	// ETag indica que la respuesta exitosa trae el header ETag
+	ETag bool
+	// NotModified indica que la operación responde 304 con If-None-Match
+	NotModified bool
+	Errors      []int
+	// Public indica que la operación no exige sesión; las demás responden 401 sin ella
+	Public bool
+	// SessionOnly indica que la operación no acepta tokens personales, solo la cookie de sesión
+	SessionOnly bool
+	// Global indica que la operación no depende del workspace; las demás
+	// aceptan el header X-Workspace y responden 404 si no existe
+	Global bool
+}
+
+// Parámetros comunes
+var (
+	idParam = Param{Name: "id", In: "path", Description: "ID del recurso", Type: "integer", Required: true}
+
+	memberParam = Param{Name: "userID", In: "path", Description: "ID del usuario miembro", Type: "integer", Required: true}
+
+	ifMatchParam = Param{
+		Name:        "If-Match",
+		In:          "header",
+		Description: `ETag del todo (por ejemplo "v3"); responde 412 si cambió`,
+		Type:        "string",
+	}
+	ifNoneMatchParam = Param{
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "ETag conocido; responde 304 si nada cambió",
+		Type:        "string",
+	}
+	idempotencyKeyParam = Param{
+		Name:        "Idempotency-Key",
+		In:          "header",
+		Description: "Clave para reintentar sin crear duplicados",
+		Type:        "string",
+	}
+	workspaceParam = Param{
+		Name:        "X-Workspace",
+		In:          "header",
+		Description: "Slug del workspace; sin él se usa el subdominio o el workspace por defecto",
+		Type:        "string",
+	}
+)
+
+// Operations lista todos los endpoints de /api/v1. El test de rutas falla si
+// esta lista y las rutas registradas en el router dejan de coincidir
+var Operations = []Operation{
+	// Cuentas
+	{
+		Method: http.MethodPost, Path: "/auth/register", Tag: "cuentas",
+		Summary: "Crear una cuenta e iniciar su sesión (cookie session)",
+		Body:    models.RegisterRequest{},
+		Status:  http.StatusCreated, Data: models.User{}, Public: true,
+		Errors: []int{http.StatusBadRequest, http.StatusConflict},
+	},
+	{
+		Method: http.MethodPost, Path: "/auth/login", Tag: "cuentas",
+		Summary: "Iniciar sesión con email y contraseña (cookie session)",
+		Body:    models.LoginRequest{},
+		Status:  http.StatusOK, Data: models.User{}, Public: true,
+		Errors: []int{http.StatusBadRequest, http.StatusUnauthorized},
+	},
+	{
+		Method: http.MethodPost, Path: "/auth/logout", Tag: "cuentas",
+		Summary: "Cerrar la sesión actual",
+		Status:  http.StatusOK, Public: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/auth/me", Tag: "cuentas",
+		Summary: "Usuario de la sesión actual",
+		Status:  http.StatusOK, Data: models.User{},
+	},
+	{
+		Method: http.MethodGet, Path: "/workspace", Tag: "cuentas",
+		Summary: "Workspace de la petición con su límite de todos y cuántos usa",
+		Status:  http.StatusOK, Data: models.Workspace{},
+	},
+
+	// Tokens personales
+	{
+		Method: http.MethodGet, Path: "/tokens", Tag: "cuentas",
+		Summary: "Listar los tokens personales (sin su valor)",
+		Status:  http.StatusOK, Data: []models.APIToken{}, SessionOnly: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/tokens", Tag: "cuentas",
+		Summary: "Crear un token personal; la respuesta trae su valor para el header Authorization",
+		Body:    models.APITokenRequest{},
+		Status:  http.StatusCreated, Data: models.APIToken{}, SessionOnly: true,
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodDelete, Path: "/tokens/{id}", Tag: "cuentas",
+		Summary: "Revocar un token personal",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, SessionOnly: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+
+	// Todos
+	{
+		Method: http.MethodGet, Path: "/todos", Tag: "todos",
+		Summary: "Listar los todos que no están archivados",
+		Params: []Param{ifNoneMatchParam, {
+			Name: "assignee", In: "query", Type: "string",
+			Description: `Solo los todos de este responsable: "me", "none" (sin responsable) o el ID de una cuenta`,
+		}},
+		Status: http.StatusOK, Data: []models.Todo{}, ETag: true, NotModified: true,
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodPost, Path: "/todos", Tag: "todos",
+		Summary: "Crear un todo",
+		Params:  []Param{idempotencyKeyParam},
+		Body:    models.TodoRequest{},
+		Status:  http.StatusCreated, Data: models.Todo{}, ETag: true,
+		Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+	},
+	{
+		Method: http.MethodPost, Path: "/todos/batch", Tag: "todos",
+		Summary: "Aplicar varias operaciones de forma atómica",
+		Params:  []Param{idempotencyKeyParam},
+		Body:    models.BatchRequest{},
+		Status:  http.StatusOK, Data: []models.BatchResult{},
+		Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+	},
+	{
+		Method: http.MethodGet, Path: "/todos/{id}", Tag: "todos",
+		Summary: "Obtener un todo",
+		Params:  []Param{idParam, ifNoneMatchParam},
+		Status:  http.StatusOK, Data: models.Todo{}, ETag: true, NotModified: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPut, Path: "/todos/{id}", Tag: "todos",
+		Summary: "Reemplazar un todo",
+		Params:  []Param{idParam, ifMatchParam},
+		Body:    models.TodoRequest{},
+		Status:  http.StatusOK, Data: models.Todo{}, ETag: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+	},
+	{
+		Method: http.MethodPatch, Path: "/todos/{id}", Tag: "todos",
+		Summary: "Actualizar parcialmente un todo",
+		Params:  []Param{idParam, ifMatchParam},
+		Body:    models.TodoPatch{},
+		Status:  http.StatusOK, Data: models.Todo{}, ETag: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+	},
+	{
+		Method: http.MethodGet, Path: "/todos/{id}/history", Tag: "todos",
+		Summary: "Historial de cambios de un todo, incluidas las reasignaciones",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: []models.AuditEntry{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodDelete, Path: "/todos/{id}", Tag: "todos",
+		Summary: "Eliminar un todo",
+		Params:  []Param{idParam, ifMatchParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed, http.StatusPreconditionRequired},
+	},
+
+	// Archivo
+	{
+		Method: http.MethodGet, Path: "/archive", Tag: "archivo",
+		Summary: "Buscar entre los todos archivados",
+		Params:  []Param{{Name: "q", In: "query", Description: "Texto a buscar en título y descripción", Type: "string"}},
+		Status:  http.StatusOK, Data: []models.Todo{},
+	},
+	{
+		Method: http.MethodPost, Path: "/todos/{id}/archive", Tag: "archivo",
+		Summary: "Archivar un todo completado",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: models.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method: http.MethodPost, Path: "/todos/{id}/unarchive", Tag: "archivo",
+		Summary: "Devolver un todo archivado a la lista",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: models.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+
+	// Plantillas
+	{
+		Method: http.MethodGet, Path: "/templates", Tag: "plantillas",
+		Summary: "Listar las plantillas",
+		Status:  http.StatusOK, Data: []models.TodoTemplate{},
+	},
+	{
+		Method: http.MethodPost, Path: "/templates", Tag: "plantillas",
+		Summary: "Crear una plantilla",
+		Body:    models.TodoTemplateRequest{},
+		Status:  http.StatusCreated, Data: models.TodoTemplate{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/templates/{id}", Tag: "plantillas",
+		Summary: "Obtener una plantilla",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: models.TodoTemplate{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPut, Path: "/templates/{id}", Tag: "plantillas",
+		Summary: "Reemplazar una plantilla",
+		Params:  []Param{idParam},
+		Body:    models.TodoTemplateRequest{},
+		Status:  http.StatusOK, Data: models.TodoTemplate{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodDelete, Path: "/templates/{id}", Tag: "plantillas",
+		Summary: "Eliminar una plantilla",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPost, Path: "/templates/{id}/instantiate", Tag: "plantillas",
+		Summary: "Crear los todos de una plantilla",
+		Params:  []Param{idParam, idempotencyKeyParam},
+		Body:    models.InstantiateRequest{},
+		Status:  http.StatusCreated, Data: []models.Todo{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	},
+
+	// Listas compartidas, miembros e invitaciones
+	{
+		Method: http.MethodGet, Path: "/lists", Tag: "listas",
+		Summary: "Listar las listas compartidas de las que el usuario es miembro",
+		Status:  http.StatusOK, Data: []models.List{},
+	},
+	{
+		Method: http.MethodPost, Path: "/lists", Tag: "listas",
+		Summary: "Crear una lista compartida; quien la crea queda como owner",
+		Body:    models.ListRequest{},
+		Status:  http.StatusCreated, Data: models.List{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/lists/{id}", Tag: "listas",
+		Summary: "Obtener una lista con sus miembros (y sus invitaciones pendientes si eres owner)",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: models.List{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPut, Path: "/lists/{id}", Tag: "listas",
+		Summary: "Renombrar una lista (owner)",
+		Params:  []Param{idParam},
+		Body:    models.ListRequest{},
+		Status:  http.StatusOK, Data: models.List{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodDelete, Path: "/lists/{id}", Tag: "listas",
+		Summary: "Eliminar una lista junto con sus todos (owner)",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPost, Path: "/lists/{id}/invitations", Tag: "listas",
+		Summary: "Invitar a un email a la lista con un rol (owner)",
+		Params:  []Param{idParam},
+		Body:    models.InvitationRequest{},
+		Status:  http.StatusCreated, Data: models.Invitation{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method: http.MethodPut, Path: "/lists/{id}/members/{userID}", Tag: "listas",
+		Summary: "Cambiar el rol de un miembro (owner)",
+		Params:  []Param{idParam, memberParam},
+		Body:    models.MemberRequest{},
+		Status:  http.StatusOK, Data: models.List{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method: http.MethodDelete, Path: "/lists/{id}/members/{userID}", Tag: "listas",
+		Summary: "Quitar a un miembro (owner) o salir de la lista (tu propio ID)",
+		Params:  []Param{idParam, memberParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		Method: http.MethodGet, Path: "/invitations", Tag: "listas",
+		Summary: "Listar las invitaciones pendientes dirigidas a tu email",
+		Status:  http.StatusOK, Data: []models.Invitation{},
+	},
+	{
+		Method: http.MethodPost, Path: "/invitations/{id}/accept", Tag: "listas",
+		Summary: "Aceptar una invitación con su código y unirse a la lista",
+		Params:  []Param{idParam},
+		Body:    models.InvitationAcceptRequest{},
+		Status:  http.StatusOK, Data: models.List{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodDelete, Path: "/invitations/{id}", Tag: "listas",
+		Summary: "Rechazar una invitación propia o cancelar una de tu lista (owner)",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+
+	// Cambios en tiempo real
+	{
+		Method: http.MethodGet, Path: "/events", Tag: "eventos",
+		Summary: "Feed de cambios como Server-Sent Events (evento change con un ChangeEvent, o reset)",
+		Params: []Param{
+			{Name: "Last-Event-ID", In: "header", Description: "Último ID recibido; se reenvían los cambios posteriores", Type: "integer"},
+			{Name: "last_event_id", In: "query", Description: "Alternativa a Last-Event-ID para clientes que no pueden enviar headers", Type: "integer"},
+		},
+		Status: http.StatusOK, Raw: true, ContentType: "text/event-stream", Data: models.ChangeEvent{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/ws", Tag: "eventos",
+		Summary: "Canal WebSocket de colaboración: cambios, presencia y bloqueos de edición (mensajes JSON con type, ver README)",
+		Params:  []Param{{Name: "name", In: "query", Description: "Nombre que verán las demás personas", Type: "string"}},
+		Status:  http.StatusSwitchingProtocols, Raw: true,
+		Errors: []int{http.StatusBadRequest},
+	},
+
+	// GraphQL
+	{
+		Method: http.MethodPost, Path: "/graphql", Tag: "graphql",
+		Summary: "Ejecutar una consulta o mutación GraphQL (ver /graphql/schema)",
+		Body:    models.GraphQLRequest{},
+		Status:  http.StatusOK, Raw: true, ContentType: "application/json", Data: models.GraphQLResponse{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/graphql", Tag: "graphql",
+		Summary: "Canal WebSocket de suscripciones GraphQL (subprotocolo graphql-transport-ws)",
+		Status:  http.StatusSwitchingProtocols, Raw: true,
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/graphql/schema", Tag: "graphql",
+		Summary: "Schema GraphQL en SDL",
+		Status:  http.StatusOK, Raw: true, ContentType: "text/plain",
+	},
+
+	// Webhooks
+	{
+		Method: http.MethodGet, Path: "/webhooks", Tag: "webhooks",
+		Summary: "Listar los webhooks (sin sus secretos)",
+		Status:  http.StatusOK, Data: []models.Webhook{},
+	},
+	{
+		Method: http.MethodPost, Path: "/webhooks", Tag: "webhooks",
+		Summary: "Registrar un webhook; la respuesta trae el secreto para verificar las firmas",
+		Body:    models.WebhookRequest{},
+		Status:  http.StatusCreated, Data: models.Webhook{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}", Tag: "webhooks",
+		Summary: "Obtener un webhook",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: models.Webhook{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodPut, Path: "/webhooks/{id}", Tag: "webhooks",
+		Summary: "Reemplazar un webhook (sin secret conserva el actual)",
+		Params:  []Param{idParam},
+		Body:    models.WebhookRequest{},
+		Status:  http.StatusOK, Data: models.Webhook{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodDelete, Path: "/webhooks/{id}", Tag: "webhooks",
+		Summary: "Eliminar un webhook y su registro de entregas",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK,
+		Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", Tag: "webhooks",
+		Summary: "Registro de entregas de un webhook con cada intento, las más recientes primero",
+		Params:  []Param{idParam},
+		Status:  http.StatusOK, Data: []models.WebhookDelivery{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		Method: http.MethodGet, Path: "/webhooks/dead-letters", Tag: "webhooks",
+		Summary: "Entregas que agotaron sus reintentos",
+		Status:  http.StatusOK, Data: []models.WebhookDelivery{},
+	},
+	{
+		Method: http.MethodPost, Path: "/webhooks/deliveries/{id}/redeliver", Tag: "webhooks",
+		Summary: "Volver a poner en cola una entrega de dead letters",
+		Params:  []Param{idParam},
+		Status:  http.StatusAccepted, Data: models.WebhookDelivery{},
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+
+	// Estadísticas y sistema
+	{
+		Method: http.MethodGet, Path: "/stats", Tag: "estadísticas",
+		Summary: "Estimaciones, throughput semanal y burndown",
+		Params:  []Param{{Name: "days", In: "query", Description: "Días del burndown (1 a 365, por defecto 14)", Type: "integer"}},
+		Status:  http.StatusOK, Data: models.Stats{},
+		Errors: []int{http.StatusBadRequest},
+	},
+	{
+		Method: http.MethodGet, Path: "/health", Tag: "sistema",
+		Summary: "Verificar que la API está corriendo",
+		Status:  http.StatusOK, Raw: true, ContentType: "application/json", Public: true, Global: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", Tag: "sistema",
+		Summary: "Esta especificación OpenAPI",
+		Status:  http.StatusOK, Raw: true, ContentType: "application/json", Public: true, Global: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/docs", Tag: "sistema",
+		Summary: "Visor de la especificación",
+		Status:  http.StatusOK, Raw: true, ContentType: "text/html", Public: true, Global: true,
+	},
+}
@@ -0,0 +1,234 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeType se documenta como string date-time en lugar de como struct
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaRegistry genera los schemas de components a partir de los tipos de Go
+type schemaRegistry struct {
+	schemas map[string]interface{}
+}
+
+// newSchemaRegistry crea un registro vacío
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: make(map[string]interface{})}
+}
+
+// ref registra el tipo de v y retorna una referencia a su schema
+func (r *schemaRegistry) ref(v interface{}) map[string]interface{} {
+	return r.schemaFor(reflect.TypeOf(v))
+}
+
+// schemaFor retorna el schema de un tipo; los structs con nombre se agregan a
+// components y se referencian con $ref
+func (r *schemaRegistry) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return r.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := r.schemas[name]; !ok {
+			// Reservar el nombre antes de recorrer los campos por si el tipo es recursivo
+			r.schemas[name] = nil
+			r.schemas[name] = r.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		// interface{} y cualquier otro tipo admiten cualquier valor
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema genera el schema de objeto de un struct a partir de sus tags
+// json y validate. En los structs sin reglas de validación (las respuestas)
+// son obligatorios los campos sin omitempty
+func (r *schemaRegistry) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	validated := hasValidateTags(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitempty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+
+		schema := r.schemaFor(field.Type)
+		rules, itemRules := splitRules(field.Tag.Get("validate"))
+		applyRules(schema, field.Type, rules)
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			applyRules(items, elemType(field.Type), itemRules)
+		}
+		if field.Type.Kind() == reflect.Ptr {
+			schema = nullable(schema)
+		}
+		properties[name] = schema
+
+		if validated {
+			if hasRule(rules, "required") && !hasRule(rules, "omitempty") && !hasRule(rules, "omitnil") {
+				required = append(required, name)
+			}
+		} else if !omitempty && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// hasValidateTags indica si algún campo del struct declara reglas de validación
+func hasValidateTags(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("validate") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRules separa las reglas del campo de las que aplican a cada elemento (después de dive)
+func splitRules(tag string) ([]string, []string) {
+	if tag == "" {
+		return nil, nil
+	}
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		if rule == "dive" {
+			return rules[:i], rules[i+1:]
+		}
+	}
+	return rules, nil
+}
+
+// hasRule indica si la lista de reglas contiene name
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRules traduce las reglas min, max, oneof, notblank y http_url a las palabras clave de JSON Schema
+func applyRules(schema map[string]interface{}, t reflect.Type, rules []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			schema[limitKeyword(name, t.Kind())] = n
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "notblank":
+			if t.Kind() == reflect.String {
+				schema["minLength"] = 1
+			}
+		case "http_url":
+			schema["format"] = "uri"
+		}
+	}
+}
+
+// limitKeyword elige la palabra clave de JSON Schema según el tipo del campo
+func limitKeyword(rule string, kind reflect.Kind) string {
+	prefix := "minimum"
+	if rule == "max" {
+		prefix = "maximum"
+	}
+	switch kind {
+	case reflect.String:
+		return rule + "Length"
+	case reflect.Slice, reflect.Array:
+		return rule + "Items"
+	case reflect.Map:
+		return rule + "Properties"
+	default:
+		return prefix
+	}
+}
+
+// elemType obtiene el tipo de los elementos de una lista o mapa, atravesando punteros
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		return t.Elem()
+	}
+	return t
+}
+
+// nullable permite null además del schema original (OpenAPI 3.1)
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if typ, ok := schema["type"].(string); ok {
+		copied := make(map[string]interface{}, len(schema))
+		for k, v := range schema {
+			copied[k] = v
+		}
+		copied["type"] = []string{typ, "null"}
+		return copied
+	}
+	return map[string]interface{}{
+		"oneOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
+
+// jsonName obtiene el nombre del campo en JSON y si tiene omitempty
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			return name, true
+		}
+	}
+	return name, false
+}
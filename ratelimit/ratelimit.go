@@ -0,0 +1,197 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRule se retorna cuando un límite no tiene el formato "N/periodo"
+var ErrInvalidRule = errors.New("el límite debe tener el formato N/periodo, por ejemplo 100/m")
+
+// Rule es un límite de Requests peticiones por Period. El bucket de cada
+// cliente admite ráfagas de hasta Requests y se recarga de forma continua
+type Rule struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseRule lee un límite como "100/m", "10/s", "1000/h" o "30/10s". Vacío o
+// "0" es un límite desactivado, con Requests en cero
+func ParseRule(value string) (Rule, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Rule{}, nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Rule{}, ErrInvalidRule
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests < 0 {
+		return Rule{}, ErrInvalidRule
+	}
+	period = strings.TrimSpace(period)
+	if period == "s" || period == "m" || period == "h" {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Rule{}, ErrInvalidRule
+	}
+	return Rule{Requests: requests, Period: d}, nil
+}
+
+// Enabled indica si el límite se aplica
+func (r Rule) Enabled() bool {
+	return r.Requests > 0
+}
+
+// Policy describe el límite para el header RateLimit-Policy: "100;w=60"
+func (r Rule) Policy() string {
+	return fmt.Sprintf("%d;w=%d", r.Requests, int(r.Period/time.Second))
+}
+
+// Decision es el resultado de consultar el limiter para una petición
+type Decision struct {
+	Rule    Rule
+	Allowed bool
+	// Remaining es cuántas peticiones quedan en el bucket
+	Remaining int
+	// Reset es cuánto falta para que el bucket vuelva a estar lleno
+	Reset time.Duration
+	// RetryAfter es cuánto esperar antes de reintentar; cero si se permitió
+	RetryAfter time.Duration
+}
+
+// SetHeaders agrega los headers RateLimit-* y, si se rechazó, Retry-After
+func (d Decision) SetHeaders(h http.Header) {
+	h.Set("RateLimit-Limit", strconv.Itoa(d.Rule.Requests))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+	h.Set("RateLimit-Policy", d.Rule.Policy())
+	if !d.Allowed {
+		h.Set("Retry-After", strconv.Itoa(d.RetryAfterSeconds()))
+	}
+}
+
+// Stricter retorna la decisión más restrictiva entre d y other: la que
+// rechazó o, si ambas permitieron, la que deja menos peticiones. Una decisión
+// sin regla (sin límite) nunca es la más restrictiva
+func (d Decision) Stricter(other Decision) Decision {
+	switch {
+	case !other.Rule.Enabled():
+		return d
+	case !d.Rule.Enabled():
+		return other
+	case d.Allowed != other.Allowed:
+		if !other.Allowed {
+			return other
+		}
+		return d
+	case other.Remaining != d.Remaining:
+		if other.Remaining < d.Remaining {
+			return other
+		}
+		return d
+	case other.Reset > d.Reset:
+		return other
+	}
+	return d
+}
+
+// RetryAfterSeconds es RetryAfter en segundos enteros, redondeado hacia
+// arriba como en el header Retry-After
+func (d Decision) RetryAfterSeconds() int {
+	return seconds(d.RetryAfter)
+}
+
+// seconds redondea una espera hacia arriba a segundos enteros
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// bucket son las peticiones disponibles de un cliente
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Limiter aplica un token bucket por clave (token, usuario o IP). Los
+// buckets que se llenan de nuevo se descartan, así que la memoria depende de
+// los clientes activos en el último período
+type Limiter struct {
+	mu        sync.Mutex
+	rule      Rule
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLimiter crea un limiter con la regla indicada; retorna nil si la regla
+// está desactivada, y un limiter nil deja pasar todas las peticiones
+func NewLimiter(rule Rule) *Limiter {
+	if !rule.Enabled() {
+		return nil
+	}
+	return &Limiter{
+		rule:    rule,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consume una petición del bucket de key si queda alguna
+func (l *Limiter) Allow(key string) Decision {
+	if l == nil {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(l.rule.Requests)
+	rate := capacity / l.rule.Period.Seconds()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	}
+	b.updated = now
+
+	decision := Decision{Rule: l.rule}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = time.Duration((capacity - b.tokens) / rate * float64(time.Second))
+	return decision
+}
+
+// sweep descarta los buckets que ya estarían llenos, una vez por período;
+// requiere tener el lock tomado
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.rule.Period {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.rule.Period {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestReopeningAnArchivedTodoUnarchivesIt(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			alice := register(t, setup(), "acme", "alice@example.com", "Alice")
+			id := alice.createID("/todos", map[string]any{"title": "Cerrar el sprint", "completed": true})
+			alice.mustData(http.MethodPost, fmt.Sprintf("/todos/%d/archive", id), nil, http.StatusOK, nil)
+
+			var todo struct {
+				Completed  bool    `json:"completed"`
+				Archived   bool    `json:"archived"`
+				ArchivedAt *string `json:"archived_at"`
+			}
+			alice.mustData(http.MethodPatch, fmt.Sprintf("/todos/%d", id), map[string]any{"completed": false}, http.StatusOK, &todo)
+			if todo.Completed || todo.Archived || todo.ArchivedAt != nil {
+				t.Fatalf("el todo reabierto sigue archivado: %+v", todo)
+			}
+		})
+	}
+}
+
+func TestArchivingKeepsStatsTotals(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			alice := register(t, setup(), "acme", "alice@example.com", "Alice")
+			id := alice.createID("/todos", map[string]any{"title": "Cerrar el sprint", "completed": true, "estimate": 5})
+			alice.createID("/todos", map[string]any{"title": "Planear el siguiente", "estimate": 3})
+
+			type totals struct {
+				Total             int `json:"total"`
+				Completed         int `json:"completed"`
+				EstimateCompleted int `json:"estimate_completed"`
+			}
+			var before, after totals
+			alice.mustData(http.MethodGet, "/stats", nil, http.StatusOK, &before)
+			alice.mustData(http.MethodPost, fmt.Sprintf("/todos/%d/archive", id), nil, http.StatusOK, nil)
+			alice.mustData(http.MethodGet, "/stats", nil, http.StatusOK, &after)
+			if before != (totals{Total: 2, Completed: 1, EstimateCompleted: 5}) || after != before {
+				t.Fatalf("archivar cambió las estadísticas: antes %+v, después %+v", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package routes
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"todo-list/config"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/oidc"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSSO crea el cliente OIDC con la configuración del entorno; nil si
+// OIDC_ISSUER no está definido
+func newSSO(cfg config.Config, users *store.UserStore) *oidc.Client {
+	oidcCfg := oidc.Config{
+		Issuer:       cfg.OIDCIssuer,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       cfg.OIDCScopes,
+		Audience:     cfg.OIDCAudience,
+	}
+	if oidcCfg.Issuer != "" && !oidcCfg.AccessTokens() {
+		log.Printf("⚠️  OIDC_AUDIENCE está vacío o es el client ID; la API no acepta access tokens JWT")
+	}
+	return oidc.NewClient(oidcCfg, users)
+}
+
+// unauthenticatedProblem se usa cuando la ruta exige sesión y no hay una válida
+func unauthenticatedProblem() models.Problem {
+	return models.NewProblem(http.StatusUnauthorized, models.CodeUnauthenticated, "Inicia sesión para continuar")
+}
+
+// sessionUser obtiene el usuario de la cookie de sesión; ok es false si no
+// hay cookie o la sesión ya no es válida
+func sessionUser(r *http.Request, users *store.UserStore) (models.User, bool) {
+	cookie, err := r.Cookie(handlers.SessionCookie)
+	if err != nil {
+		return models.User{}, false
+	}
+	user, err := users.SessionUser(r.Context(), cookie.Value)
+	if err != nil {
+		return models.User{}, false
+	}
+	return user, true
+}
+
+// bearerUser obtiene el usuario del header Authorization ("Bearer <token>")
+// y deja al usuario y al token en el contexto. El token puede ser un token
+// personal o, con OIDC configurado, un access token JWT del proveedor; ok es
+// false si el header no tiene ese formato o el token no es válido
+func bearerUser(r *http.Request, users *store.UserStore, sso *oidc.Client) (context.Context, bool) {
+	value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, false
+	}
+	value = strings.TrimSpace(value)
+	var user models.User
+	var token models.APIToken
+	var err error
+	if strings.HasPrefix(value, store.TokenPrefix) || !sso.Enabled() {
+		user, token, err = users.TokenUser(r.Context(), value)
+	} else {
+		user, token, err = sso.VerifyAccessToken(r.Context(), value)
+	}
+	if err != nil {
+		return nil, false
+	}
+	return store.WithToken(store.WithUser(r.Context(), user), token), true
+}
+
+// authenticate obtiene el contexto con el usuario de la petición. Si viene el
+// header Authorization se usa solo el token personal, aunque también haya
+// cookie de sesión
+func authenticate(r *http.Request, users *store.UserStore, sso *oidc.Client) (context.Context, models.Problem, bool) {
+	if r.Header.Get("Authorization") != "" {
+		ctx, ok := bearerUser(r, users, sso)
+		if !ok {
+			return nil, unauthenticatedProblem(), false
+		}
+		if problem, ok := checkScope(ctx, r); !ok {
+			return nil, problem, false
+		}
+		return ctx, models.Problem{}, true
+	}
+	user, ok := sessionUser(r, users)
+	if !ok {
+		return nil, unauthenticatedProblem(), false
+	}
+	return store.WithUser(r.Context(), user), models.Problem{}, true
+}
+
+// graphQLPath es la ruta de GraphQL en los routers de mux y Gin
+const graphQLPath = "/api/v1/graphql"
+
+// checkScope revisa que el token de la petición tenga el scope que pide el
+// método: todos:read para leer y todos:write para todo lo demás. En GraphQL
+// el método no dice nada (las consultas van por POST y las mutaciones también
+// llegan por el WebSocket, que es un GET), así que alcanza con todos:read y
+// cada mutación exige todos:write
+func checkScope(ctx context.Context, r *http.Request) (models.Problem, bool) {
+	token, _ := store.TokenFrom(ctx)
+	scope := models.ScopeTodosWrite
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.URL.Path == graphQLPath {
+		scope = models.ScopeTodosRead
+	}
+	if token.HasScope(scope) {
+		return models.Problem{}, true
+	}
+	return models.NewProblem(http.StatusForbidden, models.CodeInsufficientScope,
+		"El token no tiene el scope "+scope), false
+}
+
+// sessionOnlyProblem se usa cuando un token personal intenta administrar tokens
+func sessionOnlyProblem() models.Problem {
+	return models.NewProblem(http.StatusForbidden, models.CodeInsufficientScope,
+		"Los tokens personales solo se administran con la sesión iniciada")
+}
+
+// requireSession exige una sesión o un token válidos y deja al usuario en el
+// contexto de la petición, que es lo que usan los stores para separar los datos
+func requireSession(users *store.UserStore, sso *oidc.Client) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, problem, ok := authenticate(r, users, sso)
+			if !ok {
+				handlers.WriteProblem(w, r, problem)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireSessionGin exige una sesión o un token válidos y deja al usuario en
+// el contexto de la petición
+func requireSessionGin(users *store.UserStore, sso *oidc.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, problem, ok := authenticate(c.Request, users, sso)
+		if !ok {
+			handlers.AbortWithProblem(c, problem)
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// sessionOnly rechaza las peticiones hechas con un token personal, para que un
+// token no pueda crear otros con más permisos
+func sessionOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := store.TokenFrom(r.Context()); ok {
+			handlers.WriteProblem(w, r, sessionOnlyProblem())
+			return
+		}
+		next(w, r)
+	}
+}
+
+// sessionOnlyGin rechaza las peticiones hechas con un token personal
+func sessionOnlyGin(c *gin.Context) {
+	if _, ok := store.TokenFrom(c.Request.Context()); ok {
+		handlers.AbortWithProblem(c, sessionOnlyProblem())
+		return
+	}
+	c.Next()
+}
+
+// requireSessionTempl es requireSessionGin para la interfaz HTMX: sin sesión
+// las páginas redirigen a /login y las peticiones HTMX lo piden con HX-Redirect
+func requireSessionTempl(users *store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := sessionUser(c.Request, users)
+		if !ok {
+			if c.GetHeader("HX-Request") == "true" {
+				c.Header("HX-Redirect", "/login")
+				handlers.AbortWithProblem(c, unauthenticatedProblem())
+				return
+			}
+			c.Redirect(http.StatusSeeOther, "/login")
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(store.WithUser(c.Request.Context(), user))
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"net/http"
+	"todo-list/handlers"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBody limita el cuerpo de las peticiones del router de mux a limit bytes.
+// Un Content-Length mayor se rechaza enseguida con 413; un cuerpo sin
+// Content-Length falla al leerlo y el handler responde el mismo 413
+func maxBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				handlers.WriteProblem(w, r, bodyTooLargeProblem(limit))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxBodyGin limita el cuerpo de las peticiones de los routers de Gin a
+// limit bytes
+func maxBodyGin(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			handlers.AbortWithProblem(c, bodyTooLargeProblem(limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// bodyTooLargeProblem se usa cuando el Content-Length supera el máximo
+func bodyTooLargeProblem(limit int64) models.Problem {
+	return handlers.InvalidBodyProblem(&http.MaxBytesError{Limit: limit})
+}
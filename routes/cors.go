@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/config"
+	"todo-list/handlers"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsExposeHeaders son los headers de respuesta que el navegador deja leer
+// a un origen permitido
+var corsExposeHeaders = []string{
+	"ETag", "Idempotent-Replayed",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After",
+}
+
+// corsPolicy decide qué orígenes pueden llamar a la API desde el navegador.
+// La comparten los routers de mux y Gin y los canales WebSocket
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	wildcards   []originPattern
+	methods     string
+	headers     string
+	expose      string
+	maxAge      string
+	credentials bool
+}
+
+// originPattern es un origen con comodín de subdominio, como
+// https://*.example.com: coincide con el esquema y el final del host
+type originPattern struct {
+	scheme string
+	suffix string
+}
+
+// newCORSPolicy crea la política con la configuración del entorno
+func newCORSPolicy(cfg config.Config) *corsPolicy {
+	policy := &corsPolicy{
+		origins:     make(map[string]bool),
+		methods:     strings.Join(cfg.CORSMethods, ", "),
+		headers:     strings.Join(cfg.CORSHeaders, ", "),
+		expose:      strings.Join(corsExposeHeaders, ", "),
+		credentials: cfg.CORSCredentials,
+	}
+	if cfg.CORSMaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(cfg.CORSMaxAge / time.Second))
+	}
+
+	for _, origin := range cfg.CORSOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		scheme, host, _ := strings.Cut(origin, "://")
+		switch {
+		case origin == "*":
+			policy.anyOrigin = true
+		case strings.HasPrefix(host, "*."):
+			policy.wildcards = append(policy.wildcards, originPattern{scheme: scheme, suffix: host[1:]})
+		default:
+			policy.origins[origin] = true
+		}
+	}
+	if policy.anyOrigin && policy.credentials {
+		log.Printf("⚠️  CORS_ORIGINS incluye \"*\", que no admite credenciales; se ignora CORS_CREDENTIALS")
+		policy.credentials = false
+	}
+	return policy
+}
+
+// allows indica si origin puede llamar a la API
+func (p *corsPolicy) allows(origin string) bool {
+	origin = strings.ToLower(origin)
+	if p.anyOrigin || p.origins[origin] {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok {
+		return false
+	}
+	for _, pattern := range p.wildcards {
+		if scheme == pattern.scheme && len(host) > len(pattern.suffix) && strings.HasSuffix(host, pattern.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsWebSocket es el CheckOrigin de los canales WebSocket: el navegador
+// no aplica CORS al abrirlos, así que se aceptan el mismo origen, los
+// orígenes permitidos y los clientes que no envían Origin
+func (p *corsPolicy) allowsWebSocket(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return p.allows(origin)
+}
+
+// handle agrega los headers CORS a la respuesta y responde los preflight.
+// Retorna true si ya respondió y la petición no debe seguir
+func (p *corsPolicy) handle(w http.ResponseWriter, r *http.Request) bool {
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+	if preflight {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	if !p.allows(origin) {
+		// Sin headers CORS el navegador no deja leer la respuesta; al
+		// preflight se le responde el motivo
+		if preflight {
+			handlers.WriteProblem(w, r, originNotAllowedProblem(origin))
+			return true
+		}
+		return false
+	}
+
+	if p.anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if !preflight {
+		header.Set("Access-Control-Expose-Headers", p.expose)
+		return false
+	}
+
+	header.Set("Access-Control-Allow-Methods", p.methods)
+	header.Set("Access-Control-Allow-Headers", p.headers)
+	if p.maxAge != "" {
+		header.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// originNotAllowedProblem se usa cuando un preflight llega de un origen que
+// no está en CORS_ORIGINS
+func originNotAllowedProblem(origin string) models.Problem {
+	return models.NewProblem(http.StatusForbidden, models.CodeOriginNotAllowed,
+		"El origen "+origin+" no puede llamar a la API")
+}
+
+// corsMiddleware aplica la política CORS en el router de mux
+func corsMiddleware(policy *corsPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.handle(w, r) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// corsGin aplica la política CORS en los routers de Gin
+func corsGin(policy *corsPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.handle(c.Writer, c.Request) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,103 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// graphQLMutation crea un todo; un token de solo lectura no puede ejecutarla
+const graphQLMutation = `mutation { createTodo(input: {title: "desde el socket"}) { id } }`
+
+// newScopedToken crea un token personal de la cuenta con los scopes indicados
+func newScopedToken(c *tenantClient, scopes ...string) string {
+	c.t.Helper()
+
+	var token struct {
+		Token string `json:"token"`
+	}
+	c.mustData(http.MethodPost, "/tokens", map[string]any{"name": "token", "scopes": scopes}, http.StatusCreated, &token)
+	return token.Token
+}
+
+// graphQLOverWebSocket ejecuta una operación por el canal graphql-transport-ws
+// con el token indicado y retorna el primer mensaje con su resultado
+func graphQLOverWebSocket(t *testing.T, server *httptest.Server, workspace, token, query string) (string, string) {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}, HandshakeTimeout: 2 * time.Second}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set(workspaceHeader, workspace)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + apiPrefix + "/graphql"
+	conn, resp, err := dialer.Dial(url, header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("abrir el WebSocket de GraphQL: %v (status %d)", err, status)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	send := func(msg map[string]any) {
+		if err := conn.WriteJSON(msg); err != nil {
+			t.Fatalf("enviar %v: %v", msg["type"], err)
+		}
+	}
+	send(map[string]any{"type": "connection_init"})
+	send(map[string]any{"id": "1", "type": "subscribe", "payload": map[string]any{"query": query}})
+	for {
+		var msg struct {
+			ID      string          `json:"id"`
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("leer la respuesta de GraphQL: %v", err)
+		}
+		if msg.ID == "1" && (msg.Type == "next" || msg.Type == "error") {
+			return msg.Type, string(msg.Payload)
+		}
+	}
+}
+
+func TestGraphQLScopeIsCheckedPerOperation(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(setup())
+			defer server.Close()
+			alice := register(t, server.Config.Handler, "acme", "alice@example.com", "Alice")
+			readOnly := newScopedToken(alice, "todos:read")
+			readWrite := newScopedToken(alice, "todos:read", "todos:write")
+
+			// Por WebSocket (un GET) la mutación con solo todos:read se rechaza
+			kind, payload := graphQLOverWebSocket(t, server, "acme", readOnly, graphQLMutation)
+			if !strings.Contains(payload, "insufficient_scope") {
+				t.Errorf("mutación por WebSocket con todos:read: %s %s, se esperaba insufficient_scope", kind, payload)
+			}
+			kind, payload = graphQLOverWebSocket(t, server, "acme", readWrite, graphQLMutation)
+			if kind != "next" || !strings.Contains(payload, `"createTodo":{"id"`) {
+				t.Errorf("mutación por WebSocket con todos:write: %s %s", kind, payload)
+			}
+
+			// Por POST el token de solo lectura puede consultar pero no mutar
+			reader := &tenantClient{t: t, router: server.Config.Handler, prefix: apiPrefix, workspace: "acme", token: readOnly}
+			w := reader.do(http.MethodPost, "/graphql", map[string]any{"query": `{ todos { totalCount } }`})
+			if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "errors") {
+				t.Errorf("consulta por POST con todos:read: status %d: %s", w.Code, w.Body.String())
+			}
+			w = reader.do(http.MethodPost, "/graphql", map[string]any{"query": graphQLMutation})
+			if !strings.Contains(w.Body.String(), "insufficient_scope") {
+				t.Errorf("mutación por POST con todos:read: status %d: %s, se esperaba insufficient_scope", w.Code, w.Body.String())
+			}
+		})
+	}
+}
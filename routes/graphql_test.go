@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGraphQLSetsListAndAssignees(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice")
+			bob := register(t, router, "acme", "bob@example.com", "Bob")
+			list := alice.createID("/lists", map[string]any{"name": "Equipo"})
+			joinList(alice, bob, list, "bob@example.com", "editor")
+
+			type todo struct {
+				ID         int   `json:"id"`
+				ListID     *int  `json:"listId"`
+				AssigneeID *int  `json:"assigneeId"`
+				Watchers   []int `json:"watchers"`
+			}
+			var created struct {
+				CreateTodo todo `json:"createTodo"`
+			}
+			alice.mustData(http.MethodPost, "/graphql", map[string]any{"query": fmt.Sprintf(
+				`mutation { createTodo(input: {title: "Revisar el deploy", listId: %d, assigneeId: %d, watchers: [%d]}) { id listId assigneeId watchers } }`,
+				list, bob.userID, alice.userID)}, http.StatusOK, &created)
+			got := created.CreateTodo
+			if got.ListID == nil || *got.ListID != list || got.AssigneeID == nil || *got.AssigneeID != bob.userID ||
+				len(got.Watchers) != 1 || got.Watchers[0] != alice.userID {
+				t.Fatalf("createTodo no aplicó lista y responsables: %+v", got)
+			}
+
+			// Sin los campos se conservan; en cero se quitan
+			var updated struct {
+				UpdateTodo todo `json:"updateTodo"`
+			}
+			alice.mustData(http.MethodPost, "/graphql", map[string]any{"query": fmt.Sprintf(
+				`mutation { updateTodo(id: %d, input: {title: "Revisar el deploy"}) { id listId assigneeId watchers } }`,
+				got.ID)}, http.StatusOK, &updated)
+			if updated.UpdateTodo.ListID == nil || updated.UpdateTodo.AssigneeID == nil || len(updated.UpdateTodo.Watchers) != 1 {
+				t.Fatalf("updateTodo sin los campos los cambió: %+v", updated.UpdateTodo)
+			}
+			alice.mustData(http.MethodPost, "/graphql", map[string]any{"query": fmt.Sprintf(
+				`mutation { updateTodo(id: %d, input: {title: "Revisar el deploy", listId: 0, assigneeId: 0, watchers: []}) { id listId assigneeId watchers } }`,
+				got.ID)}, http.StatusOK, &updated)
+			if updated.UpdateTodo.ListID != nil || updated.UpdateTodo.AssigneeID != nil || len(updated.UpdateTodo.Watchers) != 0 {
+				t.Fatalf("updateTodo en cero no los quitó: %+v", updated.UpdateTodo)
+			}
+		})
+	}
+}
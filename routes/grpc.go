@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"todo-list/config"
+	"todo-list/events"
+	"todo-list/grpcapi"
+	"todo-list/oidc"
+	"todo-list/store"
+)
+
+// GRPCServer es la API gRPC de un router: usa el mismo store y feed de
+// cambios que las rutas HTTP, autentica con los mismos tokens y, si está
+// configurado, con los access tokens de OIDC, en los mismos workspaces. El
+// router no la arranca; main la inicia y la detiene junto al servidor HTTP
+type GRPCServer struct {
+	server *grpcapi.Server
+	port   int
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newGRPCServer prepara la API gRPC para GRPC_PORT sin abrir el puerto
+func newGRPCServer(cfg config.Config, todoStore *store.TodoStore, broker *events.Broker, users *store.UserStore, sso *oidc.Client, workspaces *store.Workspaces) *GRPCServer {
+	return &GRPCServer{
+		server: grpcapi.NewServer(todoStore, broker, users, sso, workspaces, cfg.RequireIfMatch),
+		port:   cfg.GRPCPort,
+	}
+}
+
+// Start abre GRPC_PORT y sirve la API en segundo plano; con GRPC_PORT en cero
+// no hace nada. Un puerto ocupado es un error, para que el servidor no
+// arranque sin la API gRPC que se pidió
+func (g *GRPCServer) Start() error {
+	if g.port <= 0 {
+		return nil
+	}
+
+	addr := ":" + strconv.Itoa(g.port)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("no se pudo iniciar la API gRPC en %s: %w", addr, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	g.done = make(chan struct{})
+	go func() {
+		defer close(g.done)
+		if err := g.server.Serve(ctx, lis); err != nil {
+			log.Printf("⚠️  La API gRPC se detuvo: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop deja de aceptar llamadas gRPC y espera a que el servidor se detenga;
+// si no se inició no hace nada
+func (g *GRPCServer) Stop() {
+	if g.cancel == nil {
+		return
+	}
+	g.cancel()
+	<-g.done
+}
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"todo-list/handlers"
+	"todo-list/idempotency"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyRecorder captura la respuesta del handler para poder repetirla
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader guarda el código de estado antes de enviarlo
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write guarda una copia del cuerpo antes de enviarlo
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// idempotent repite la primera respuesta de cada Idempotency-Key en lugar de
+// volver a ejecutar el handler
+func idempotent(cache *idempotency.Cache) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.HeaderKey)
+			if key == "" {
+				next(w, r)
+				return
+			}
+			if len(key) > idempotency.MaxKeyLength {
+				handlers.WriteProblem(w, r, idempotencyKeyTooLongProblem())
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				handlers.WriteProblem(w, r, unreadableBodyProblem(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key = userKey(r, key)
+			state, record := cache.Begin(key, idempotency.Fingerprint(r.Method, r.URL.Path, body))
+			switch state {
+			case idempotency.StateReplay:
+				for name, values := range record.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(idempotency.HeaderReplayed, "true")
+				w.WriteHeader(record.Status)
+				w.Write(record.Body)
+				return
+			case idempotency.StateMismatch:
+				handlers.WriteProblem(w, r, idempotencyKeyReusedProblem())
+				return
+			case idempotency.StateInFlight:
+				handlers.WriteProblem(w, r, idempotencyInFlightProblem())
+				return
+			}
+
+			// Los errores del servidor y los pánicos no se guardan para que el
+			// cliente pueda reintentar; el pánico sigue hacia recoveryMiddleware
+			completed := false
+			defer func() {
+				if !completed {
+					cache.Abort(key)
+				}
+			}()
+
+			recorder := &idempotencyRecorder{ResponseWriter: w}
+			next(recorder, r)
+
+			if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+				return
+			}
+			cache.Complete(key, idempotency.Record{
+				Status: recorder.status,
+				Header: w.Header().Clone(),
+				Body:   recorder.body.Bytes(),
+			})
+			completed = true
+		}
+	}
+}
+
+// userKey separa las claves por usuario: dos cuentas pueden usar la misma
+// Idempotency-Key sin ver la respuesta de la otra
+func userKey(r *http.Request, key string) string {
+	return strconv.Itoa(store.UserID(r.Context())) + ":" + key
+}
+
+// idempotencyKeyTooLongProblem se usa cuando la clave supera MaxKeyLength
+func idempotencyKeyTooLongProblem() models.Problem {
+	return models.NewValidationProblem([]models.FieldError{{
+		Field:   idempotency.HeaderKey,
+		Code:    models.FieldTooLong,
+		Message: "El header Idempotency-Key es demasiado largo",
+	}})
+}
+
+// unreadableBodyProblem se usa cuando no se pudo leer el cuerpo para calcular
+// su huella; si superó el máximo, es un 413
+func unreadableBodyProblem(err error) models.Problem {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return handlers.InvalidBodyProblem(err)
+	}
+	return models.NewProblem(http.StatusBadRequest, models.CodeInvalidBody, "No se pudo leer el cuerpo de la petición")
+}
+
+// idempotencyKeyReusedProblem se usa cuando la clave llega con otro cuerpo
+func idempotencyKeyReusedProblem() models.Problem {
+	return models.NewProblem(http.StatusUnprocessableEntity, models.CodeIdempotencyKeyReused,
+		"La Idempotency-Key ya se usó con una petición distinta")
+}
+
+// idempotencyInFlightProblem se usa cuando la primera petición con la clave aún no termina
+func idempotencyInFlightProblem() models.Problem {
+	return models.NewProblem(http.StatusConflict, models.CodeIdempotencyInFlight,
+		"Hay una petición en curso con la misma Idempotency-Key")
+}
+
+// idempotencyRecorderGin captura la respuesta de Gin para poder repetirla
+type idempotencyRecorderGin struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write guarda una copia del cuerpo antes de enviarlo
+func (r *idempotencyRecorderGin) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// WriteString guarda una copia del cuerpo antes de enviarlo
+func (r *idempotencyRecorderGin) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// idempotentGin repite la primera respuesta de cada Idempotency-Key en lugar de
+// volver a ejecutar el handler
+func idempotentGin(cache *idempotency.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			handlers.AbortWithProblem(c, idempotencyKeyTooLongProblem())
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			handlers.AbortWithProblem(c, unreadableBodyProblem(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = userKey(c.Request, key)
+		state, record := cache.Begin(key, idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body))
+		switch state {
+		case idempotency.StateReplay:
+			for name, values := range record.Header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header(idempotency.HeaderReplayed, "true")
+			c.Data(record.Status, record.Header.Get("Content-Type"), record.Body)
+			c.Abort()
+			return
+		case idempotency.StateMismatch:
+			handlers.AbortWithProblem(c, idempotencyKeyReusedProblem())
+			return
+		case idempotency.StateInFlight:
+			handlers.AbortWithProblem(c, idempotencyInFlightProblem())
+			return
+		}
+
+		// Los errores del servidor y los pánicos no se guardan para que el
+		// cliente pueda reintentar; el pánico sigue hacia la recuperación de Gin
+		completed := false
+		defer func() {
+			if !completed {
+				cache.Abort(key)
+			}
+		}()
+
+		recorder := &idempotencyRecorderGin{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() { c.Writer = recorder.ResponseWriter }()
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		cache.Complete(key, idempotency.Record{
+			Status: status,
+			Header: recorder.Header().Clone(),
+			Body:   recorder.body.Bytes(),
+		})
+		completed = true
+	}
+}
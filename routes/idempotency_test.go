@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyKeyIsReleasedAfterAPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	panics := true
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic("falla del handler")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	muxCache := idempotency.NewCache(time.Hour)
+	mux := recoveryMiddleware(idempotent(muxCache)(handler))
+
+	ginCache := idempotency.NewCache(time.Hour)
+	ginRouter := gin.New()
+	ginRouter.Use(gin.CustomRecovery(recoveryGin))
+	ginRouter.POST("/todos", idempotentGin(ginCache), func(c *gin.Context) { handler(c.Writer, c.Request) })
+
+	for name, router := range map[string]http.Handler{"mux": mux, "gin": ginRouter} {
+		t.Run(name, func(t *testing.T) {
+			send := func() int {
+				r := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(`{"title":"x"}`))
+				r.Header.Set(idempotency.HeaderKey, "clave-"+name)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+				return w.Code
+			}
+
+			panics = true
+			if code := send(); code != http.StatusInternalServerError {
+				t.Fatalf("el pánico respondió %d, se esperaba 500", code)
+			}
+			// El reintento vuelve a ejecutar el handler en lugar de quedar en curso
+			panics = false
+			if code := send(); code != http.StatusCreated {
+				t.Fatalf("el reintento respondió %d, se esperaba 201", code)
+			}
+		})
+	}
+}
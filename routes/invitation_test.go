@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestInvitationRequiresItsToken(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice")
+			bob := register(t, router, "acme", "bob@example.com", "Bob")
+			list := alice.createID("/lists", map[string]any{"name": "Equipo"})
+
+			var invitation struct {
+				ID    int    `json:"id"`
+				Token string `json:"token"`
+			}
+			alice.mustData(http.MethodPost, fmt.Sprintf("/lists/%d/invitations", list), map[string]any{
+				"email": "bob@example.com", "role": "editor",
+			}, http.StatusCreated, &invitation)
+			if invitation.Token == "" {
+				t.Fatal("Invitar no retornó el código de la invitación")
+			}
+			path := fmt.Sprintf("/invitations/%d/accept", invitation.ID)
+
+			// El email coincide, pero sin el código correcto no alcanza
+			if code := problemCode(bob.do(http.MethodPost, path, map[string]any{"token": "inv_0"})); code != "invitation_not_found" {
+				t.Errorf("aceptar con un código incorrecto: %q, se esperaba invitation_not_found", code)
+			}
+			bob.mustData(http.MethodPost, path, map[string]any{"token": invitation.Token}, http.StatusOK, nil)
+
+			// El código sirve una sola vez
+			if code := problemCode(bob.do(http.MethodPost, path, map[string]any{"token": invitation.Token})); code != "invitation_not_found" {
+				t.Errorf("reusar el código: %q, se esperaba invitation_not_found", code)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// joinList invita a member a la lista de owner con role y acepta la invitación
+func joinList(owner, member *tenantClient, list int, email, role string) {
+	owner.t.Helper()
+
+	var invitation struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	owner.mustData(http.MethodPost, fmt.Sprintf("/lists/%d/invitations", list), map[string]any{
+		"email": email, "role": role,
+	}, http.StatusCreated, &invitation)
+	member.mustData(http.MethodPost, fmt.Sprintf("/invitations/%d/accept", invitation.ID), map[string]any{"token": invitation.Token}, http.StatusOK, nil)
+}
+
+func TestEditorCannotTakeATeammateTodo(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice")
+			bob := register(t, router, "acme", "bob@example.com", "Bob")
+			list := alice.createID("/lists", map[string]any{"name": "Equipo"})
+
+			joinList(alice, bob, list, "bob@example.com", "editor")
+
+			// Bob edita los todos de la lista, pero no se queda con el de Alice
+			shared := alice.createID("/todos", map[string]any{"title": "Revisar el deploy", "list_id": list})
+			path := fmt.Sprintf("/todos/%d", shared)
+			if code := problemCode(bob.do(http.MethodPatch, path, map[string]any{"list_id": 0})); code != "forbidden" {
+				t.Errorf("un editor movió a personal un todo ajeno: %q, se esperaba forbidden", code)
+			}
+			alice.mustData(http.MethodGet, path, nil, http.StatusOK, nil)
+
+			// Sus propios todos sí los puede sacar de la lista
+			own := bob.createID("/todos", map[string]any{"title": "Preparar la demo", "list_id": list})
+			bob.mustData(http.MethodPatch, fmt.Sprintf("/todos/%d", own), map[string]any{"list_id": 0}, http.StatusOK, nil)
+
+			// Y el owner de la lista puede sacar cualquiera
+			alice.mustData(http.MethodPatch, path, map[string]any{"list_id": 0}, http.StatusOK, nil)
+		})
+	}
+}
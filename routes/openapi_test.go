@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/mux"
+)
+
+// apiPrefix es el prefijo de las rutas que describe la especificación
+const apiPrefix = "/api/v1"
+
+// ginParam reconoce los parámetros de Gin como :id
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+// specRoutes obtiene las rutas publicadas en /api/v1/openapi.json como "GET /todos/{id}"
+func specRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, apiPrefix+"/openapi.json", nil)
+	w := httptest.NewRecorder()
+	router, _ := SetupRoutes()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET openapi.json: status %d", w.Code)
+	}
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("openapi.json no es JSON válido: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		t.Fatalf("se esperaba OpenAPI 3.1, se obtuvo %q", spec.OpenAPI)
+	}
+
+	routes := make(map[string]bool)
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			routes[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	return routes
+}
+
+// muxRoutes obtiene las rutas de /api/v1 registradas en SetupRoutes
+func muxRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	routes := make(map[string]bool)
+	router, _ := SetupRoutes()
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, apiPrefix+"/") {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes[method+" "+strings.TrimPrefix(path, apiPrefix)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("recorrer rutas de mux: %v", err)
+	}
+	return routes
+}
+
+// ginRoutes obtiene las rutas de /api/v1 registradas en SetupRoutesGin
+func ginRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	routes := make(map[string]bool)
+	ginRouter, _ := SetupRoutesGin()
+	for _, route := range ginRouter.Routes() {
+		if !strings.HasPrefix(route.Path, apiPrefix+"/") {
+			continue
+		}
+		path := ginParam.ReplaceAllString(strings.TrimPrefix(route.Path, apiPrefix), "{$1}")
+		routes[route.Method+" "+path] = true
+	}
+	return routes
+}
+
+// diffRoutes retorna las rutas de a que no están en b, ordenadas
+func diffRoutes(a, b map[string]bool) []string {
+	missing := make([]string, 0)
+	for route := range a {
+		if !b[route] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// checkDrift falla si las rutas de un router y las de la especificación no coinciden
+func checkDrift(t *testing.T, name string, registered, spec map[string]bool) {
+	t.Helper()
+
+	if len(registered) == 0 {
+		t.Fatalf("%s no registró rutas bajo %s", name, apiPrefix)
+	}
+	for _, route := range diffRoutes(registered, spec) {
+		t.Errorf("%s registra %s pero no está en la especificación OpenAPI", name, route)
+	}
+	for _, route := range diffRoutes(spec, registered) {
+		t.Errorf("la especificación OpenAPI describe %s pero %s no la registra", route, name)
+	}
+}
+
+func TestOpenAPIMatchesMuxRoutes(t *testing.T) {
+	checkDrift(t, "SetupRoutes", muxRoutes(t), specRoutes(t))
+}
+
+func TestOpenAPIMatchesGinRoutes(t *testing.T) {
+	checkDrift(t, "SetupRoutesGin", ginRoutes(t), specRoutes(t))
+}
+
+func TestOpenAPIViewerIsServed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	muxRouter, _ := SetupRoutes()
+	ginRouter, _ := SetupRoutesGin()
+	for name, router := range map[string]http.Handler{"mux": muxRouter, "gin": ginRouter} {
+		req := httptest.NewRequest(http.MethodGet, apiPrefix+"/docs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "openapi.json") {
+			t.Errorf("%s: GET /docs respondió %d sin el visor", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"todo-list/handlers"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeNotFoundProblem se usa cuando ninguna ruta de la API coincide
+func routeNotFoundProblem() models.Problem {
+	return models.NewProblem(http.StatusNotFound, models.CodeRouteNotFound, "Ruta no encontrada")
+}
+
+// internalProblem se usa cuando un handler entra en pánico
+func internalProblem() models.Problem {
+	return models.NewProblem(http.StatusInternalServerError, models.CodeInternal, "Error interno del servidor")
+}
+
+// recoveryMiddleware convierte un pánico en un error 500 problem+json
+func recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				log.Printf("panic en %s %s: %v", r.Method, r.URL.Path, err)
+				handlers.WriteProblem(w, r, internalProblem())
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recoveryGin convierte un pánico en un error 500 problem+json
+func recoveryGin(c *gin.Context, err any) {
+	handlers.AbortWithProblem(c, internalProblem())
+}
+
+// noRouteGin responde con problem+json cuando ninguna ruta coincide
+func noRouteGin(c *gin.Context) {
+	handlers.AbortWithProblem(c, routeNotFoundProblem())
+}
+
+// noMethodGin responde con problem+json cuando la ruta existe con otro método
+func noMethodGin(c *gin.Context) {
+	handlers.AbortWithProblem(c, models.NewProblem(http.StatusMethodNotAllowed, models.CodeMethodNotAllowed,
+		"Método "+c.Request.Method+" no permitido en esta ruta"))
+}
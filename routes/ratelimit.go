@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-list/config"
+	"todo-list/gql"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/ratelimit"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimits son los limiters de cada grupo de rutas; uno nil no limita
+type rateLimits struct {
+	// auth limita por IP el registro y el inicio de sesión
+	auth *ratelimit.Limiter
+	// api limita todas las peticiones de cada cliente a la API
+	api *ratelimit.Limiter
+	// write limita además las peticiones que cambian datos
+	write      *ratelimit.Limiter
+	trustProxy bool
+}
+
+// newRateLimits crea los limiters con la configuración del entorno. Un
+// límite inválido se informa y queda desactivado
+func newRateLimits(cfg config.Config) rateLimits {
+	return rateLimits{
+		auth:       newLimiter("RATE_LIMIT_AUTH", cfg.RateLimitAuth),
+		api:        newLimiter("RATE_LIMIT_API", cfg.RateLimitAPI),
+		write:      newLimiter("RATE_LIMIT_WRITE", cfg.RateLimitWrite),
+		trustProxy: cfg.TrustProxy,
+	}
+}
+
+// newLimiter crea el limiter de una variable de entorno
+func newLimiter(key, value string) *ratelimit.Limiter {
+	rule, err := ratelimit.ParseRule(value)
+	if err != nil {
+		log.Printf("⚠️  %s inválido (%q): %v; queda sin límite", key, value, err)
+	}
+	return ratelimit.NewLimiter(rule)
+}
+
+// rateKey identifica al cliente de la petición: el token con el que se
+// autenticó, su usuario o, sin sesión, su IP
+func rateKey(r *http.Request, trustProxy bool) string {
+	ctx := r.Context()
+	if token, ok := store.TokenFrom(ctx); ok && token.ID != 0 {
+		return "token:" + strconv.Itoa(token.ID)
+	}
+	if user := store.UserID(ctx); user != 0 {
+		return "user:" + strconv.Itoa(user)
+	}
+	return "ip:" + clientIP(r, trustProxy)
+}
+
+// clientIP obtiene la IP del cliente; con trustProxy, la última de
+// X-Forwarded-For, que es la que agregó el proxy. Las anteriores las escribe
+// el cliente y no sirven para identificarlo
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			forwarded := values[len(values)-1]
+			if i := strings.LastIndex(forwarded, ","); i >= 0 {
+				forwarded = forwarded[i+1:]
+			}
+			if ip := strings.TrimSpace(forwarded); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// isWrite indica si la petición cambia datos
+func isWrite(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// checkRate consume una petición del limiter y agrega sus headers. Retorna
+// false si el cliente superó el límite
+func checkRate(limiter *ratelimit.Limiter, w http.ResponseWriter, r *http.Request, trustProxy bool) (models.Problem, bool) {
+	decision := limiter.Allow(rateKey(r, trustProxy))
+	return applyDecision(decision, w.Header())
+}
+
+// applyDecision agrega los headers de la decisión y retorna false con el
+// problema a responder si se rechazó
+func applyDecision(decision ratelimit.Decision, h http.Header) (models.Problem, bool) {
+	if decision.Rule.Enabled() {
+		decision.SetHeaders(h)
+	}
+	if !decision.Allowed {
+		return rateLimitedProblem(decision.RetryAfterSeconds()), false
+	}
+	return models.Problem{}, true
+}
+
+// checkAPIRate consume una petición del límite general y, si cambia datos,
+// otra del de escrituras. Los headers son los de la decisión más restrictiva,
+// para que el cliente vea el límite que se le va a acabar primero. En GraphQL
+// el método no dice si hay escritura, así que cada mutación consume la suya
+// al ejecutarse y las consultas solo cuentan para el límite general. Retorna
+// la petición con la que seguir
+func (l rateLimits) checkAPIRate(w http.ResponseWriter, r *http.Request) (*http.Request, models.Problem, bool) {
+	key := rateKey(r, l.trustProxy)
+	decision := l.api.Allow(key)
+	if decision.Allowed && isWrite(r) && l.write != nil {
+		if r.URL.Path == graphQLPath {
+			r = r.WithContext(gql.WithWriteLimit(r.Context(), l.writeLimit(key, decision, w.Header())))
+		} else {
+			decision = decision.Stricter(l.write.Allow(key))
+		}
+	}
+	problem, ok := applyDecision(decision, w.Header())
+	return r, problem, ok
+}
+
+// writeLimit es el límite de escrituras que consumen las mutaciones de una
+// petición GraphQL; actualiza los headers, que se envían al terminar de
+// ejecutar la operación
+func (l rateLimits) writeLimit(key string, current ratelimit.Decision, h http.Header) gql.WriteLimit {
+	return func() (models.Problem, bool) {
+		write := l.write.Allow(key)
+		current = current.Stricter(write)
+		applyDecision(current, h)
+		if !write.Allowed {
+			return rateLimitedProblem(write.RetryAfterSeconds()), false
+		}
+		return models.Problem{}, true
+	}
+}
+
+// rateLimit limita por cliente las peticiones de un grupo de rutas del router
+// de mux
+func rateLimit(limiter *ratelimit.Limiter, trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if problem, ok := checkRate(limiter, w, r, trustProxy); !ok {
+				handlers.WriteProblem(w, r, problem)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitGin limita por cliente las peticiones de un grupo de rutas de los
+// routers de Gin
+func rateLimitGin(limiter *ratelimit.Limiter, trustProxy bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+		if problem, ok := checkRate(limiter, c.Writer, c.Request, trustProxy); !ok {
+			handlers.AbortWithProblem(c, problem)
+			return
+		}
+		c.Next()
+	}
+}
+
+// apiRateLimit aplica a la API del router de mux el límite general y el de
+// escrituras
+func apiRateLimit(limits rateLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limits.api == nil && limits.write == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, problem, ok := limits.checkAPIRate(w, r)
+			if !ok {
+				handlers.WriteProblem(w, r, problem)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiRateLimitGin aplica a la API de los routers de Gin el límite general y
+// el de escrituras
+func apiRateLimitGin(limits rateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limits.api == nil && limits.write == nil {
+			c.Next()
+			return
+		}
+		r, problem, ok := limits.checkAPIRate(c.Writer, c.Request)
+		if !ok {
+			handlers.AbortWithProblem(c, problem)
+			return
+		}
+		c.Request = r
+		c.Next()
+	}
+}
+
+// rateLimitedProblem se usa cuando el cliente superó su límite de peticiones;
+// retryAfter son los mismos segundos del header Retry-After
+func rateLimitedProblem(retryAfter int) models.Problem {
+	return models.NewProblem(http.StatusTooManyRequests, models.CodeRateLimited,
+		"Demasiadas peticiones; vuelve a intentarlo en "+strconv.Itoa(retryAfter)+" segundos")
+}
@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIPUsesTheProxyEntry(t *testing.T) {
+	cases := []struct {
+		name       string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{"sin proxy", nil, false, "192.0.2.1"},
+		{"sin proxy ignora el header", []string{"203.0.113.9"}, false, "192.0.2.1"},
+		{"una entrada", []string{"203.0.113.9"}, true, "203.0.113.9"},
+		{"el cliente inventa la primera", []string{"10.0.0.1, 203.0.113.9"}, true, "203.0.113.9"},
+		{"varios headers", []string{"10.0.0.1", "198.51.100.4 , 203.0.113.9"}, true, "203.0.113.9"},
+		{"entrada vacía", []string{"10.0.0.1, "}, true, "192.0.2.1"},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		for _, value := range tc.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := clientIP(r, tc.trustProxy); got != tc.want {
+			t.Errorf("%s: clientIP = %q, se esperaba %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRateLimitHeadersComeFromTheStricterLimit(t *testing.T) {
+	cases := []struct {
+		name, api, write, want string
+	}{
+		{"el general se acaba antes", "5/h", "100/h", "5"},
+		{"el de escrituras se acaba antes", "100/h", "3/h", "3"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupTenants(t, 0)
+			t.Setenv("RATE_LIMIT_API", tc.api)
+			t.Setenv("RATE_LIMIT_WRITE", tc.write)
+			for name, setup := range apiRouters() {
+				alice := register(t, setup(), "acme", "alice@example.com", "Alice")
+				w := alice.do(http.MethodPost, "/todos", map[string]any{"title": "Revisar el deploy"})
+				if got := w.Header().Get("RateLimit-Limit"); w.Code != http.StatusCreated || got != tc.want {
+					t.Errorf("%s: status %d, RateLimit-Limit %q, se esperaba %s", name, w.Code, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLQueriesDoNotCountAsWrites(t *testing.T) {
+	setupTenants(t, 0)
+	t.Setenv("RATE_LIMIT_WRITE", "1/h")
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			alice := register(t, setup(), "acme", "alice@example.com", "Alice")
+			for i := 0; i < 3; i++ {
+				w := alice.do(http.MethodPost, "/graphql", map[string]any{"query": `{ todos { totalCount } }`})
+				if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "errors") {
+					t.Fatalf("consulta %d: status %d: %s", i+1, w.Code, w.Body.String())
+				}
+			}
+
+			// Cada mutación sí consume el límite de escrituras
+			w := alice.do(http.MethodPost, "/graphql", map[string]any{"query": graphQLMutation})
+			if strings.Contains(w.Body.String(), "errors") {
+				t.Fatalf("primera mutación: %s", w.Body.String())
+			}
+			w = alice.do(http.MethodPost, "/graphql", map[string]any{"query": graphQLMutation})
+			if !strings.Contains(w.Body.String(), "rate_limited") || w.Header().Get("RateLimit-Remaining") != "0" {
+				t.Errorf("segunda mutación: RateLimit-Remaining %q: %s, se esperaba rate_limited",
+					w.Header().Get("RateLimit-Remaining"), w.Body.String())
+			}
+		})
+	}
+}
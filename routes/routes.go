@@ -1,52 +1,179 @@
 package routes
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"todo-list/collab"
+	"todo-list/config"
+	"todo-list/events"
+	"todo-list/gql"
 	"todo-list/handlers"
+	"todo-list/idempotency"
+	"todo-list/models"
+	"todo-list/openapi"
+	"todo-list/store"
+	"todo-list/webhooks"
 
 	"github.com/gorilla/mux"
 )
 
-// SetupRoutes configura todas las rutas de la aplicación
-func SetupRoutes() *mux.Router {
+// SetupRoutes configura todas las rutas de la aplicación. También retorna la API gRPC sobre el mismo
+// store, que el llamador inicia con Start y detiene con Stop
+func SetupRoutes() (*mux.Router, *GRPCServer) {
 	router := mux.NewRouter()
 	
-	// Crear instancia del handler
-	todoHandler := handlers.NewTodoHandler()
+	// Crear el store compartido y la instancia del handler
+	cfg := config.Load()
+	todoStore := store.NewTodoStore()
+	todoStore.StartAutoArchive(context.Background(), store.ArchivePolicy{
+		After:    cfg.ArchiveAfter,
+		Interval: cfg.ArchiveInterval,
+	})
+	broker := events.NewBroker(cfg.EventHistory, cfg.EventHeartbeat)
+	todoStore.SetPublisher(broker)
+	cors := newCORSPolicy(cfg)
+	hub := collab.NewHub(todoStore, broker, cfg.CollabLockTTL, cors.allowsWebSocket)
+	hub.Start(context.Background())
+	webhookStore := store.NewWebhookStore()
+	if !cfg.WebhookAllowPrivate {
+		webhookStore.SetTargetChecker(webhooks.CheckTarget)
+	}
+	dispatcher := webhooks.NewDispatcher(webhookStore, broker, webhooks.Policy{
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		BaseDelay:    cfg.WebhookRetryBase,
+		MaxDelay:     cfg.WebhookRetryMax,
+		Timeout:      cfg.WebhookTimeout,
+		AllowPrivate: cfg.WebhookAllowPrivate,
+	})
+	dispatcher.Start(context.Background())
+	userStore := store.NewUserStore(cfg.SessionTTL)
+	sso := newSSO(cfg, userStore)
+	workspaces := newWorkspaces(cfg)
+	graphqlHandler := gql.NewHandler(todoStore, broker, cfg.RequireIfMatch, cors.allowsWebSocket)
+	grpcServer := newGRPCServer(cfg, todoStore, broker, userStore, sso, workspaces)
+	todoHandler := handlers.NewTodoHandler(todoStore)
+	webhookHandler := handlers.NewWebhookHandler(webhookStore)
+	authHandler := handlers.NewAuthHandler(userStore, cfg.SessionSecure)
+	tokenHandler := handlers.NewTokenHandler(userStore)
+	idempotencyCache := idempotency.NewCache(cfg.IdempotencyTTL)
+	limits := newRateLimits(cfg)
 	
 	// Middleware para logging
 	router.Use(loggingMiddleware)
 	
+	// Middleware para convertir pánicos en errores 500
+	router.Use(recoveryMiddleware)
+	
 	// Middleware para CORS
-	router.Use(corsMiddleware)
+	router.Use(corsMiddleware(cors))
+	
+	// Tamaño máximo del cuerpo de las peticiones
+	router.Use(maxBody(cfg.MaxBodyBytes))
+	
+	// Rutas públicas de la API: health check y documentación
+	public := router.PathPrefix("/api/v1").Subrouter()
+	public.HandleFunc("/health", healthCheck).Methods("GET")
+	public.HandleFunc("/openapi.json", openapi.ServeSpec).Methods("GET")
+	public.HandleFunc("/docs", openapi.ServeViewer).Methods("GET")
 	
-	// Rutas de la API
-	api := router.PathPrefix("/api/v1").Subrouter()
+	// Las cuentas y los datos viven en un workspace, que se indica con el
+	// header X-Workspace o el subdominio
+	tenant := public.NewRoute().Subrouter()
+	tenant.Use(requireWorkspace(workspaces))
+	// El registro y el inicio de sesión se limitan por IP contra la fuerza bruta
+	authLimit := rateLimit(limits.auth, limits.trustProxy)
+	tenant.Handle("/auth/register", authLimit(http.HandlerFunc(authHandler.Register))).Methods("POST")
+	tenant.Handle("/auth/login", authLimit(http.HandlerFunc(authHandler.Login))).Methods("POST")
+	tenant.Handle("/auth/logout", authLimit(http.HandlerFunc(authHandler.Logout))).Methods("POST")
+	
+	// El resto de la API exige sesión o token personal y solo ve los datos del usuario
+	api := tenant.NewRoute().Subrouter()
+	api.Use(requireSession(userStore, sso))
+	api.Use(apiRateLimit(limits))
+	api.HandleFunc("/auth/me", authHandler.Me).Methods("GET")
+	api.HandleFunc("/workspace", todoHandler.GetWorkspace).Methods("GET")
+	
+	// Tokens personales; solo se administran con la sesión iniciada
+	api.HandleFunc("/tokens", sessionOnly(tokenHandler.GetAllTokens)).Methods("GET")
+	api.HandleFunc("/tokens", sessionOnly(tokenHandler.CreateToken)).Methods("POST")
+	api.HandleFunc("/tokens/{id}", sessionOnly(tokenHandler.RevokeToken)).Methods("DELETE")
 	
 	// Rutas de todos
 	api.HandleFunc("/todos", todoHandler.GetAllTodos).Methods("GET")
-	api.HandleFunc("/todos", todoHandler.CreateTodo).Methods("POST")
+	once := idempotent(idempotencyCache)
+	api.HandleFunc("/todos", once(todoHandler.CreateTodo)).Methods("POST")
+	api.HandleFunc("/todos/batch", once(todoHandler.BatchTodos)).Methods("POST")
 	api.HandleFunc("/todos/{id}", todoHandler.GetTodoByID).Methods("GET")
-	api.HandleFunc("/todos/{id}", todoHandler.UpdateTodo).Methods("PUT")
-	api.HandleFunc("/todos/{id}", todoHandler.DeleteTodo).Methods("DELETE")
+	api.HandleFunc("/todos/{id}/history", todoHandler.GetTodoHistory).Methods("GET")
+	ifMatch := requireIfMatch(cfg.RequireIfMatch)
+	api.HandleFunc("/todos/{id}", ifMatch(todoHandler.UpdateTodo)).Methods("PUT")
+	api.HandleFunc("/todos/{id}", ifMatch(todoHandler.PatchTodo)).Methods("PATCH")
+	api.HandleFunc("/todos/{id}", ifMatch(todoHandler.DeleteTodo)).Methods("DELETE")
+	
+	// Rutas del archivo
+	api.HandleFunc("/archive", todoHandler.GetArchivedTodos).Methods("GET")
+	api.HandleFunc("/todos/{id}/archive", todoHandler.ArchiveTodo).Methods("POST")
+	api.HandleFunc("/todos/{id}/unarchive", todoHandler.UnarchiveTodo).Methods("POST")
+	
+	// Rutas de plantillas
+	api.HandleFunc("/templates", todoHandler.GetAllTemplates).Methods("GET")
+	api.HandleFunc("/templates", todoHandler.CreateTemplate).Methods("POST")
+	api.HandleFunc("/templates/{id}", todoHandler.GetTemplateByID).Methods("GET")
+	api.HandleFunc("/templates/{id}", todoHandler.UpdateTemplate).Methods("PUT")
+	api.HandleFunc("/templates/{id}", todoHandler.DeleteTemplate).Methods("DELETE")
+	api.HandleFunc("/templates/{id}/instantiate", once(todoHandler.InstantiateTemplate)).Methods("POST")
+	
+	// Rutas de listas compartidas, sus miembros e invitaciones
+	api.HandleFunc("/lists", todoHandler.GetAllLists).Methods("GET")
+	api.HandleFunc("/lists", todoHandler.CreateList).Methods("POST")
+	api.HandleFunc("/lists/{id}", todoHandler.GetListByID).Methods("GET")
+	api.HandleFunc("/lists/{id}", todoHandler.UpdateList).Methods("PUT")
+	api.HandleFunc("/lists/{id}", todoHandler.DeleteList).Methods("DELETE")
+	api.HandleFunc("/lists/{id}/invitations", todoHandler.InviteToList).Methods("POST")
+	api.HandleFunc("/lists/{id}/members/{userID}", todoHandler.UpdateListMember).Methods("PUT")
+	api.HandleFunc("/lists/{id}/members/{userID}", todoHandler.RemoveListMember).Methods("DELETE")
+	api.HandleFunc("/invitations", todoHandler.GetInvitations).Methods("GET")
+	api.HandleFunc("/invitations/{id}/accept", todoHandler.AcceptInvitation).Methods("POST")
+	api.HandleFunc("/invitations/{id}", todoHandler.DeleteInvitation).Methods("DELETE")
 	
-	// Ruta de health check
-	api.HandleFunc("/health", healthCheck).Methods("GET")
+	// Feed de cambios en tiempo real (Server-Sent Events)
+	api.Handle("/events", broker).Methods("GET")
+	
+	// Canal de colaboración: cambios, presencia y bloqueos de edición (WebSocket)
+	api.Handle("/ws", hub).Methods("GET")
+	
+	// GraphQL: consultas y mutaciones por POST, suscripciones por WebSocket
+	api.Handle("/graphql", graphqlHandler).Methods("GET", "POST")
+	api.HandleFunc("/graphql/schema", gql.ServeSchema).Methods("GET")
+	
+	// Rutas de webhooks y su registro de entregas; las rutas fijas van antes
+	// que /webhooks/{id} para que mux no las tome como un ID
+	api.HandleFunc("/webhooks", webhookHandler.GetAllWebhooks).Methods("GET")
+	api.HandleFunc("/webhooks", webhookHandler.CreateWebhook).Methods("POST")
+	api.HandleFunc("/webhooks/dead-letters", webhookHandler.GetDeadLetters).Methods("GET")
+	api.HandleFunc("/webhooks/deliveries/{id}/redeliver", webhookHandler.RedeliverDelivery).Methods("POST")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.GetWebhookByID).Methods("GET")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.UpdateWebhook).Methods("PUT")
+	api.HandleFunc("/webhooks/{id}", webhookHandler.DeleteWebhook).Methods("DELETE")
+	api.HandleFunc("/webhooks/{id}/deliveries", webhookHandler.GetDeliveries).Methods("GET")
+	
+	// Ruta de estadísticas
+	api.HandleFunc("/stats", todoHandler.GetStats).Methods("GET")
 	
 	// Servir archivos estáticos de la página web
 	router.PathPrefix("/").Handler(http.HandlerFunc(serveWebFiles))
 	
-	return router
+	return router, grpcServer
 }
 
 // serveWebFiles sirve los archivos estáticos de la página web
 func serveWebFiles(w http.ResponseWriter, r *http.Request) {
 	// Si es una petición a la API, no servir archivos estáticos
 	if strings.HasPrefix(r.URL.Path, "/api/") {
-		http.NotFound(w, r)
+		handlers.WriteProblem(w, r, routeNotFoundProblem())
 		return
 	}
 	
@@ -123,20 +250,26 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// corsMiddleware maneja CORS
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+// requireIfMatch exige el header If-Match en escrituras cuando required es true
+func requireIfMatch(required bool) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if !required {
+			return next
 		}
-		
-		next.ServeHTTP(w, r)
-	})
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-Match") == "" {
+				handlers.WriteProblem(w, r, ifMatchRequiredProblem())
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// ifMatchRequiredProblem se usa cuando se exige If-Match y no viene
+func ifMatchRequiredProblem() models.Problem {
+	return models.NewProblem(http.StatusPreconditionRequired, models.CodeIfMatchRequired,
+		"Se requiere el header If-Match con el ETag del todo")
 }
 
 // healthCheck verifica el estado de la aplicación
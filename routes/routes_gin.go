@@ -1,31 +1,36 @@
 package routes
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"todo-list/collab"
+	"todo-list/config"
+	"todo-list/events"
+	"todo-list/gql"
 	"todo-list/handlers"
-	"github.com/gin-contrib/cors"
+	"todo-list/idempotency"
+	"todo-list/openapi"
+	"todo-list/store"
+	"todo-list/webhooks"
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRoutesGin configura todas las rutas usando Gin framework
-func SetupRoutesGin() *gin.Engine {
+// SetupRoutesGin configura todas las rutas usando Gin framework. También retorna la API gRPC sobre el mismo
+// store, que el llamador inicia con Start y detiene con Stop
+func SetupRoutesGin() (*gin.Engine, *GRPCServer) {
 	// Configurar Gin en modo release para producción
 	// gin.SetMode(gin.ReleaseMode)
 	
 	// Crear router de Gin
 	router := gin.Default()
 	
-	// Middleware de CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	// Middleware de CORS según la configuración del entorno
+	cfg := config.Load()
+	cors := newCORSPolicy(cfg)
+	router.Use(corsGin(cors))
 	
 	// Middleware de logging personalizado
 	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -40,36 +45,165 @@ func SetupRoutesGin() *gin.Engine {
 	}))
 	
 	// Middleware de recuperación
-	router.Use(gin.Recovery())
+	router.Use(gin.CustomRecovery(recoveryGin))
+	
+	// Tamaño máximo del cuerpo de las peticiones
+	router.Use(maxBodyGin(cfg.MaxBodyBytes))
+	
+	// Crear el store compartido y la instancia del handler
+	todoStore := store.NewTodoStore()
+	todoStore.StartAutoArchive(context.Background(), store.ArchivePolicy{
+		After:    cfg.ArchiveAfter,
+		Interval: cfg.ArchiveInterval,
+	})
+	broker := events.NewBroker(cfg.EventHistory, cfg.EventHeartbeat)
+	todoStore.SetPublisher(broker)
+	hub := collab.NewHub(todoStore, broker, cfg.CollabLockTTL, cors.allowsWebSocket)
+	hub.Start(context.Background())
+	webhookStore := store.NewWebhookStore()
+	if !cfg.WebhookAllowPrivate {
+		webhookStore.SetTargetChecker(webhooks.CheckTarget)
+	}
+	dispatcher := webhooks.NewDispatcher(webhookStore, broker, webhooks.Policy{
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		BaseDelay:    cfg.WebhookRetryBase,
+		MaxDelay:     cfg.WebhookRetryMax,
+		Timeout:      cfg.WebhookTimeout,
+		AllowPrivate: cfg.WebhookAllowPrivate,
+	})
+	dispatcher.Start(context.Background())
+	userStore := store.NewUserStore(cfg.SessionTTL)
+	sso := newSSO(cfg, userStore)
+	workspaces := newWorkspaces(cfg)
+	graphqlHandler := gql.NewHandler(todoStore, broker, cfg.RequireIfMatch, cors.allowsWebSocket)
+	grpcServer := newGRPCServer(cfg, todoStore, broker, userStore, sso, workspaces)
+	todoHandler := handlers.NewTodoHandlerGin(todoStore)
+	webhookHandler := handlers.NewWebhookHandlerGin(webhookStore)
+	authHandler := handlers.NewAuthHandlerGin(userStore, cfg.SessionSecure)
+	tokenHandler := handlers.NewTokenHandlerGin(userStore)
+	idempotencyCache := idempotency.NewCache(cfg.IdempotencyTTL)
+	limits := newRateLimits(cfg)
 	
-	// Crear instancia del handler
-	todoHandler := handlers.NewTodoHandlerGin()
+	// Rutas públicas: health check y documentación
+	public := router.Group("/api/v1")
+	{
+		public.GET("/health", todoHandler.HealthCheck)
+		public.GET("/openapi.json", gin.WrapF(openapi.ServeSpec))
+		public.GET("/docs", gin.WrapF(openapi.ServeViewer))
+	}
 	
-	// Grupo de rutas para la API
-	api := router.Group("/api/v1")
+	// Las cuentas y los datos viven en un workspace, que se indica con el
+	// header X-Workspace o el subdominio
+	tenant := router.Group("/api/v1", requireWorkspaceGin(workspaces))
 	{
+		// El registro y el inicio de sesión se limitan por IP contra la fuerza bruta
+		authLimit := rateLimitGin(limits.auth, limits.trustProxy)
+		tenant.POST("/auth/register", authLimit, authHandler.Register)
+		tenant.POST("/auth/login", authLimit, authHandler.Login)
+		tenant.POST("/auth/logout", authLimit, authHandler.Logout)
+	}
+	
+	// El resto de la API exige sesión o token personal y solo ve los datos del usuario
+	api := tenant.Group("", requireSessionGin(userStore, sso),
+		apiRateLimitGin(limits))
+	{
+		api.GET("/auth/me", authHandler.Me)
+		api.GET("/workspace", todoHandler.GetWorkspace)
+		
+		// Tokens personales; solo se administran con la sesión iniciada
+		api.GET("/tokens", sessionOnlyGin, tokenHandler.GetAllTokens)
+		api.POST("/tokens", sessionOnlyGin, tokenHandler.CreateToken)
+		api.DELETE("/tokens/:id", sessionOnlyGin, tokenHandler.RevokeToken)
+		
 		// Rutas de todos
 		api.GET("/todos", todoHandler.GetAllTodos)
-		api.POST("/todos", todoHandler.CreateTodo)
+		once := idempotentGin(idempotencyCache)
+		api.POST("/todos", once, todoHandler.CreateTodo)
+		api.POST("/todos/batch", once, todoHandler.BatchTodos)
 		api.GET("/todos/:id", todoHandler.GetTodoByID)
-		api.PUT("/todos/:id", todoHandler.UpdateTodo)
-		api.DELETE("/todos/:id", todoHandler.DeleteTodo)
+		api.GET("/todos/:id/history", todoHandler.GetTodoHistory)
+		ifMatch := requireIfMatchGin(cfg.RequireIfMatch)
+		api.PUT("/todos/:id", ifMatch, todoHandler.UpdateTodo)
+		api.PATCH("/todos/:id", ifMatch, todoHandler.PatchTodo)
+		api.DELETE("/todos/:id", ifMatch, todoHandler.DeleteTodo)
 		
-		// Ruta de health check
-		api.GET("/health", todoHandler.HealthCheck)
+		// Rutas del archivo
+		api.GET("/archive", todoHandler.GetArchivedTodos)
+		api.POST("/todos/:id/archive", todoHandler.ArchiveTodo)
+		api.POST("/todos/:id/unarchive", todoHandler.UnarchiveTodo)
+		
+		// Rutas de plantillas
+		api.GET("/templates", todoHandler.GetAllTemplates)
+		api.POST("/templates", todoHandler.CreateTemplate)
+		api.GET("/templates/:id", todoHandler.GetTemplateByID)
+		api.PUT("/templates/:id", todoHandler.UpdateTemplate)
+		api.DELETE("/templates/:id", todoHandler.DeleteTemplate)
+		api.POST("/templates/:id/instantiate", once, todoHandler.InstantiateTemplate)
+		
+		// Rutas de listas compartidas, sus miembros e invitaciones
+		api.GET("/lists", todoHandler.GetAllLists)
+		api.POST("/lists", todoHandler.CreateList)
+		api.GET("/lists/:id", todoHandler.GetListByID)
+		api.PUT("/lists/:id", todoHandler.UpdateList)
+		api.DELETE("/lists/:id", todoHandler.DeleteList)
+		api.POST("/lists/:id/invitations", todoHandler.InviteToList)
+		api.PUT("/lists/:id/members/:userID", todoHandler.UpdateListMember)
+		api.DELETE("/lists/:id/members/:userID", todoHandler.RemoveListMember)
+		api.GET("/invitations", todoHandler.GetInvitations)
+		api.POST("/invitations/:id/accept", todoHandler.AcceptInvitation)
+		api.DELETE("/invitations/:id", todoHandler.DeleteInvitation)
+		
+		// Feed de cambios en tiempo real (Server-Sent Events)
+		api.GET("/events", gin.WrapH(broker))
+		
+		// Canal de colaboración: cambios, presencia y bloqueos de edición (WebSocket)
+		api.GET("/ws", gin.WrapH(hub))
+		
+		// GraphQL: consultas y mutaciones por POST, suscripciones por WebSocket
+		api.POST("/graphql", gin.WrapH(graphqlHandler))
+		api.GET("/graphql", gin.WrapH(graphqlHandler))
+		api.GET("/graphql/schema", gin.WrapF(gql.ServeSchema))
+		
+		// Rutas de webhooks y su registro de entregas
+		api.GET("/webhooks", webhookHandler.GetAllWebhooks)
+		api.POST("/webhooks", webhookHandler.CreateWebhook)
+		api.GET("/webhooks/dead-letters", webhookHandler.GetDeadLetters)
+		api.POST("/webhooks/deliveries/:id/redeliver", webhookHandler.RedeliverDelivery)
+		api.GET("/webhooks/:id", webhookHandler.GetWebhookByID)
+		api.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+		api.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+		
+		// Ruta de estadísticas
+		api.GET("/stats", todoHandler.GetStats)
 	}
 	
+	// Responder 405 cuando la ruta existe con otro método
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(noMethodGin)
+	
 	// Servir archivos estáticos de la página web
 	router.NoRoute(gin.WrapF(serveWebFilesGin))
 	
-	return router
+	return router, grpcServer
+}
+
+// requireIfMatchGin exige el header If-Match en escrituras cuando required es true
+func requireIfMatchGin(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if required && c.GetHeader("If-Match") == "" {
+			handlers.AbortWithProblem(c, ifMatchRequiredProblem())
+			return
+		}
+		c.Next()
+	}
 }
 
 // serveWebFilesGin sirve los archivos estáticos de la página web
 func serveWebFilesGin(w http.ResponseWriter, r *http.Request) {
 	// Si es una petición a la API, no servir archivos estáticos
 	if strings.HasPrefix(r.URL.Path, "/api/") {
-		http.NotFound(w, r)
+		handlers.WriteProblem(w, r, routeNotFoundProblem())
 		return
 	}
 	
@@ -1,10 +1,13 @@
 package routes
 
 import (
+	"context"
 	"fmt"
+	"todo-list/config"
+	"todo-list/events"
 	"todo-list/handlers"
+	"todo-list/store"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,14 +19,10 @@ func SetupRoutesTempl() *gin.Engine {
 	// Crear router de Gin
 	router := gin.Default()
 	
-	// Middleware de CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "HX-Request"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	// Middleware de CORS según la configuración del entorno
+	cfg := config.Load()
+	cors := newCORSPolicy(cfg)
+	router.Use(corsGin(cors))
 	
 	// Middleware de logging personalizado
 	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
@@ -38,19 +37,70 @@ func SetupRoutesTempl() *gin.Engine {
 	}))
 	
 	// Middleware de recuperación
-	router.Use(gin.Recovery())
+	router.Use(gin.CustomRecovery(recoveryGin))
+	
+	// Tamaño máximo del cuerpo de las peticiones
+	router.Use(maxBodyGin(cfg.MaxBodyBytes))
+	
+	// Content-Security-Policy y protección CSRF de las páginas
+	router.Use(securityHeadersGin())
+	router.Use(csrfGin(cfg.SessionSecure))
 	
-	// Crear instancia del handler
-	todoHandler := handlers.NewTodoHandlerTempl()
+	// Crear el store compartido y la instancia del handler
+	todoStore := store.NewTodoStore()
+	todoStore.StartAutoArchive(context.Background(), store.ArchivePolicy{
+		After:    cfg.ArchiveAfter,
+		Interval: cfg.ArchiveInterval,
+	})
+	broker := events.NewBroker(cfg.EventHistory, cfg.EventHeartbeat)
+	todoStore.SetPublisher(broker)
+	userStore := store.NewUserStore(cfg.SessionTTL)
+	sso := newSSO(cfg, userStore)
+	workspaces := newWorkspaces(cfg)
+	todoHandler := handlers.NewTodoHandlerTempl(todoStore)
+	authHandler := handlers.NewAuthHandlerTempl(userStore, sso, cfg.SessionSecure)
+	limits := newRateLimits(cfg)
 	
 	// Servir archivos estáticos
 	router.Static("/static", "./web")
 	
+	// Ruta de health check
+	router.GET("/api/health", todoHandler.HealthCheck)
+	
+	// Las cuentas y las tareas viven en el workspace del subdominio o del
+	// header X-Workspace
+	tenant := router.Group("/", requireWorkspaceGin(workspaces))
+	
+	// Páginas de inicio de sesión y registro (públicas); los envíos se
+	// limitan por IP contra la fuerza bruta
+	authLimit := rateLimitGin(limits.auth, limits.trustProxy)
+	tenant.GET("/login", authHandler.GetLoginPage)
+	tenant.POST("/login", authLimit, authHandler.Login)
+	tenant.GET("/register", authHandler.GetRegisterPage)
+	tenant.POST("/register", authLimit, authHandler.Register)
+	tenant.POST("/logout", authLimit, authHandler.Logout)
+	
+	// Inicio de sesión único con el proveedor OIDC, si está configurado
+	if sso.Enabled() {
+		tenant.GET("/auth/oidc/login", authHandler.SSOLogin)
+		tenant.GET("/auth/oidc/callback", authHandler.SSOCallback)
+	}
+	
+	// El resto de las páginas exige sesión y solo muestra las tareas del usuario
+	private := tenant.Group("/", requireSessionTempl(userStore),
+		apiRateLimitGin(limits))
+	
 	// Ruta principal - página del todo list
-	router.GET("/", todoHandler.GetHomePage)
+	private.GET("/", todoHandler.GetHomePage)
+	
+	// Página de tareas archivadas
+	private.GET("/archive", todoHandler.GetArchivePage)
+	
+	// Página de listas compartidas
+	private.GET("/lists", todoHandler.GetListsPage)
 	
 	// Grupo de rutas para la API (HTMX)
-	api := router.Group("/api")
+	api := private.Group("/api")
 	{
 		// Rutas de todos para HTMX
 		api.GET("/todos", todoHandler.GetAllTodos)
@@ -59,16 +109,43 @@ func SetupRoutesTempl() *gin.Engine {
 		api.PUT("/todos/:id", todoHandler.UpdateTodo)
 		api.DELETE("/todos/:id", todoHandler.DeleteTodo)
 		
+		// Rutas del archivo
+		api.GET("/archive", todoHandler.GetArchivedTodos)
+		api.POST("/todos/:id/archive", todoHandler.ArchiveTodo)
+		api.POST("/todos/:id/unarchive", todoHandler.UnarchiveTodo)
+		
 		// Endpoint flexible que acepta JSON y Form Data
 		api.POST("/todos/flexible", todoHandler.CreateTodoFlexible)
 		
+		// Rutas de plantillas
+		api.GET("/templates", todoHandler.GetAllTemplates)
+		api.POST("/templates", todoHandler.CreateTemplate)
+		api.GET("/templates/form", todoHandler.GetTemplateForm)
+		api.DELETE("/templates/:id", todoHandler.DeleteTemplate)
+		api.POST("/templates/:id/instantiate", todoHandler.InstantiateTemplate)
+		
+		// Rutas de listas compartidas; responden con el panel actualizado
+		api.POST("/lists", todoHandler.CreateList)
+		api.DELETE("/lists/:id", todoHandler.DeleteList)
+		api.POST("/lists/:id/invitations", todoHandler.InviteToList)
+		api.PUT("/lists/:id/members/:userID", todoHandler.UpdateListMember)
+		api.DELETE("/lists/:id/members/:userID", todoHandler.RemoveListMember)
+		api.POST("/invitations/:id/accept", todoHandler.AcceptInvitation)
+		api.DELETE("/invitations/:id", todoHandler.DeleteInvitation)
+		
+		// Estadísticas y feed de cambios en tiempo real (hx-ext sse)
+		api.GET("/stats", todoHandler.GetStats)
+		api.GET("/events", gin.WrapH(broker))
+		
 		// Rutas para modales
 		api.GET("/todos/:id/edit", todoHandler.GetEditModal)
 		api.GET("/close-modal", todoHandler.CloseModal)
-		
-		// Ruta de health check
-		api.GET("/health", todoHandler.HealthCheck)
 	}
 	
+	// Errores de ruteo en formato problem+json
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(noMethodGin)
+	router.NoRoute(noRouteGin)
+	
 	return router
 }
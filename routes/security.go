@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"todo-list/handlers"
+	"todo-list/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contentSecurityPolicy es la política de las páginas HTMX: solo scripts
+// propios y los de htmx (sin inline ni eval), estilos y fuentes propios y de
+// Font Awesome, y ningún sitio puede mostrarlas en un frame
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' https://unpkg.com/htmx.org@1.9.10/; " +
+	"style-src 'self' https://cdnjs.cloudflare.com; " +
+	"font-src 'self' https://cdnjs.cloudflare.com; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'none'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// Nombres con los que viaja el token CSRF: htmx lo envía en el header y los
+// formularios sin htmx, en el campo
+const (
+	csrfHeader      = "X-CSRF-Token"
+	csrfField       = "csrf_token"
+	csrfTokenLength = 64
+)
+
+// securityHeadersGin agrega a las respuestas de las páginas HTMX la
+// Content-Security-Policy y los headers que evitan el sniffing de tipos
+func securityHeadersGin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", contentSecurityPolicy)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Referrer-Policy", "same-origin")
+		c.Next()
+	}
+}
+
+// csrfGin protege las páginas HTMX con el patrón double-submit: cada
+// navegador recibe un token aleatorio en una cookie HttpOnly y las páginas lo
+// repiten en un <meta> y en sus formularios. Toda petición que cambia datos
+// debe devolverlo en el header X-CSRF-Token o en el campo csrf_token; otro
+// sitio puede hacer que el navegador envíe la cookie, pero no leerla. Con
+// secure la cookie usa el prefijo __Host-, que un subdominio no puede pisar
+func csrfGin(secure bool) gin.HandlerFunc {
+	name := "csrf"
+	if secure {
+		name = "__Host-csrf"
+	}
+	return func(c *gin.Context) {
+		token, err := c.Cookie(name)
+		if err != nil || len(token) != csrfTokenLength {
+			token, err = newCSRFToken()
+			if err != nil {
+				handlers.AbortWithProblem(c, internalProblem())
+				return
+			}
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     name,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   secure,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		c.Set(handlers.CSRFKey, token)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		sent := c.GetHeader(csrfHeader)
+		if sent == "" {
+			sent = c.PostForm(csrfField)
+		}
+		if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			handlers.AbortWithProblem(c, csrfProblem())
+			return
+		}
+		c.Next()
+	}
+}
+
+// newCSRFToken genera un token CSRF aleatorio
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// csrfProblem se usa cuando una petición que cambia datos no trae el token
+// CSRF de la página
+func csrfProblem() models.Problem {
+	return models.NewProblem(http.StatusForbidden, models.CodeCSRFFailed,
+		"Falta el token CSRF o no coincide; recarga la página e inténtalo de nuevo")
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInstantiateValidatesTheSubstitutedTodos(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			alice := register(t, setup(), "acme", "alice@example.com", "Alice")
+			id := alice.createID("/templates", map[string]any{
+				"name": "Onboarding",
+				"items": []map[string]any{
+					{"title": "Bienvenida"},
+					{"title": "Crear cuenta para {{name}}", "tags": []string{"{{team}}"}},
+				},
+			})
+			path := fmt.Sprintf("/templates/%d/instantiate", id)
+
+			w := alice.do(http.MethodPost, path, map[string]any{"values": map[string]string{
+				"name": strings.Repeat("a", 200), "team": "soporte",
+			}})
+			if w.Code != http.StatusBadRequest || problemCode(w) != "invalid_placeholder_values" ||
+				!strings.Contains(w.Body.String(), `"field":"values.name"`) {
+				t.Fatalf("un título demasiado largo: status %d: %s", w.Code, w.Body.String())
+			}
+
+			// Nada se creó, ni siquiera el item válido
+			var todos []struct{}
+			alice.mustData(http.MethodGet, "/todos", nil, http.StatusOK, &todos)
+			if len(todos) != 0 {
+				t.Fatalf("la instanciación fallida creó %d todos", len(todos))
+			}
+
+			alice.mustData(http.MethodPost, path, map[string]any{"values": map[string]string{
+				"name": "Ana", "team": "soporte",
+			}}, http.StatusCreated, nil)
+		})
+	}
+}
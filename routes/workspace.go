@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-list/config"
+	"todo-list/handlers"
+	"todo-list/models"
+	"todo-list/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceHeader es el header con el que una petición indica su workspace;
+// tiene prioridad sobre el subdominio
+const workspaceHeader = "X-Workspace"
+
+// newWorkspaces crea el registro de workspaces con la configuración del
+// entorno. Cada entrada de WORKSPACES es "slug" o "slug=límite"; un límite
+// inválido se reemplaza por WORKSPACE_MAX_TODOS
+func newWorkspaces(cfg config.Config) *store.Workspaces {
+	list := make([]models.Workspace, 0, len(cfg.Workspaces))
+	for _, entry := range cfg.Workspaces {
+		slug, limit, hasLimit := strings.Cut(entry, "=")
+		slug = strings.TrimSpace(slug)
+		if slug == "" {
+			continue
+		}
+		workspace := models.Workspace{Slug: slug, MaxTodos: cfg.WorkspaceMaxTodos}
+		if hasLimit {
+			n, err := strconv.Atoi(strings.TrimSpace(limit))
+			if err != nil || n < 0 {
+				log.Printf("⚠️  Límite inválido para el workspace %s (%q), usando %d", slug, limit, cfg.WorkspaceMaxTodos)
+			} else {
+				workspace.MaxTodos = n
+			}
+		}
+		list = append(list, workspace)
+	}
+	return store.NewWorkspaces(list, cfg.WorkspaceDomain, cfg.WorkspaceDefault)
+}
+
+// resolveWorkspace obtiene el contexto con el workspace de la petición: el
+// del header X-Workspace, el del subdominio o el workspace por defecto
+func resolveWorkspace(r *http.Request, workspaces *store.Workspaces) (context.Context, error) {
+	workspace, err := workspaces.Resolve(r.Header.Get(workspaceHeader), r.Host)
+	if err != nil {
+		return nil, err
+	}
+	return store.WithWorkspace(r.Context(), workspace), nil
+}
+
+// requireWorkspace deja el workspace de la petición en el contexto, que es lo
+// que usan los stores para separar los datos de cada equipo. Responde 404 si
+// la petición no indica un workspace configurado
+func requireWorkspace(workspaces *store.Workspaces) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, err := resolveWorkspace(r, workspaces)
+			if err != nil {
+				handlers.WriteProblem(w, r, handlers.ProblemFromError(err))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requireWorkspaceGin deja el workspace de la petición en el contexto
+func requireWorkspaceGin(workspaces *store.Workspaces) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := resolveWorkspace(c.Request, workspaces)
+		if err != nil {
+			handlers.AbortWithProblem(c, handlers.ProblemFromError(err))
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,573 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// acmeSecret marca todo lo que crea la cuenta de acme; ninguna respuesta a
+// una cuenta de otro workspace puede contenerlo
+const acmeSecret = "acme-secreto"
+
+// tenantClient hace peticiones a un router en nombre de una cuenta de un
+// workspace
+type tenantClient struct {
+	t         *testing.T
+	router    http.Handler
+	prefix    string
+	workspace string
+	cookie    *http.Cookie
+	csrf      *http.Cookie
+	token     string
+	userID    int
+}
+
+// do envía la petición; body puede ser nil, url.Values (formulario) o
+// cualquier valor que se codifica como JSON. Las peticiones tienen un plazo
+// corto para que los streams como /events terminen
+func (c *tenantClient) do(method, path string, body any) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	var reader *bytes.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case url.Values:
+		reader = bytes.NewReader([]byte(b.Encode()))
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			c.t.Fatalf("codificar body: %v", err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(method, c.prefix+path, reader).WithContext(ctx)
+	req.Header.Set("Content-Type", contentType)
+	if c.workspace != "" {
+		req.Header.Set(workspaceHeader, c.workspace)
+	}
+	if c.cookie != nil {
+		req.AddCookie(c.cookie)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.csrf != nil {
+		req.AddCookie(c.csrf)
+		req.Header.Set(csrfHeader, c.csrf.Value)
+	}
+	w := httptest.NewRecorder()
+	c.router.ServeHTTP(w, req)
+	return w
+}
+
+// as retorna una copia del cliente que habla con otro workspace
+func (c *tenantClient) as(workspace string) *tenantClient {
+	other := *c
+	other.workspace = workspace
+	return &other
+}
+
+// mustData hace la petición, exige el status indicado y decodifica el campo
+// data de la respuesta en out
+func (c *tenantClient) mustData(method, path string, body any, status int, out any) {
+	c.t.Helper()
+
+	w := c.do(method, path, body)
+	if w.Code != status {
+		c.t.Fatalf("%s %s: status %d, se esperaba %d: %s", method, path, w.Code, status, w.Body.String())
+	}
+	if out == nil {
+		return
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		c.t.Fatalf("%s %s: respuesta inválida: %v", method, path, err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		c.t.Fatalf("%s %s: data inválida: %v", method, path, err)
+	}
+}
+
+// createID crea un recurso y retorna su ID
+func (c *tenantClient) createID(path string, body any) int {
+	c.t.Helper()
+
+	var created struct {
+		ID int `json:"id"`
+	}
+	c.mustData(http.MethodPost, path, body, http.StatusCreated, &created)
+	return created.ID
+}
+
+// problemCode obtiene el code de una respuesta problem+json
+func problemCode(w *httptest.ResponseRecorder) string {
+	var problem struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &problem)
+	return problem.Code
+}
+
+// register crea una cuenta en el workspace y retorna su cliente con la
+// cookie de sesión
+func register(t *testing.T, router http.Handler, workspace, email, name string) *tenantClient {
+	t.Helper()
+
+	c := &tenantClient{t: t, router: router, prefix: apiPrefix, workspace: workspace}
+	w := c.do(http.MethodPost, "/auth/register", map[string]string{
+		"email": email, "name": name, "password": "contraseña-segura",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("registrar %s en %s: status %d: %s", email, workspace, w.Code, w.Body.String())
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "session" {
+			c.cookie = cookie
+		}
+	}
+	if c.cookie == nil {
+		t.Fatalf("registrar %s en %s: no se recibió la cookie de sesión", email, workspace)
+	}
+	var user struct {
+		ID int `json:"id"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &struct {
+		Data any `json:"data"`
+	}{Data: &user})
+	c.userID = user.ID
+	return c
+}
+
+// acmeData son los IDs de los recursos que crea la cuenta de acme
+type acmeData struct {
+	todo, archived, template, list, invitation, webhook, delivery, token int
+	tokenValue, invitationToken                                          string
+}
+
+// seedAcme crea en acme un recurso de cada tipo con el marcador acmeSecret;
+// la invitación es para el email de la cuenta de globex
+func seedAcme(t *testing.T, alice *tenantClient) acmeData {
+	t.Helper()
+
+	var data acmeData
+	data.webhook = alice.createID("/webhooks", map[string]any{
+		"url": "http://127.0.0.1:1/" + acmeSecret, "events": []string{"todo.created"},
+	})
+	data.list = alice.createID("/lists", map[string]any{"name": acmeSecret + " lista"})
+	data.todo = alice.createID("/todos", map[string]any{
+		"title": acmeSecret + " todo", "description": acmeSecret, "tags": []string{acmeSecret}, "list_id": data.list,
+	})
+	data.archived = alice.createID("/todos", map[string]any{"title": acmeSecret + " archivado", "completed": true})
+	alice.mustData(http.MethodPost, fmt.Sprintf("/todos/%d/archive", data.archived), nil, http.StatusOK, nil)
+	data.template = alice.createID("/templates", map[string]any{
+		"name": acmeSecret + " plantilla", "items": []map[string]any{{"title": acmeSecret + " paso"}},
+	})
+	var invitation struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	alice.mustData(http.MethodPost, fmt.Sprintf("/lists/%d/invitations", data.list), map[string]any{
+		"email": "eve@example.com", "role": "owner",
+	}, http.StatusCreated, &invitation)
+	data.invitation, data.invitationToken = invitation.ID, invitation.Token
+
+	var token struct {
+		ID    int    `json:"id"`
+		Token string `json:"token"`
+	}
+	alice.mustData(http.MethodPost, "/tokens", map[string]any{"name": acmeSecret + " token"}, http.StatusCreated, &token)
+	data.token, data.tokenValue = token.ID, token.Token
+
+	// El dispatcher registra las entregas de forma asíncrona
+	deadline := time.Now().Add(2 * time.Second)
+	for data.delivery == 0 && time.Now().Before(deadline) {
+		var deliveries []struct {
+			ID int `json:"id"`
+		}
+		alice.mustData(http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries", data.webhook), nil, http.StatusOK, &deliveries)
+		if len(deliveries) > 0 {
+			data.delivery = deliveries[0].ID
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if data.delivery == 0 {
+		t.Fatal("el webhook de acme no registró ninguna entrega")
+	}
+	return data
+}
+
+// sweepPath reemplaza los parámetros de la ruta por los IDs de acme; los
+// prefijos más específicos van primero
+func sweepPath(path string, data acmeData, aliceID int) string {
+	ids := []struct {
+		prefix string
+		id     int
+	}{
+		{"/todos/{id}/unarchive", data.archived},
+		{"/todos/", data.todo},
+		{"/templates/", data.template},
+		{"/lists/", data.list},
+		{"/invitations/", data.invitation},
+		{"/webhooks/deliveries/", data.delivery},
+		{"/webhooks/", data.webhook},
+		{"/tokens/", data.token},
+	}
+	for _, entry := range ids {
+		if strings.HasPrefix(path, entry.prefix) {
+			path = strings.Replace(path, "{id}", strconv.Itoa(entry.id), 1)
+			break
+		}
+	}
+	return strings.Replace(path, "{userID}", strconv.Itoa(aliceID), 1)
+}
+
+// sweepBody arma un body válido para cada operación, de forma que la
+// petición llegue hasta el store en lugar de fallar en la validación
+func sweepBody(op openapi.Operation, data acmeData) any {
+	todo := map[string]any{"title": "pwned", "list_id": data.list}
+	switch op.Method + " " + op.Path {
+	case "POST /tokens":
+		return map[string]any{"name": "pwned"}
+	case "POST /todos", "PUT /todos/{id}", "PATCH /todos/{id}":
+		return todo
+	case "POST /todos/batch":
+		return map[string]any{"operations": []map[string]any{
+			{"op": "update", "id": data.todo, "todo": todo},
+			{"op": "complete", "id": data.todo},
+			{"op": "delete", "id": data.archived},
+		}}
+	case "POST /templates", "PUT /templates/{id}":
+		return map[string]any{"name": "pwned", "items": []map[string]any{{"title": "pwned"}}}
+	case "POST /templates/{id}/instantiate":
+		return map[string]any{"values": map[string]string{}}
+	case "POST /lists", "PUT /lists/{id}":
+		return map[string]any{"name": "pwned"}
+	case "POST /lists/{id}/invitations":
+		return map[string]any{"email": "eve@example.com", "role": "owner"}
+	case "POST /invitations/{id}/accept":
+		return map[string]any{"token": data.invitationToken}
+	case "PUT /lists/{id}/members/{userID}":
+		return map[string]any{"role": "viewer"}
+	case "POST /webhooks", "PUT /webhooks/{id}":
+		return map[string]any{"url": "http://127.0.0.1:1/pwned", "events": []string{"todo.created"}}
+	case "POST /graphql":
+		return map[string]any{"query": fmt.Sprintf(
+			"{ todo(id: %d) { title } todos { nodes { title description } } tags { tag } }", data.todo)}
+	}
+	return nil
+}
+
+// setupTenants configura los workspaces acme y globex; globex no tiene límite
+func setupTenants(t *testing.T, acmeLimit int) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	t.Setenv("GRPC_PORT", "0")
+	// El receptor de los webhooks de prueba escucha en loopback
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	t.Setenv("WORKSPACES", fmt.Sprintf("acme=%d,globex", acmeLimit))
+	t.Setenv("WORKSPACE_DEFAULT", "")
+	t.Setenv("WORKSPACE_DOMAIN", "todo.test")
+}
+
+// apiRouters son los routers que sirven la API JSON
+func apiRouters() map[string]func() http.Handler {
+	return map[string]func() http.Handler{
+		"mux": func() http.Handler { router, _ := SetupRoutes(); return router },
+		"gin": func() http.Handler { router, _ := SetupRoutesGin(); return router },
+	}
+}
+
+func TestWorkspaceIsolationAcrossEndpoints(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice Acme")
+			eve := register(t, router, "globex", "eve@example.com", "Eve Globex")
+			data := seedAcme(t, alice)
+
+			for _, op := range openapi.Operations {
+				if op.Global || op.Public {
+					continue
+				}
+				path := sweepPath(op.Path, data, alice.userID)
+				w := eve.do(op.Method, path, sweepBody(op, data))
+				if strings.Contains(w.Body.String(), acmeSecret) {
+					t.Errorf("%s %s desde globex expone datos de acme: %s", op.Method, path, w.Body.String())
+				}
+				if strings.Contains(op.Path, "{") && w.Code < 300 {
+					t.Errorf("%s %s desde globex respondió %d con un recurso de acme", op.Method, path, w.Code)
+				}
+			}
+
+			// Los datos de acme siguen intactos después del barrido
+			var todo struct {
+				Title     string `json:"title"`
+				Completed bool   `json:"completed"`
+			}
+			alice.mustData(http.MethodGet, fmt.Sprintf("/todos/%d", data.todo), nil, http.StatusOK, &todo)
+			if todo.Title != acmeSecret+" todo" || todo.Completed {
+				t.Errorf("el todo de acme cambió: %+v", todo)
+			}
+			for _, path := range []string{
+				fmt.Sprintf("/templates/%d", data.template),
+				fmt.Sprintf("/webhooks/%d", data.webhook),
+				"/archive?q=" + acmeSecret,
+			} {
+				if w := alice.do(http.MethodGet, path, nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), acmeSecret) {
+					t.Errorf("GET %s en acme: status %d sin los datos de acme", path, w.Code)
+				}
+			}
+			w := alice.do(http.MethodGet, fmt.Sprintf("/lists/%d", data.list), nil)
+			if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "Eve Globex") || strings.Contains(w.Body.String(), "pwned") {
+				t.Errorf("la lista de acme cambió: status %d: %s", w.Code, w.Body.String())
+			}
+			var invitations []struct {
+				ID int `json:"id"`
+			}
+			alice.mustData(http.MethodGet, fmt.Sprintf("/lists/%d", data.list), nil, http.StatusOK, &struct {
+				Invitations *[]struct {
+					ID int `json:"id"`
+				} `json:"invitations"`
+			}{Invitations: &invitations})
+			if len(invitations) != 1 || invitations[0].ID != data.invitation {
+				t.Errorf("la invitación de acme cambió: %+v", invitations)
+			}
+			bearer := &tenantClient{t: t, router: router, prefix: apiPrefix, workspace: "acme", token: data.tokenValue}
+			if w := bearer.do(http.MethodGet, "/todos", nil); w.Code != http.StatusOK {
+				t.Errorf("el token de acme dejó de funcionar: status %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestWorkspaceCredentialsStayInTheirWorkspace(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice Acme")
+			data := seedAcme(t, alice)
+			bearer := &tenantClient{t: t, router: router, prefix: apiPrefix, workspace: "acme", token: data.tokenValue}
+
+			for label, c := range map[string]*tenantClient{"sesión": alice.as("globex"), "token": bearer.as("globex")} {
+				if w := c.do(http.MethodGet, "/todos", nil); w.Code != http.StatusUnauthorized {
+					t.Errorf("la %s de acme en globex respondió %d, se esperaba 401", label, w.Code)
+				}
+			}
+
+			anonymous := &tenantClient{t: t, router: router, prefix: apiPrefix, workspace: "globex"}
+			login := map[string]string{"email": "alice@example.com", "password": "contraseña-segura"}
+			if w := anonymous.do(http.MethodPost, "/auth/login", login); w.Code != http.StatusUnauthorized {
+				t.Errorf("login de alice en globex respondió %d, se esperaba 401", w.Code)
+			}
+			// Los emails son únicos por workspace
+			register(t, router, "globex", "alice@example.com", "Otra Alice")
+
+			for _, workspace := range []string{"initech", ""} {
+				w := alice.as(workspace).do(http.MethodGet, "/todos", nil)
+				if w.Code != http.StatusNotFound || problemCode(w) != "workspace_not_found" {
+					t.Errorf("workspace %q respondió %d %s, se esperaba 404 workspace_not_found", workspace, w.Code, problemCode(w))
+				}
+			}
+			if w := anonymous.do(http.MethodGet, "/health", nil); w.Code != http.StatusOK {
+				t.Errorf("GET /health respondió %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestWorkspaceFromSubdomain(t *testing.T) {
+	setupTenants(t, 0)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice Acme")
+			alice.createID("/todos", map[string]any{"title": acmeSecret})
+
+			get := func(host, header string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, apiPrefix+"/todos", nil)
+				req.Host = host
+				if header != "" {
+					req.Header.Set(workspaceHeader, header)
+				}
+				req.AddCookie(alice.cookie)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w
+			}
+			if w := get("acme.todo.test:8080", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), acmeSecret) {
+				t.Errorf("acme.todo.test respondió %d sin los todos de acme", w.Code)
+			}
+			if w := get("globex.todo.test", ""); w.Code != http.StatusUnauthorized {
+				t.Errorf("la sesión de acme en globex.todo.test respondió %d, se esperaba 401", w.Code)
+			}
+			// El header tiene prioridad sobre el subdominio
+			if w := get("acme.todo.test", "globex"); w.Code != http.StatusUnauthorized {
+				t.Errorf("X-Workspace: globex en acme.todo.test respondió %d, se esperaba 401", w.Code)
+			}
+			if w := get("todo.test", ""); w.Code != http.StatusNotFound {
+				t.Errorf("el dominio base sin workspace respondió %d, se esperaba 404", w.Code)
+			}
+		})
+	}
+}
+
+func TestWorkspaceQuota(t *testing.T) {
+	setupTenants(t, 3)
+	for name, setup := range apiRouters() {
+		t.Run(name, func(t *testing.T) {
+			router := setup()
+			alice := register(t, router, "acme", "alice@example.com", "Alice Acme")
+			eve := register(t, router, "globex", "eve@example.com", "Eve Globex")
+
+			first := alice.createID("/todos", map[string]any{"title": "uno", "completed": true})
+			alice.createID("/todos", map[string]any{"title": "dos"})
+			template := alice.createID("/templates", map[string]any{
+				"name": "plantilla", "items": []map[string]any{{"title": "a"}, {"title": "b"}},
+			})
+
+			// Una plantilla que no entra completa no crea ningún todo
+			w := alice.do(http.MethodPost, fmt.Sprintf("/templates/%d/instantiate", template), map[string]any{})
+			if w.Code != http.StatusForbidden || problemCode(w) != "quota_exceeded" {
+				t.Errorf("instanciar por encima del límite respondió %d %s", w.Code, problemCode(w))
+			}
+			alice.createID("/todos", map[string]any{"title": "tres"})
+
+			// Archivar no libera cupo
+			alice.mustData(http.MethodPost, fmt.Sprintf("/todos/%d/archive", first), nil, http.StatusOK, nil)
+			w = alice.do(http.MethodPost, "/todos", map[string]any{"title": "cuatro"})
+			if w.Code != http.StatusForbidden || problemCode(w) != "quota_exceeded" {
+				t.Errorf("crear por encima del límite respondió %d %s", w.Code, problemCode(w))
+			}
+
+			w = alice.do(http.MethodPost, "/todos/batch", map[string]any{"operations": []map[string]any{
+				{"op": "create", "todo": map[string]any{"title": "cuatro"}},
+			}})
+			var batch struct {
+				Results []struct {
+					Status int `json:"status"`
+				} `json:"results"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &batch)
+			if w.Code != http.StatusUnprocessableEntity || len(batch.Results) != 1 || batch.Results[0].Status != http.StatusForbidden {
+				t.Errorf("lote por encima del límite respondió %d: %s", w.Code, w.Body.String())
+			}
+
+			var workspace struct {
+				Slug     string `json:"slug"`
+				MaxTodos int    `json:"max_todos"`
+				Todos    int    `json:"todos"`
+			}
+			alice.mustData(http.MethodGet, "/workspace", nil, http.StatusOK, &workspace)
+			if workspace.Slug != "acme" || workspace.MaxTodos != 3 || workspace.Todos != 3 {
+				t.Errorf("GET /workspace en acme: %+v", workspace)
+			}
+
+			// El límite de acme no afecta a globex
+			for i := 0; i < 5; i++ {
+				eve.createID("/todos", map[string]any{"title": "globex"})
+			}
+		})
+	}
+}
+
+func TestWorkspaceIsolationTempl(t *testing.T) {
+	setupTenants(t, 0)
+	router := SetupRoutesTempl()
+
+	registerTempl := func(workspace, email string) *tenantClient {
+		c := &tenantClient{t: t, router: router, workspace: workspace}
+		for _, cookie := range c.do(http.MethodGet, "/register", nil).Result().Cookies() {
+			if cookie.Name == "csrf" {
+				c.csrf = cookie
+			}
+		}
+		w := c.do(http.MethodPost, "/register", url.Values{
+			"email": {email}, "name": {email}, "password": {"contraseña-segura"},
+		})
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == "session" {
+				c.cookie = cookie
+			}
+		}
+		if c.cookie == nil {
+			t.Fatalf("registrar %s en %s: status %d sin cookie de sesión", email, workspace, w.Code)
+		}
+		return c
+	}
+	alice := registerTempl("acme", "alice@example.com")
+	eve := registerTempl("globex", "eve@example.com")
+
+	// En un store nuevo los recursos de alice son los primeros de cada tipo;
+	// eve recibe el código de la invitación para que solo la frene el workspace
+	var invitationToken string
+	for _, step := range []struct {
+		path string
+		body any
+	}{
+		{"/api/todos", map[string]any{"title": acmeSecret + " todo", "description": acmeSecret}},
+		{"/api/templates", map[string]any{"name": acmeSecret, "items": []map[string]any{{"title": acmeSecret}}}},
+		{"/api/lists", url.Values{"name": {acmeSecret + " lista"}}},
+		{"/api/lists/1/invitations", url.Values{"email": {"eve@example.com"}, "role": {"owner"}}},
+	} {
+		if w := alice.do(http.MethodPost, step.path, step.body); w.Code >= 400 {
+			t.Fatalf("POST %s en acme: status %d: %s", step.path, w.Code, w.Body.String())
+		} else if token := regexp.MustCompile(`inv_[0-9a-f]+`).FindString(w.Body.String()); token != "" {
+			invitationToken = token
+		}
+	}
+
+	bodies := map[string]any{
+		"POST /api/todos":                     map[string]any{"title": "pwned"},
+		"POST /api/todos/flexible":            map[string]any{"title": "pwned"},
+		"PUT /api/todos/:id":                  map[string]any{"title": "pwned"},
+		"POST /api/lists":                     url.Values{"name": {"pwned"}},
+		"POST /api/lists/:id/invitations":     url.Values{"email": {"eve@example.com"}, "role": {"owner"}},
+		"PUT /api/lists/:id/members/:userID":  url.Values{"role": {"viewer"}},
+		"POST /api/invitations/:id/accept":    url.Values{"token": {invitationToken}},
+		"POST /api/templates/:id/instantiate": url.Values{},
+	}
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, "/static") || route.Path == "/api/health" || !strings.HasPrefix(route.Path, "/api/") && route.Method != http.MethodGet {
+			continue
+		}
+		path := strings.NewReplacer(":id", "1", ":userID", "1", "*filepath", "").Replace(route.Path)
+		w := eve.do(route.Method, path, bodies[route.Method+" "+route.Path])
+		if strings.Contains(w.Body.String(), acmeSecret) {
+			t.Errorf("%s %s desde globex expone datos de acme", route.Method, path)
+		}
+		if strings.Contains(route.Path, ":id") && w.Code < 300 {
+			t.Errorf("%s %s desde globex respondió %d con un recurso de acme", route.Method, path, w.Code)
+		}
+	}
+
+	for _, path := range []string{"/", "/lists"} {
+		if w := alice.do(http.MethodGet, path, nil); !strings.Contains(w.Body.String(), acmeSecret) {
+			t.Errorf("GET %s en acme ya no muestra los datos de acme (status %d)", path, w.Code)
+		}
+	}
+}
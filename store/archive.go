@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"todo-list/models"
+)
+
+// ErrNotCompleted se retorna al archivar una tarea que no está completada
+var ErrNotCompleted = errors.New("solo se pueden archivar tareas completadas")
+
+// ArchivePolicy define cuándo se archivan automáticamente las tareas completadas
+type ArchivePolicy struct {
+	// After es la antigüedad mínima desde que se completó la tarea
+	After time.Duration
+	// Interval es cada cuánto se revisan las tareas
+	Interval time.Duration
+}
+
+// Enabled indica si el archivado automático está activo
+func (p ArchivePolicy) Enabled() bool {
+	return p.After > 0 && p.Interval > 0
+}
+
+// ListArchived obtiene los todos archivados que el usuario puede ver cuyo título o
+// descripción contienen query
+func (s *TodoStore) ListArchived(ctx context.Context, query string) []models.Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	query = strings.ToLower(strings.TrimSpace(query))
+	todos := make([]models.Todo, 0)
+	for _, todo := range s.todos {
+		if !todo.Archived || !s.canRead(sc, todo) {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(todo.Title), query) &&
+			!strings.Contains(strings.ToLower(todo.Description), query) {
+			continue
+		}
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+// Archive archiva manualmente un todo completado
+func (s *TodoStore) Archive(ctx context.Context, id int) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	i, err := s.writableIndexOf(scopeOf(ctx), id)
+	if err != nil {
+		return models.Todo{}, err
+	}
+	if !s.todos[i].Completed {
+		return models.Todo{}, ErrNotCompleted
+	}
+
+	s.archive(i, s.now())
+	return s.todos[i], nil
+}
+
+// Unarchive devuelve un todo archivado a la lista principal
+func (s *TodoStore) Unarchive(ctx context.Context, id int) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	i, err := s.writableIndexOf(scopeOf(ctx), id)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	todo := &s.todos[i]
+	if todo.Archived {
+		now := s.now()
+		todo.Archived = false
+		todo.ArchivedAt = nil
+		todo.UpdatedAt = now
+		todo.Version++
+		s.record(*todo, models.AuditUnarchived, todo.Estimate, now)
+		s.emit(models.EventTodoUnarchived, todo)
+	}
+	return *todo, nil
+}
+
+// ArchiveCompletedBefore archiva los todos completados antes de cutoff, de
+// todos los usuarios y workspaces, y retorna cuántos se archivaron
+func (s *TodoStore) ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	now := s.now()
+	archived := 0
+	for i, todo := range s.todos {
+		if todo.Archived || !todo.Completed || todo.CompletedAt == nil {
+			continue
+		}
+		if todo.CompletedAt.Before(cutoff) {
+			s.archive(i, now)
+			archived++
+		}
+	}
+	return archived
+}
+
+// StartAutoArchive ejecuta el archivado automático en segundo plano hasta
+// que se cancele ctx
+func (s *TodoStore) StartAutoArchive(ctx context.Context, policy ArchivePolicy) {
+	if !policy.Enabled() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(policy.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cutoff := s.now().Add(-policy.After)
+				if n := s.ArchiveCompletedBefore(ctx, cutoff); n > 0 {
+					log.Printf("🗄️  %d tareas archivadas automáticamente", n)
+				}
+			}
+		}
+	}()
+}
+
+// archive marca un todo como archivado; requiere tener el lock tomado
+func (s *TodoStore) archive(i int, now time.Time) {
+	todo := &s.todos[i]
+	if todo.Archived {
+		return
+	}
+	todo.Archived = true
+	todo.ArchivedAt = &now
+	todo.UpdatedAt = now
+	todo.Version++
+	s.record(*todo, models.AuditArchived, todo.Estimate, now)
+	s.emit(models.EventTodoArchived, todo)
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-list/models"
+)
+
+// ErrInvalidAssignee se retorna al asignar un todo, o sumarle un observador,
+// a alguien que no puede verlo: en un todo personal solo su dueño y en uno de
+// una lista sus miembros
+var ErrInvalidAssignee = errors.New("el responsable y los observadores deben poder ver el todo")
+
+// History obtiene los cambios registrados sobre un todo visible para el
+// usuario, del más antiguo al más reciente, incluidas las reasignaciones
+func (s *TodoStore) History(ctx context.Context, id int) ([]models.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	todo := s.todos[i]
+	entries := make([]models.AuditEntry, 0)
+	for _, entry := range s.audit {
+		if entry.TodoID == todo.ID && entry.WorkspaceID == todo.WorkspaceID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// assign aplica el responsable y los observadores pedidos sobre todo, que ya
+// debe tener su lista definitiva. En nil se conservan los actuales, salvo los
+// que dejaron de ver el todo (porque cambió de lista o salieron de ella), que
+// se quitan. No modifica todo si falla; requiere tener el lock tomado
+func (s *TodoStore) assign(todo *models.Todo, assignee *int, watchers []int) error {
+	audience := s.audience(*todo)
+
+	assigneeID := todo.AssigneeID
+	if assignee != nil {
+		assigneeID = *assignee
+		if assigneeID != 0 && !containsID(audience, assigneeID) {
+			return ErrInvalidAssignee
+		}
+	} else if !containsID(audience, assigneeID) {
+		assigneeID = 0
+	}
+
+	kept := make([]int, 0, len(todo.Watchers))
+	if watchers != nil {
+		for _, watcher := range watchers {
+			if !containsID(audience, watcher) {
+				return ErrInvalidAssignee
+			}
+			if !containsID(kept, watcher) {
+				kept = append(kept, watcher)
+			}
+		}
+	} else {
+		for _, watcher := range todo.Watchers {
+			if containsID(audience, watcher) {
+				kept = append(kept, watcher)
+			}
+		}
+	}
+
+	todo.AssigneeID = assigneeID
+	todo.Watchers = kept
+	return nil
+}
+
+// unassignMember quita al usuario como responsable y observador de los todos
+// de la lista que acaba de dejar; requiere tener el lock tomado
+func (s *TodoStore) unassignMember(workspace, listID, user int) {
+	now := s.now()
+	for i := range s.todos {
+		todo := &s.todos[i]
+		if todo.WorkspaceID != workspace || todo.ListID != listID ||
+			(todo.AssigneeID != user && !containsID(todo.Watchers, user)) {
+			continue
+		}
+		previous := todo.AssigneeID
+		s.assign(todo, nil, nil)
+		todo.UpdatedAt = now
+		todo.Version++
+		if todo.AssigneeID != previous {
+			s.recordReassign(*todo, previous, now)
+		}
+		s.emit(models.EventTodoUpdated, todo)
+	}
+}
+
+// recordReassign registra en la auditoría el cambio de responsable de un
+// todo; requiere tener el lock tomado
+func (s *TodoStore) recordReassign(todo models.Todo, previous int, at time.Time) {
+	s.record(todo, models.AuditReassigned, todo.Estimate, at)
+	s.audit[len(s.audit)-1].PreviousAssigneeID = previous
+}
+
+// containsID indica si id está en ids
+func containsID(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"todo-list/models"
+)
+
+// MaxBatchOperations es la cantidad máxima de operaciones por lote
+const MaxBatchOperations = 100
+
+// ErrBatchFailed se retorna cuando alguna operación del lote falla; en ese
+// caso ningún cambio queda aplicado
+var ErrBatchFailed = errors.New("el lote no se aplicó porque una operación falló")
+
+// Batch ejecuta las operaciones sobre los todos del usuario de forma atómica:
+// o se aplican todas o ninguna. Siempre retorna un resultado por operación
+func (s *TodoStore) Batch(ctx context.Context, ops []models.BatchOperation) ([]models.BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	// Guardar el estado para poder revertir si algo falla
+	todos := make([]models.Todo, len(s.todos))
+	copy(todos, s.todos)
+	nextID := s.nextID
+	auditLen := len(s.audit)
+
+	sc := scopeOf(ctx)
+	results := make([]models.BatchResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		results[i] = s.applyOperation(sc, i, op)
+		if !results[i].Success {
+			failed = true
+			break
+		}
+	}
+
+	if !failed {
+		return results, nil
+	}
+
+	s.todos = todos
+	s.nextID = nextID
+	s.audit = s.audit[:auditLen]
+	s.pending = nil // los cambios revertidos no se publican
+	for i := range results {
+		if results[i].Success {
+			results[i].Success = false
+			results[i].Status = http.StatusFailedDependency
+			results[i].Message = "Revertida porque otra operación del lote falló"
+			results[i].Data = nil
+		} else if results[i].Op == "" && results[i].Status == 0 {
+			results[i] = models.BatchResult{
+				Index:   i,
+				Op:      ops[i].Op,
+				ID:      ops[i].ID,
+				Status:  http.StatusFailedDependency,
+				Message: "No ejecutada porque otra operación del lote falló",
+			}
+		}
+	}
+	return results, ErrBatchFailed
+}
+
+// applyOperation ejecuta una operación del lote sobre los todos del usuario
+// de sc; requiere tener el lock tomado
+func (s *TodoStore) applyOperation(sc scope, index int, op models.BatchOperation) models.BatchResult {
+	result := models.BatchResult{Index: index, Op: op.Op, ID: op.ID}
+	fail := func(status int, message string) models.BatchResult {
+		result.Status = status
+		result.Message = message
+		return result
+	}
+	failWith := func(err error) models.BatchResult {
+		switch {
+		case errors.Is(err, ErrForbidden):
+			return fail(http.StatusForbidden, "Tu rol en la lista no permite esta operación")
+		case errors.Is(err, ErrListNotFound):
+			return fail(http.StatusNotFound, "Lista no encontrada")
+		case errors.Is(err, ErrQuotaExceeded):
+			return fail(http.StatusForbidden, "El workspace alcanzó su límite de tareas")
+		default:
+			return fail(http.StatusNotFound, "Todo no encontrado")
+		}
+	}
+	done := func(status int, message string, todo *models.Todo) models.BatchResult {
+		result.Status = status
+		result.Success = true
+		result.Message = message
+		result.Data = todo
+		return result
+	}
+
+	switch op.Op {
+	case models.BatchCreate:
+		todo, err := s.create(sc, *op.Todo)
+		if err != nil {
+			return failWith(err)
+		}
+		result.ID = todo.ID
+		return done(http.StatusCreated, "Todo creado exitosamente", &todo)
+	case models.BatchUpdate:
+		todo, err := s.update(sc, op.ID, *op.Todo)
+		if err != nil {
+			return failWith(err)
+		}
+		return done(http.StatusOK, "Todo actualizado exitosamente", &todo)
+	case models.BatchComplete:
+		i := s.indexOf(sc, op.ID)
+		if i < 0 {
+			return fail(http.StatusNotFound, "Todo no encontrado")
+		}
+		current := s.todos[i]
+		todo, err := s.update(sc, op.ID, models.TodoRequest{
+			Title:       current.Title,
+			Description: current.Description,
+			Completed:   true,
+			Estimate:    current.Estimate,
+		})
+		if err != nil {
+			return failWith(err)
+		}
+		return done(http.StatusOK, "Todo completado exitosamente", &todo)
+	case models.BatchDelete:
+		if err := s.delete(sc, op.ID); err != nil {
+			return failWith(err)
+		}
+		return done(http.StatusOK, "Todo eliminado exitosamente", nil)
+	default:
+		return fail(http.StatusBadRequest, "Operación inválida: use create, update, delete o complete")
+	}
+}
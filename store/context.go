@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"todo-list/models"
+)
+
+// contextKey es el tipo de las claves que el store lee del contexto
+type contextKey int
+
+const (
+	// userKey guarda el usuario autenticado de la petición
+	userKey contextKey = iota
+	// apiTokenKey guarda el token personal con el que se autenticó la petición
+	apiTokenKey
+	// workspaceKey guarda el workspace al que va dirigida la petición
+	workspaceKey
+)
+
+// WithUser guarda en ctx el usuario autenticado. Las operaciones del store
+// solo ven y modifican los datos de ese usuario
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFrom obtiene el usuario autenticado guardado en ctx
+func UserFrom(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(userKey).(models.User)
+	return user, ok
+}
+
+// UserID obtiene el ID del usuario autenticado; cero si no hay ninguno o si
+// es de otro workspace, y ningún dato pertenece al usuario cero
+func UserID(ctx context.Context) int {
+	return scopeOf(ctx).user.ID
+}
+
+// WithToken guarda en ctx el token personal con el que se autenticó la
+// petición. Las peticiones con la cookie de sesión no llevan ninguno
+func WithToken(ctx context.Context, token models.APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenKey, token)
+}
+
+// TokenFrom obtiene el token personal guardado en ctx; ok es false si la
+// petición se autenticó con la sesión
+func TokenFrom(ctx context.Context) (models.APIToken, bool) {
+	token, ok := ctx.Value(apiTokenKey).(models.APIToken)
+	return token, ok
+}
+
+// WithWorkspace guarda en ctx el workspace de la petición. Las operaciones
+// del store solo ven y modifican los datos de ese workspace
+func WithWorkspace(ctx context.Context, workspace models.Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey, workspace)
+}
+
+// WorkspaceFrom obtiene el workspace de la petición guardado en ctx
+func WorkspaceFrom(ctx context.Context) (models.Workspace, bool) {
+	workspace, ok := ctx.Value(workspaceKey).(models.Workspace)
+	return workspace, ok
+}
+
+// WorkspaceID obtiene el ID del workspace de la petición; cero si no hay ninguno
+func WorkspaceID(ctx context.Context) int {
+	workspace, _ := WorkspaceFrom(ctx)
+	return workspace.ID
+}
+
+// scope identifica de quién son los datos que toca una operación: el
+// workspace de la petición y el usuario autenticado dentro de él. Los helpers
+// de los stores reciben un scope en lugar de leer ctx
+type scope struct {
+	workspace models.Workspace
+	user      models.User
+}
+
+// scopeOf obtiene el scope de ctx. Un usuario de otro workspace se trata
+// como si no hubiera usuario, así que no ve ni modifica nada
+func scopeOf(ctx context.Context) scope {
+	workspace, _ := WorkspaceFrom(ctx)
+	user, _ := UserFrom(ctx)
+	if user.WorkspaceID != workspace.ID {
+		user = models.User{}
+	}
+	return scope{workspace: workspace, user: user}
+}
+
+// owns indica si un registro de workspaceID y ownerID pertenece al usuario del scope
+func (sc scope) owns(workspaceID, ownerID int) bool {
+	return workspaceID == sc.workspace.ID && ownerID == sc.user.ID
+}
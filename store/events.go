@@ -0,0 +1,62 @@
+package store
+
+import (
+	"todo-list/models"
+)
+
+// Publisher recibe los cambios del store una vez aplicados. Se llama con el
+// lock del store tomado, así que no debe bloquear ni volver a usar el store
+type Publisher interface {
+	Publish(event models.ChangeEvent)
+}
+
+// SetPublisher configura a quién se avisan los cambios; nil deja de avisar
+func (s *TodoStore) SetPublisher(p Publisher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publisher = p
+}
+
+// emit encola un cambio sobre todo para publicarlo cuando la operación
+// termine, dirigido a quienes pueden verlo; requiere tener el lock tomado
+func (s *TodoStore) emit(eventType string, todo *models.Todo) {
+	if s.publisher == nil {
+		return
+	}
+	s.emitTo(eventType, todo, s.audience(*todo))
+}
+
+// emitTo encola un cambio dirigido a los usuarios de audience. Los eventos de
+// eliminación no llevan el todo; requiere tener el lock tomado
+func (s *TodoStore) emitTo(eventType string, todo *models.Todo, audience []int) {
+	if s.publisher == nil || len(audience) == 0 {
+		return
+	}
+
+	event := models.ChangeEvent{
+		Type:        eventType,
+		TodoID:      todo.ID,
+		WorkspaceID: todo.WorkspaceID,
+		OwnerID:     todo.OwnerID,
+		ListID:      todo.ListID,
+		Audience:    audience,
+		At:          s.now(),
+	}
+	if eventType != models.EventTodoDeleted {
+		copied := *todo
+		copied.Tags = copyTags(todo.Tags)
+		copied.Checklist = copyChecklist(todo.Checklist)
+		event.Todo = &copied
+	}
+	s.pending = append(s.pending, event)
+}
+
+// flush publica los cambios encolados, en el orden en que ocurrieron; requiere
+// tener el lock tomado
+func (s *TodoStore) flush() {
+	for _, event := range s.pending {
+		s.publisher.Publish(event)
+	}
+	s.pending = nil
+}
@@ -0,0 +1,491 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"todo-list/models"
+)
+
+// InvitationTokenPrefix inicia el código de todas las invitaciones
+const InvitationTokenPrefix = "inv_"
+
+// ErrListNotFound se retorna cuando la lista no existe o el usuario no es
+// miembro; así no se revela qué listas existen
+var ErrListNotFound = errors.New("lista no encontrada")
+
+// ErrForbidden se retorna cuando el usuario ve el recurso pero su rol en la
+// lista no le permite la operación
+var ErrForbidden = errors.New("tu rol en la lista no permite esta operación")
+
+// ErrInvitationNotFound se retorna cuando la invitación no existe o no está
+// dirigida al usuario
+var ErrInvitationNotFound = errors.New("invitación no encontrada")
+
+// ErrMemberNotFound se retorna cuando el usuario no es miembro de la lista
+var ErrMemberNotFound = errors.New("miembro no encontrado")
+
+// ErrAlreadyMember se retorna al invitar a alguien que ya es miembro
+var ErrAlreadyMember = errors.New("ya es miembro de la lista")
+
+// ErrLastOwner se retorna cuando un cambio dejaría la lista sin owners
+var ErrLastOwner = errors.New("la lista debe tener al menos un owner")
+
+// Lists obtiene las listas compartidas de las que el usuario es miembro
+func (s *TodoStore) Lists(ctx context.Context) []models.List {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	lists := make([]models.List, 0)
+	for i := range s.lists {
+		if s.roleIn(sc, s.lists[i].ID) != "" {
+			lists = append(lists, s.listView(i, sc))
+		}
+	}
+	return lists
+}
+
+// GetList obtiene una lista de la que el usuario es miembro
+func (s *TodoStore) GetList(ctx context.Context, id int) (models.List, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	i, err := s.requireRole(sc, id, models.RoleViewer)
+	if err != nil {
+		return models.List{}, err
+	}
+	return s.listView(i, sc), nil
+}
+
+// CreateList crea una lista compartida con el usuario como único owner
+func (s *TodoStore) CreateList(ctx context.Context, req models.ListRequest) (models.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	now := s.now()
+	list := models.List{
+		ID:          s.nextListID,
+		WorkspaceID: sc.workspace.ID,
+		Name:        req.Name,
+		Members: []models.ListMember{{
+			UserID:   sc.user.ID,
+			Name:     sc.user.Name,
+			Email:    sc.user.Email,
+			Role:     models.RoleOwner,
+			JoinedAt: now,
+		}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.lists = append(s.lists, list)
+	s.nextListID++
+	return s.listView(len(s.lists)-1, sc), nil
+}
+
+// UpdateList renombra una lista; solo los owners pueden hacerlo
+func (s *TodoStore) UpdateList(ctx context.Context, id int, req models.ListRequest) (models.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	i, err := s.requireRole(sc, id, models.RoleOwner)
+	if err != nil {
+		return models.List{}, err
+	}
+
+	s.lists[i].Name = req.Name
+	s.lists[i].UpdatedAt = s.now()
+	return s.listView(i, sc), nil
+}
+
+// DeleteList elimina una lista junto con sus todos e invitaciones; solo los
+// owners pueden hacerlo
+func (s *TodoStore) DeleteList(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	i, err := s.requireRole(scopeOf(ctx), id, models.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	// Los todos se eliminan antes que la lista para avisar a sus miembros
+	for j := len(s.todos) - 1; j >= 0; j-- {
+		if s.todos[j].ListID == id {
+			s.remove(j)
+		}
+	}
+	invitations := s.invitations[:0]
+	for _, invitation := range s.invitations {
+		if invitation.ListID != id {
+			invitations = append(invitations, invitation)
+		}
+	}
+	s.invitations = invitations
+	s.lists = append(s.lists[:i], s.lists[i+1:]...)
+	return nil
+}
+
+// Invite invita a un email a la lista con el rol indicado; solo los owners
+// pueden hacerlo. Si ya había una invitación pendiente para ese email se
+// actualiza su rol y se emite un código nuevo. El código solo viaja en la
+// invitación retornada; el store guarda su hash
+func (s *TodoStore) Invite(ctx context.Context, listID int, req models.InvitationRequest) (models.Invitation, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return models.Invitation{}, err
+	}
+	token := InvitationTokenPrefix + hex.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	i, err := s.requireRole(sc, listID, models.RoleOwner)
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	email := normalizeEmail(req.Email)
+	for _, member := range s.lists[i].Members {
+		if normalizeEmail(member.Email) == email {
+			return models.Invitation{}, ErrAlreadyMember
+		}
+	}
+	for j := range s.invitations {
+		if s.invitations[j].ListID == listID && s.invitations[j].Email == email {
+			s.invitations[j].Role = req.Role
+			s.invitations[j].TokenHash = tokenKey(token)
+			invitation := s.invitationView(j)
+			invitation.Token = token
+			return invitation, nil
+		}
+	}
+
+	s.invitations = append(s.invitations, models.Invitation{
+		ID:          s.nextInviteID,
+		WorkspaceID: sc.workspace.ID,
+		ListID:      listID,
+		Email:       email,
+		Role:        req.Role,
+		InvitedBy:   sc.user.Name,
+		CreatedAt:   s.now(),
+		TokenHash:   tokenKey(token),
+	})
+	s.nextInviteID++
+	invitation := s.invitationView(len(s.invitations) - 1)
+	invitation.Token = token
+	return invitation, nil
+}
+
+// Invitations obtiene las invitaciones pendientes dirigidas al email del
+// usuario dentro de su workspace
+func (s *TodoStore) Invitations(ctx context.Context) []models.Invitation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	invitations := make([]models.Invitation, 0)
+	for j := range s.invitations {
+		if s.invitedIn(sc, j) {
+			invitations = append(invitations, s.invitationView(j))
+		}
+	}
+	return invitations
+}
+
+// AcceptInvitation agrega al usuario a la lista con el rol de la invitación.
+// Además del email, exige el código que Invite entregó al owner: el email de
+// la cuenta no está verificado. El código sirve una sola vez
+func (s *TodoStore) AcceptInvitation(ctx context.Context, id int, token string) (models.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	j := s.invitationIndexOf(sc, id)
+	if j < 0 || !s.invitedIn(sc, j) {
+		return models.List{}, ErrInvitationNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(s.invitations[j].TokenHash), []byte(tokenKey(token))) != 1 {
+		return models.List{}, ErrInvitationNotFound
+	}
+	invitation := s.invitations[j]
+	s.invitations = append(s.invitations[:j], s.invitations[j+1:]...)
+
+	i := s.listIndexOf(sc.workspace.ID, invitation.ListID)
+	if i < 0 {
+		return models.List{}, ErrListNotFound
+	}
+	if s.roleIn(sc, invitation.ListID) == "" {
+		s.lists[i].Members = append(s.lists[i].Members, models.ListMember{
+			UserID:   sc.user.ID,
+			Name:     sc.user.Name,
+			Email:    sc.user.Email,
+			Role:     invitation.Role,
+			JoinedAt: s.now(),
+		})
+	}
+	return s.listView(i, sc), nil
+}
+
+// DeleteInvitation rechaza una invitación dirigida al usuario o cancela una
+// invitación de una lista de la que es owner
+func (s *TodoStore) DeleteInvitation(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	j := s.invitationIndexOf(sc, id)
+	if j < 0 {
+		return ErrInvitationNotFound
+	}
+	if !s.invitedIn(sc, j) && s.roleIn(sc, s.invitations[j].ListID) != models.RoleOwner {
+		return ErrInvitationNotFound
+	}
+
+	s.invitations = append(s.invitations[:j], s.invitations[j+1:]...)
+	return nil
+}
+
+// UpdateMember cambia el rol de un miembro; solo los owners pueden hacerlo y
+// la lista no puede quedarse sin owners
+func (s *TodoStore) UpdateMember(ctx context.Context, listID, memberID int, req models.MemberRequest) (models.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	i, err := s.requireRole(sc, listID, models.RoleOwner)
+	if err != nil {
+		return models.List{}, err
+	}
+	m := s.memberIndexOf(i, memberID)
+	if m < 0 {
+		return models.List{}, ErrMemberNotFound
+	}
+	member := &s.lists[i].Members[m]
+	if member.Role == models.RoleOwner && req.Role != models.RoleOwner && s.owners(i) == 1 {
+		return models.List{}, ErrLastOwner
+	}
+
+	member.Role = req.Role
+	s.lists[i].UpdatedAt = s.now()
+	return s.listView(i, sc), nil
+}
+
+// RemoveMember quita a un miembro de la lista. Los owners pueden quitar a
+// cualquiera y cualquier miembro puede salir de la lista; la lista no puede
+// quedarse sin owners. Quien sale deja de ser responsable y observador de
+// los todos de la lista
+func (s *TodoStore) RemoveMember(ctx context.Context, listID, memberID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	sc := scopeOf(ctx)
+	role := models.RoleOwner
+	if memberID == sc.user.ID {
+		role = models.RoleViewer
+	}
+	i, err := s.requireRole(sc, listID, role)
+	if err != nil {
+		return err
+	}
+	m := s.memberIndexOf(i, memberID)
+	if m < 0 {
+		return ErrMemberNotFound
+	}
+	if s.lists[i].Members[m].Role == models.RoleOwner && s.owners(i) == 1 {
+		return ErrLastOwner
+	}
+
+	s.lists[i].Members = append(s.lists[i].Members[:m], s.lists[i].Members[m+1:]...)
+	s.lists[i].UpdatedAt = s.now()
+	s.unassignMember(sc.workspace.ID, listID, memberID)
+	return nil
+}
+
+// canRead indica si el usuario de sc puede ver el todo: nadie ve los de otro
+// workspace, los personales solo su dueño y los de una lista cualquier
+// miembro. Requiere tener el lock tomado
+func (s *TodoStore) canRead(sc scope, todo models.Todo) bool {
+	if todo.ListID == 0 {
+		return sc.owns(todo.WorkspaceID, todo.OwnerID)
+	}
+	return todo.WorkspaceID == sc.workspace.ID && s.roleIn(sc, todo.ListID) != ""
+}
+
+// canWrite indica si el usuario de sc puede modificar el todo: los personales
+// solo su dueño y los de una lista los editores y owners. Requiere tener el
+// lock tomado
+func (s *TodoStore) canWrite(sc scope, todo models.Todo) bool {
+	if !s.canRead(sc, todo) {
+		return false
+	}
+	return todo.ListID == 0 || models.RoleRank(s.roleIn(sc, todo.ListID)) >= models.RoleRank(models.RoleEditor)
+}
+
+// audience obtiene los usuarios que pueden ver el todo; requiere tener el
+// lock tomado
+func (s *TodoStore) audience(todo models.Todo) []int {
+	if todo.ListID == 0 {
+		return []int{todo.OwnerID}
+	}
+	i := s.listIndexOf(todo.WorkspaceID, todo.ListID)
+	if i < 0 {
+		return nil
+	}
+	users := make([]int, 0, len(s.lists[i].Members))
+	for _, member := range s.lists[i].Members {
+		users = append(users, member.UserID)
+	}
+	return users
+}
+
+// Audience obtiene los usuarios que pueden ver el todo id: su dueño o los
+// miembros de su lista. Si el usuario de ctx no lo puede ver retorna ErrNotFound
+func (s *TodoStore) Audience(ctx context.Context, id int) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return s.audience(s.todos[i]), nil
+}
+
+// requireRole busca la lista en el workspace de sc y verifica que su usuario
+// tenga al menos role en ella. Si no es miembro retorna ErrListNotFound y si
+// su rol no alcanza ErrForbidden. Requiere tener el lock tomado
+func (s *TodoStore) requireRole(sc scope, listID int, role string) (int, error) {
+	i := s.listIndexOf(sc.workspace.ID, listID)
+	if i < 0 {
+		return -1, ErrListNotFound
+	}
+	current := s.roleIn(sc, listID)
+	if current == "" {
+		return -1, ErrListNotFound
+	}
+	if models.RoleRank(current) < models.RoleRank(role) {
+		return -1, ErrForbidden
+	}
+	return i, nil
+}
+
+// roleIn obtiene el rol del usuario de sc en una lista de su workspace; vacío
+// si no es miembro. Requiere tener el lock tomado
+func (s *TodoStore) roleIn(sc scope, listID int) string {
+	i := s.listIndexOf(sc.workspace.ID, listID)
+	if i < 0 {
+		return ""
+	}
+	if m := s.memberIndexOf(i, sc.user.ID); m >= 0 {
+		return s.lists[i].Members[m].Role
+	}
+	return ""
+}
+
+// owners cuenta los owners de la lista en la posición i; requiere tener el
+// lock tomado
+func (s *TodoStore) owners(i int) int {
+	count := 0
+	for _, member := range s.lists[i].Members {
+		if member.Role == models.RoleOwner {
+			count++
+		}
+	}
+	return count
+}
+
+// listView arma la lista tal como la ve el usuario de sc: con su rol y, si es
+// owner, las invitaciones pendientes. Requiere tener el lock tomado
+func (s *TodoStore) listView(i int, sc scope) models.List {
+	list := s.lists[i]
+	list.Members = make([]models.ListMember, len(s.lists[i].Members))
+	copy(list.Members, s.lists[i].Members)
+	list.Role = s.roleIn(sc, list.ID)
+	if list.Role == models.RoleOwner {
+		for j := range s.invitations {
+			if s.invitations[j].ListID == list.ID {
+				list.Invitations = append(list.Invitations, s.invitationView(j))
+			}
+		}
+	}
+	return list
+}
+
+// invitationView completa la invitación de la posición j con el nombre
+// actual de su lista; requiere tener el lock tomado
+func (s *TodoStore) invitationView(j int) models.Invitation {
+	invitation := s.invitations[j]
+	if i := s.listIndexOf(invitation.WorkspaceID, invitation.ListID); i >= 0 {
+		invitation.ListName = s.lists[i].Name
+	}
+	invitation.TokenHash = ""
+	return invitation
+}
+
+// listIndexOf busca la posición de una lista del workspace; requiere tener el
+// lock tomado
+func (s *TodoStore) listIndexOf(workspace, id int) int {
+	for i, list := range s.lists {
+		if list.ID == id && list.WorkspaceID == workspace {
+			return i
+		}
+	}
+	return -1
+}
+
+// memberIndexOf busca la posición de un miembro en la lista de la posición
+// i; requiere tener el lock tomado
+func (s *TodoStore) memberIndexOf(i, user int) int {
+	for m, member := range s.lists[i].Members {
+		if member.UserID == user {
+			return m
+		}
+	}
+	return -1
+}
+
+// invitationIndexOf busca la posición de una invitación del workspace de sc;
+// requiere tener el lock tomado
+func (s *TodoStore) invitationIndexOf(sc scope, id int) int {
+	for j, invitation := range s.invitations {
+		if invitation.ID == id && invitation.WorkspaceID == sc.workspace.ID {
+			return j
+		}
+	}
+	return -1
+}
+
+// invitedIn indica si la invitación de la posición j está dirigida al usuario
+// de sc: a su email y dentro de su workspace. Requiere tener el lock tomado
+func (s *TodoStore) invitedIn(sc scope, j int) bool {
+	invitation := s.invitations[j]
+	return invitation.WorkspaceID == sc.workspace.ID && invitation.Email == normalizeEmail(sc.user.Email)
+}
+
+// without obtiene los usuarios de users que no están en exclude
+func without(users, exclude []int) []int {
+	kept := make([]int, 0, len(users))
+	for _, user := range users {
+		found := false
+		for _, other := range exclude {
+			if other == user {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, user)
+		}
+	}
+	return kept
+}
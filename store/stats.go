@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"todo-list/models"
+)
+
+// todoState representa el estado de un todo reconstruido desde la auditoría
+type todoState struct {
+	estimate  int
+	completed bool
+}
+
+// Stats calcula las estadísticas de estimación, el throughput semanal y el
+// burndown diario de los últimos days días a partir de la auditoría. Cuenta
+// también los todos archivados, igual que el burndown que sale de la auditoría
+func (s *TodoStore) Stats(ctx context.Context, days int) models.Stats {
+	todos := s.listAll(ctx)
+	entries := s.Audit(ctx)
+	return BuildStats(todos, entries, s.now(), days)
+}
+
+// listAll obtiene todos los todos que el usuario puede ver, archivados o no
+func (s *TodoStore) listAll(ctx context.Context) []models.Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	todos := make([]models.Todo, 0, len(s.todos))
+	for _, todo := range s.todos {
+		if s.canRead(sc, todo) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+// BuildStats construye las estadísticas para el rango que termina en now
+func BuildStats(todos []models.Todo, entries []models.AuditEntry, now time.Time, days int) models.Stats {
+	if days < 1 {
+		days = 1
+	}
+
+	stats := models.Stats{
+		Throughput: make([]models.WeeklyThroughput, 0),
+		Burndown:   make([]models.BurndownPoint, 0, days),
+	}
+	for _, todo := range todos {
+		stats.Total++
+		stats.EstimateTotal += todo.Estimate
+		if todo.Completed {
+			stats.Completed++
+			stats.EstimateCompleted += todo.Estimate
+		}
+	}
+	stats.Pending = stats.Total - stats.Completed
+	stats.EstimateRemaining = stats.EstimateTotal - stats.EstimateCompleted
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.Before(entries[j].At)
+	})
+
+	today := startOfDay(now)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	// Burndown: reproducir la auditoría hasta el cierre de cada día
+	state := make(map[int]*todoState)
+	next := 0
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		for next < len(entries) && entries[next].At.Before(end) {
+			apply(state, entries[next])
+			next++
+		}
+
+		point := models.BurndownPoint{Date: day}
+		for _, st := range state {
+			if st.completed {
+				point.Completed += st.estimate
+			} else {
+				point.Remaining += st.estimate
+			}
+		}
+		stats.Burndown = append(stats.Burndown, point)
+	}
+
+	// Throughput: completados agrupados por semana (lunes a domingo)
+	weeks := make(map[time.Time]*models.WeeklyThroughput)
+	for week := startOfWeek(from); !week.After(today); week = week.AddDate(0, 0, 7) {
+		stats.Throughput = append(stats.Throughput, models.WeeklyThroughput{WeekStart: week})
+	}
+	for i := range stats.Throughput {
+		weeks[stats.Throughput[i].WeekStart] = &stats.Throughput[i]
+	}
+	for _, entry := range entries {
+		if entry.Action != models.AuditCompleted || entry.At.Before(from) {
+			continue
+		}
+		if week, ok := weeks[startOfWeek(entry.At)]; ok {
+			week.Completed++
+			week.Points += entry.Estimate
+		}
+	}
+
+	return stats
+}
+
+// apply aplica una entrada de auditoría al estado reconstruido
+func apply(state map[int]*todoState, entry models.AuditEntry) {
+	switch entry.Action {
+	case models.AuditCreated:
+		state[entry.TodoID] = &todoState{estimate: entry.Estimate}
+	case models.AuditDeleted:
+		delete(state, entry.TodoID)
+	default:
+		st, ok := state[entry.TodoID]
+		if !ok {
+			return
+		}
+		st.estimate = entry.Estimate
+		switch entry.Action {
+		case models.AuditCompleted:
+			st.completed = true
+		case models.AuditReopened:
+			st.completed = false
+		}
+	}
+}
+
+// startOfDay retorna la medianoche del día de t
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// startOfWeek retorna el lunes de la semana de t
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
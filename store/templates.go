@@ -0,0 +1,323 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"todo-list/models"
+	"todo-list/validation"
+)
+
+// ErrTemplateNotFound se retorna cuando la plantilla solicitada no existe
+var ErrTemplateNotFound = errors.New("plantilla no encontrada")
+
+// placeholderPattern reconoce marcadores como {{name}} o {{ name }}
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// MissingValuesError se retorna al instanciar sin todos los valores requeridos
+type MissingValuesError struct {
+	Names []string
+}
+
+// Error implementa la interfaz error
+func (e *MissingValuesError) Error() string {
+	return fmt.Sprintf("faltan valores para: %s", strings.Join(e.Names, ", "))
+}
+
+// InvalidValuesError se retorna cuando los valores de los marcadores dejan
+// inválida alguna tarea de la plantilla, por ejemplo un título de más de 200
+// caracteres. Names son los marcadores de los campos inválidos y Errors los
+// errores de validación, uno por marcador y campo
+type InvalidValuesError struct {
+	Names  []string
+	Errors []models.FieldError
+}
+
+// Error implementa la interfaz error
+func (e *InvalidValuesError) Error() string {
+	return fmt.Sprintf("los valores de %s dejan tareas inválidas", strings.Join(e.Names, ", "))
+}
+
+// add registra un error de validación de la tarea que sale del item i, a
+// nombre de cada marcador del campo que falló
+func (e *InvalidValuesError) add(i int, item models.TemplateItem, fieldErr models.FieldError) {
+	field := fmt.Sprintf("items[%d].%s", i, fieldErr.Field)
+	names := placeholdersIn(fieldSource(item, fieldErr.Field)...)
+	if len(names) == 0 {
+		e.Errors = append(e.Errors, models.FieldError{Field: field, Code: fieldErr.Code, Message: fieldErr.Message})
+		return
+	}
+	for _, name := range names {
+		if !containsName(e.Names, name) {
+			e.Names = append(e.Names, name)
+		}
+		e.Errors = append(e.Errors, models.FieldError{
+			Field:   "values." + name,
+			Code:    fieldErr.Code,
+			Message: fmt.Sprintf("Con el valor de %s, %s queda inválido: %s", name, field, fieldErr.Message),
+		})
+	}
+}
+
+// containsName indica si name está en names
+func containsName(names []string, name string) bool {
+	for _, other := range names {
+		if other == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ListTemplates obtiene las plantillas del usuario
+func (s *TodoStore) ListTemplates(ctx context.Context) []models.TodoTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	templates := make([]models.TodoTemplate, 0, len(s.templates))
+	for _, tmpl := range s.templates {
+		if sc.owns(tmpl.WorkspaceID, tmpl.OwnerID) {
+			templates = append(templates, tmpl)
+		}
+	}
+	return templates
+}
+
+// GetTemplate obtiene una plantilla del usuario por ID
+func (s *TodoStore) GetTemplate(ctx context.Context, id int) (models.TodoTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.templateIndexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return models.TodoTemplate{}, ErrTemplateNotFound
+	}
+	return s.templates[i], nil
+}
+
+// CreateTemplate crea una nueva plantilla del usuario
+func (s *TodoStore) CreateTemplate(ctx context.Context, req models.TodoTemplateRequest) (models.TodoTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	now := s.now()
+	tmpl := models.TodoTemplate{
+		ID:          s.nextTemplateID,
+		WorkspaceID: sc.workspace.ID,
+		OwnerID:     sc.user.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Items:       copyItems(req.Items),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.templates = append(s.templates, tmpl)
+	s.nextTemplateID++
+	return tmpl, nil
+}
+
+// UpdateTemplate reemplaza el contenido de una plantilla existente
+func (s *TodoStore) UpdateTemplate(ctx context.Context, id int, req models.TodoTemplateRequest) (models.TodoTemplate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.templateIndexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return models.TodoTemplate{}, ErrTemplateNotFound
+	}
+
+	tmpl := &s.templates[i]
+	tmpl.Name = req.Name
+	tmpl.Description = req.Description
+	tmpl.Items = copyItems(req.Items)
+	tmpl.UpdatedAt = s.now()
+	return *tmpl, nil
+}
+
+// DeleteTemplate elimina una plantilla
+func (s *TodoStore) DeleteTemplate(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.templateIndexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return ErrTemplateNotFound
+	}
+
+	s.templates = append(s.templates[:i], s.templates[i+1:]...)
+	return nil
+}
+
+// InstantiateTemplate crea los todos de una plantilla reemplazando los
+// marcadores con los valores recibidos. Las fechas límite se calculan desde
+// StartDate o desde hoy
+func (s *TodoStore) InstantiateTemplate(ctx context.Context, id int, req models.InstantiateRequest) ([]models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	sc := scopeOf(ctx)
+	i := s.templateIndexOf(sc, id)
+	if i < 0 {
+		return nil, ErrTemplateNotFound
+	}
+	tmpl := s.templates[i]
+	if !s.hasRoom(sc.workspace, len(tmpl.Items)) {
+		return nil, ErrQuotaExceeded
+	}
+
+	// Validar antes de crear para no dejar la instanciación a medias
+	var missing []string
+	for _, name := range Placeholders(tmpl) {
+		if strings.TrimSpace(req.Values[name]) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingValuesError{Names: missing}
+	}
+
+	start := s.now()
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+
+	// Los valores pueden dejar una tarea inválida (un título demasiado largo,
+	// una etiqueta vacía): se validan todas antes de crear la primera
+	todoReqs := make([]models.TodoRequest, 0, len(tmpl.Items))
+	var invalid InvalidValuesError
+	for i, item := range tmpl.Items {
+		todoReq := models.TodoRequest{
+			Title:       substitute(item.Title, req.Values),
+			Description: substitute(item.Description, req.Values),
+			Estimate:    item.Estimate,
+			Tags:        make([]string, 0, len(item.Tags)),
+			Checklist:   make([]models.ChecklistItem, 0, len(item.Checklist)),
+		}
+		for _, tag := range item.Tags {
+			todoReq.Tags = append(todoReq.Tags, substitute(tag, req.Values))
+		}
+		for _, step := range item.Checklist {
+			todoReq.Checklist = append(todoReq.Checklist, models.ChecklistItem{Text: substitute(step, req.Values)})
+		}
+		if item.DueOffsetDays != nil {
+			due := startOfDay(start).AddDate(0, 0, *item.DueOffsetDays)
+			todoReq.DueDate = &due
+		}
+		for _, fieldErr := range validation.Struct(&todoReq, validation.DefaultLang) {
+			invalid.add(i, item, fieldErr)
+		}
+		todoReqs = append(todoReqs, todoReq)
+	}
+	if len(invalid.Errors) > 0 {
+		sort.Strings(invalid.Names)
+		return nil, &invalid
+	}
+
+	todos := make([]models.Todo, 0, len(todoReqs))
+	for _, todoReq := range todoReqs {
+		todo, err := s.create(sc, todoReq)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// Placeholders obtiene los nombres de los marcadores usados en una plantilla
+func Placeholders(tmpl models.TodoTemplate) []string {
+	var texts []string
+	for _, item := range tmpl.Items {
+		texts = append(texts, item.Title, item.Description)
+		texts = append(texts, item.Tags...)
+		texts = append(texts, item.Checklist...)
+	}
+	return placeholdersIn(texts...)
+}
+
+// placeholdersIn obtiene los nombres de los marcadores de los textos, ordenados
+func placeholdersIn(texts ...string) []string {
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			seen[match[1]] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldSource obtiene los textos del item de los que sale un campo de la
+// tarea instanciada: "title", "tags[1]" o "checklist[0].text"
+func fieldSource(item models.TemplateItem, field string) []string {
+	name, rest, indexed := strings.Cut(field, "[")
+	var texts []string
+	switch name {
+	case "title":
+		return []string{item.Title}
+	case "description":
+		return []string{item.Description}
+	case "tags":
+		texts = item.Tags
+	case "checklist":
+		texts = item.Checklist
+	default:
+		return nil
+	}
+	if indexed {
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return texts
+		}
+		if i, err := strconv.Atoi(rest[:end]); err == nil && i >= 0 && i < len(texts) {
+			return texts[i : i+1]
+		}
+	}
+	return texts
+}
+
+// substitute reemplaza los marcadores de text con los valores recibidos
+func substitute(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// templateIndexOf busca la posición de una plantilla del usuario de sc;
+// requiere tener el lock tomado
+func (s *TodoStore) templateIndexOf(sc scope, id int) int {
+	for i, tmpl := range s.templates {
+		if tmpl.ID == id && sc.owns(tmpl.WorkspaceID, tmpl.OwnerID) {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyItems copia los items de una plantilla para no compartir memoria con la petición
+func copyItems(items []models.TemplateItem) []models.TemplateItem {
+	copied := make([]models.TemplateItem, len(items))
+	for i, item := range items {
+		copied[i] = item
+		copied[i].Checklist = copyTags(item.Checklist)
+		copied[i].Tags = copyTags(item.Tags)
+	}
+	return copied
+}
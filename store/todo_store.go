@@ -0,0 +1,418 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"todo-list/markdown"
+	"todo-list/models"
+)
+
+// ErrNotFound se retorna cuando el todo solicitado no existe
+var ErrNotFound = errors.New("todo no encontrado")
+
+// ErrPreconditionFailed se retorna cuando la precondición de una escritura
+// condicional no se cumple (por ejemplo, un If-Match con una versión antigua)
+var ErrPreconditionFailed = errors.New("la versión del todo no coincide")
+
+// Precondition decide si una escritura condicional puede aplicarse sobre el
+// estado actual del todo; se evalúa con el lock tomado
+type Precondition func(current models.Todo) bool
+
+// TodoStore almacena los todos en memoria y es seguro para uso concurrente
+type TodoStore struct {
+	mu             sync.RWMutex
+	todos          []models.Todo
+	nextID         int
+	audit          []models.AuditEntry
+	templates      []models.TodoTemplate
+	nextTemplateID int
+	lists          []models.List
+	nextListID     int
+	invitations    []models.Invitation
+	nextInviteID   int
+	now            func() time.Time
+	publisher      Publisher
+	pending        []models.ChangeEvent
+}
+
+// NewTodoStore crea un store vacío
+func NewTodoStore() *TodoStore {
+	return &TodoStore{
+		todos:          make([]models.Todo, 0),
+		nextID:         1,
+		templates:      make([]models.TodoTemplate, 0),
+		nextTemplateID: 1,
+		lists:          make([]models.List, 0),
+		nextListID:     1,
+		invitations:    make([]models.Invitation, 0),
+		nextInviteID:   1,
+		now:            time.Now,
+	}
+}
+
+// List obtiene los todos que el usuario puede ver (los suyos y los de sus
+// listas compartidas) y que no están archivados
+func (s *TodoStore) List(ctx context.Context) []models.Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	todos := make([]models.Todo, 0, len(s.todos))
+	for _, todo := range s.todos {
+		if !todo.Archived && s.canRead(sc, todo) {
+			todos = append(todos, todo)
+		}
+	}
+	return todos
+}
+
+// Get obtiene un todo visible para el usuario por ID
+func (s *TodoStore) Get(ctx context.Context, id int) (models.Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return models.Todo{}, ErrNotFound
+	}
+	return s.todos[i], nil
+}
+
+// Create crea un nuevo todo del usuario a partir de la petición
+func (s *TodoStore) Create(ctx context.Context, req models.TodoRequest) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	return s.create(scopeOf(ctx), req)
+}
+
+// create inserta un nuevo todo del usuario de sc, en su lista personal o en
+// la lista compartida de req.ListID si es al menos editor. El responsable y
+// los observadores deben poder ver el todo. Falla con
+// ErrQuotaExceeded si el workspace llegó a su límite; requiere tener el lock
+// tomado
+func (s *TodoStore) create(sc scope, req models.TodoRequest) (models.Todo, error) {
+	if !s.hasRoom(sc.workspace, 1) {
+		return models.Todo{}, ErrQuotaExceeded
+	}
+	listID := 0
+	if req.ListID != nil && *req.ListID != 0 {
+		if _, err := s.requireRole(sc, *req.ListID, models.RoleEditor); err != nil {
+			return models.Todo{}, err
+		}
+		listID = *req.ListID
+	}
+
+	now := s.now()
+	todo := models.Todo{
+		ID:              s.nextID,
+		WorkspaceID:     sc.workspace.ID,
+		OwnerID:         sc.user.ID,
+		ListID:          listID,
+		Title:           req.Title,
+		Description:     req.Description,
+		DescriptionHTML: markdown.Render(req.Description),
+		Completed:       req.Completed,
+		Estimate:        req.Estimate,
+		Tags:            copyTags(req.Tags),
+		Checklist:       copyChecklist(req.Checklist),
+		DueDate:         req.DueDate,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		Version:         1,
+	}
+	if todo.Completed {
+		todo.CompletedAt = &now
+	}
+	if err := s.assign(&todo, req.AssigneeID, req.Watchers); err != nil {
+		return models.Todo{}, err
+	}
+
+	s.todos = append(s.todos, todo)
+	s.nextID++
+
+	s.record(todo, models.AuditCreated, todo.Estimate, now)
+	if todo.Completed {
+		s.record(todo, models.AuditCompleted, todo.Estimate, now)
+	}
+	s.emit(models.EventTodoCreated, &todo)
+	return todo, nil
+}
+
+// Update reemplaza los campos editables de un todo existente
+func (s *TodoStore) Update(ctx context.Context, id int, req models.TodoRequest) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	return s.update(scopeOf(ctx), id, req)
+}
+
+// UpdateIf actualiza un todo solo si cond se cumple sobre su estado actual
+func (s *TodoStore) UpdateIf(ctx context.Context, id int, req models.TodoRequest, cond Precondition) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	sc := scopeOf(ctx)
+	if err := s.check(sc, id, cond); err != nil {
+		return models.Todo{}, err
+	}
+	return s.update(sc, id, req)
+}
+
+// PatchIf aplica una actualización parcial solo si cond se cumple; cond
+// puede ser nil para no exigir ninguna precondición
+func (s *TodoStore) PatchIf(ctx context.Context, id int, patch models.TodoPatch, cond Precondition) (models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	sc := scopeOf(ctx)
+	if err := s.check(sc, id, cond); err != nil {
+		return models.Todo{}, err
+	}
+
+	current := s.todos[s.indexOf(sc, id)]
+	req := models.TodoRequest{
+		Title:       current.Title,
+		Description: current.Description,
+		Completed:   current.Completed,
+		Estimate:    current.Estimate,
+		DueDate:     patch.DueDate,
+		ListID:      patch.ListID,
+		AssigneeID:  patch.AssigneeID,
+	}
+	if patch.Title != nil {
+		req.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		req.Description = *patch.Description
+	}
+	if patch.Completed != nil {
+		req.Completed = *patch.Completed
+	}
+	if patch.Estimate != nil {
+		req.Estimate = *patch.Estimate
+	}
+	if patch.Tags != nil {
+		req.Tags = *patch.Tags
+	}
+	if patch.Checklist != nil {
+		req.Checklist = *patch.Checklist
+	}
+	if patch.Watchers != nil {
+		req.Watchers = *patch.Watchers
+	}
+	return s.update(sc, id, req)
+}
+
+// update aplica la actualización de un todo que el usuario de sc puede
+// modificar. Mover el todo a otra lista exige ser editor también en la de
+// destino. Moverlo a la lista personal lo deja a nombre del usuario, así que
+// exige ser su dueño u owner de la lista. Quien deja de ver el todo al moverlo
+// pierde la asignación, y un cambio de responsable queda en la auditoría.
+// Reabrir un todo archivado lo desarchiva. Requiere tener el lock tomado
+func (s *TodoStore) update(sc scope, id int, req models.TodoRequest) (models.Todo, error) {
+	i, err := s.writableIndexOf(sc, id)
+	if err != nil {
+		return models.Todo{}, err
+	}
+
+	now := s.now()
+	todo := &s.todos[i]
+	before := *todo
+	next := *todo
+	if req.ListID != nil && *req.ListID != todo.ListID {
+		if *req.ListID != 0 {
+			if _, err := s.requireRole(sc, *req.ListID, models.RoleEditor); err != nil {
+				return models.Todo{}, err
+			}
+		} else {
+			if todo.OwnerID != sc.user.ID {
+				if _, err := s.requireRole(sc, todo.ListID, models.RoleOwner); err != nil {
+					return models.Todo{}, err
+				}
+			}
+			next.OwnerID = sc.user.ID
+		}
+		next.ListID = *req.ListID
+	}
+	if err := s.assign(&next, req.AssigneeID, req.Watchers); err != nil {
+		return models.Todo{}, err
+	}
+	todo.OwnerID, todo.ListID = next.OwnerID, next.ListID
+	todo.AssigneeID, todo.Watchers = next.AssigneeID, next.Watchers
+
+	eventType := models.EventTodoUpdated
+	if todo.Estimate != req.Estimate {
+		s.record(*todo, models.AuditUpdated, req.Estimate, now)
+	}
+	if req.Completed && !todo.Completed {
+		todo.CompletedAt = &now
+		s.record(*todo, models.AuditCompleted, req.Estimate, now)
+		eventType = models.EventTodoCompleted
+	} else if !req.Completed && todo.Completed {
+		todo.CompletedAt = nil
+		s.record(*todo, models.AuditReopened, req.Estimate, now)
+		// Solo se archivan tareas completadas: reabrirla la desarchiva
+		if todo.Archived {
+			todo.Archived = false
+			todo.ArchivedAt = nil
+			s.record(*todo, models.AuditUnarchived, req.Estimate, now)
+		}
+	}
+
+	todo.Title = req.Title
+	todo.Description = req.Description
+	todo.DescriptionHTML = markdown.Render(req.Description)
+	todo.Completed = req.Completed
+	todo.Estimate = req.Estimate
+	if req.Tags != nil {
+		todo.Tags = copyTags(req.Tags)
+	}
+	if req.Checklist != nil {
+		todo.Checklist = copyChecklist(req.Checklist)
+	}
+	if req.DueDate != nil {
+		todo.DueDate = req.DueDate
+	}
+	todo.UpdatedAt = now
+	todo.Version++
+	if todo.AssigneeID != before.AssigneeID {
+		s.recordReassign(*todo, before.AssigneeID, now)
+	}
+	if todo.ListID != before.ListID {
+		// Quienes dejan de ver el todo lo reciben como eliminado
+		s.emitTo(models.EventTodoDeleted, &before, without(s.audience(before), s.audience(*todo)))
+	}
+	s.emit(eventType, todo)
+	return *todo, nil
+}
+
+// Delete elimina un todo
+func (s *TodoStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	return s.delete(scopeOf(ctx), id)
+}
+
+// DeleteIf elimina un todo solo si cond se cumple sobre su estado actual
+func (s *TodoStore) DeleteIf(ctx context.Context, id int, cond Precondition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.flush()
+
+	sc := scopeOf(ctx)
+	if err := s.check(sc, id, cond); err != nil {
+		return err
+	}
+	return s.delete(sc, id)
+}
+
+// delete elimina un todo que el usuario de sc puede modificar; requiere tener
+// el lock tomado
+func (s *TodoStore) delete(sc scope, id int) error {
+	i, err := s.writableIndexOf(sc, id)
+	if err != nil {
+		return err
+	}
+
+	s.remove(i)
+	return nil
+}
+
+// remove elimina el todo de la posición i; requiere tener el lock tomado
+func (s *TodoStore) remove(i int) {
+	removed := s.todos[i]
+	s.record(removed, models.AuditDeleted, removed.Estimate, s.now())
+	s.todos = append(s.todos[:i], s.todos[i+1:]...)
+	s.emit(models.EventTodoDeleted, &removed)
+}
+
+// Audit obtiene el historial de cambios de los todos del usuario
+func (s *TodoStore) Audit(ctx context.Context) []models.AuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	entries := make([]models.AuditEntry, 0, len(s.audit))
+	for _, entry := range s.audit {
+		if sc.owns(entry.WorkspaceID, entry.OwnerID) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// check verifica que el usuario de sc pueda modificar el todo y que cumpla la
+// precondición; requiere tener el lock tomado
+func (s *TodoStore) check(sc scope, id int, cond Precondition) error {
+	i, err := s.writableIndexOf(sc, id)
+	if err != nil {
+		return err
+	}
+	if cond != nil && !cond(s.todos[i]) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// indexOf busca la posición de un todo que el usuario de sc puede ver; los
+// demás, incluidos los de otros workspaces, no se encuentran. Requiere tener
+// el lock tomado
+func (s *TodoStore) indexOf(sc scope, id int) int {
+	for i, todo := range s.todos {
+		if todo.ID == id && s.canRead(sc, todo) {
+			return i
+		}
+	}
+	return -1
+}
+
+// writableIndexOf busca la posición de un todo que el usuario de sc puede
+// modificar. Si lo ve pero su rol en la lista no alcanza retorna
+// ErrForbidden. Requiere tener el lock tomado
+func (s *TodoStore) writableIndexOf(sc scope, id int) (int, error) {
+	i := s.indexOf(sc, id)
+	if i < 0 {
+		return -1, ErrNotFound
+	}
+	if !s.canWrite(sc, s.todos[i]) {
+		return -1, ErrForbidden
+	}
+	return i, nil
+}
+
+// record agrega una entrada de auditoría sobre todo, a nombre de su dueño;
+// requiere tener el lock tomado
+func (s *TodoStore) record(todo models.Todo, action string, estimate int, at time.Time) {
+	s.audit = append(s.audit, models.AuditEntry{
+		TodoID:      todo.ID,
+		WorkspaceID: todo.WorkspaceID,
+		OwnerID:     todo.OwnerID,
+		Action:      action,
+		Estimate:    estimate,
+		AssigneeID:  todo.AssigneeID,
+		At:          at,
+	})
+}
+
+// copyTags copia las etiquetas para no compartir memoria con la petición
+func copyTags(tags []string) []string {
+	copied := make([]string, len(tags))
+	copy(copied, tags)
+	return copied
+}
+
+// copyChecklist copia el checklist para no compartir memoria con la petición
+func copyChecklist(items []models.ChecklistItem) []models.ChecklistItem {
+	copied := make([]models.ChecklistItem, len(items))
+	copy(copied, items)
+	return copied
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"todo-list/models"
+)
+
+// ErrTokenNotFound se retorna cuando el token personal no existe, es de otro
+// usuario o ya venció
+var ErrTokenNotFound = errors.New("token no encontrado")
+
+// TokenPrefix inicia el valor de todos los tokens personales, para
+// reconocerlos en el header Authorization y en los escáneres de secretos
+const TokenPrefix = "tdl_"
+
+// tokenRecord guarda un token personal junto con el hash de su valor
+type tokenRecord struct {
+	token models.APIToken
+	hash  string
+}
+
+// CreateToken crea un token personal para el usuario del contexto. El valor
+// solo viaja en el token retornado; el store guarda su hash
+func (s *UserStore) CreateToken(ctx context.Context, req models.APITokenRequest) (models.APIToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return models.APIToken{}, err
+	}
+	value := TokenPrefix + hex.EncodeToString(raw)
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{models.ScopeTodosRead, models.ScopeTodosWrite}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	now := s.now()
+	token := models.APIToken{
+		ID:          s.nextToken,
+		WorkspaceID: sc.workspace.ID,
+		OwnerID:     sc.user.ID,
+		Name:        req.Name,
+		Prefix:      value[:len(TokenPrefix)+8],
+		Scopes:      append([]string(nil), scopes...),
+		CreatedAt:   now,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	s.tokens = append(s.tokens, tokenRecord{token: token, hash: tokenKey(value)})
+	s.nextToken++
+
+	token.Token = value
+	return token, nil
+}
+
+// ListTokens obtiene los tokens personales del usuario del contexto, incluidos
+// los vencidos, sin su valor
+func (s *UserStore) ListTokens(ctx context.Context) []models.APIToken {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc := scopeOf(ctx)
+	tokens := make([]models.APIToken, 0)
+	for _, record := range s.tokens {
+		if sc.owns(record.token.WorkspaceID, record.token.OwnerID) {
+			tokens = append(tokens, record.token)
+		}
+	}
+	return tokens
+}
+
+// RevokeToken elimina un token personal del usuario del contexto; deja de
+// funcionar en la siguiente petición
+func (s *UserStore) RevokeToken(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	for i, record := range s.tokens {
+		if record.token.ID == id && sc.owns(record.token.WorkspaceID, record.token.OwnerID) {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// TokenUser obtiene el usuario y el token personal que corresponden al valor
+// de un token vigente del workspace de ctx, y registra su uso en LastUsedAt
+func (s *UserStore) TokenUser(ctx context.Context, value string) (models.User, models.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	hash := tokenKey(value)
+	for i := range s.tokens {
+		record := &s.tokens[i]
+		if record.hash != hash || record.token.WorkspaceID != WorkspaceID(ctx) {
+			continue
+		}
+		if record.token.ExpiresAt != nil && !now.Before(*record.token.ExpiresAt) {
+			break
+		}
+		for _, user := range s.users {
+			if user.user.ID == record.token.OwnerID {
+				record.token.LastUsedAt = &now
+				return user.user, record.token, nil
+			}
+		}
+		break
+	}
+	return models.User{}, models.APIToken{}, ErrTokenNotFound
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo-list/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrEmailTaken se retorna al registrar un email que ya tiene cuenta
+var ErrEmailTaken = errors.New("el email ya está registrado")
+
+// ErrInvalidCredentials se retorna cuando el email o la contraseña no coinciden
+var ErrInvalidCredentials = errors.New("email o contraseña incorrectos")
+
+// ErrSessionNotFound se retorna cuando la sesión no existe o ya venció
+var ErrSessionNotFound = errors.New("sesión no encontrada")
+
+// ErrUserNotFound se retorna cuando el usuario solicitado no existe
+var ErrUserNotFound = errors.New("usuario no encontrado")
+
+// ErrEmailNotVerified se retorna cuando una identidad externa no tiene un
+// email verificado por su proveedor
+var ErrEmailNotVerified = errors.New("el proveedor no verificó el email")
+
+// userRecord guarda un usuario junto con el hash de su contraseña
+type userRecord struct {
+	user         models.User
+	passwordHash []byte
+}
+
+// session representa una sesión iniciada
+type session struct {
+	userID    int
+	expiresAt time.Time
+}
+
+// UserStore almacena en memoria las cuentas, sus sesiones y sus tokens
+// personales. Es seguro para uso concurrente
+type UserStore struct {
+	mu         sync.RWMutex
+	users      []userRecord
+	nextID     int
+	sessions   map[string]session
+	sessionTTL time.Duration
+	tokens     []tokenRecord
+	nextToken  int
+	identities map[string]int
+	now        func() time.Time
+
+	dummyOnce sync.Once
+	dummyHash []byte
+}
+
+// NewUserStore crea un store de usuarios vacío cuyas sesiones duran sessionTTL
+func NewUserStore(sessionTTL time.Duration) *UserStore {
+	return &UserStore{
+		users:      make([]userRecord, 0),
+		nextID:     1,
+		sessions:   make(map[string]session),
+		sessionTTL: sessionTTL,
+		tokens:     make([]tokenRecord, 0),
+		nextToken:  1,
+		identities: make(map[string]int),
+		now:        time.Now,
+	}
+}
+
+// Register crea una cuenta en el workspace de ctx. La contraseña se guarda
+// con bcrypt
+func (s *UserStore) Register(ctx context.Context, req models.RegisterRequest) (models.User, error) {
+	// bcrypt es lento a propósito: calcular el hash antes de tomar el lock
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspace := WorkspaceID(ctx)
+	email := normalizeEmail(req.Email)
+	if s.indexOfEmail(workspace, email) >= 0 {
+		return models.User{}, ErrEmailTaken
+	}
+	user := models.User{
+		ID:          s.nextID,
+		WorkspaceID: workspace,
+		Email:       email,
+		Name:        req.Name,
+		CreatedAt:   s.now(),
+	}
+	s.users = append(s.users, userRecord{user: user, passwordHash: hash})
+	s.nextID++
+	return user, nil
+}
+
+// Authenticate verifica el email y la contraseña de una cuenta del workspace
+// de ctx. Si el email no existe se compara igual contra un hash de relleno
+// para no revelar qué cuentas existen por el tiempo de respuesta
+func (s *UserStore) Authenticate(ctx context.Context, email, password string) (models.User, error) {
+	s.mu.RLock()
+	i := s.indexOfEmail(WorkspaceID(ctx), normalizeEmail(email))
+	var record userRecord
+	if i >= 0 {
+		record = s.users[i]
+	}
+	s.mu.RUnlock()
+
+	if i < 0 {
+		bcrypt.CompareHashAndPassword(s.dummy(), []byte(password))
+		return models.User{}, ErrInvalidCredentials
+	}
+	// Las cuentas creadas con OIDC no tienen contraseña y no se comparan
+	if record.passwordHash == nil || bcrypt.CompareHashAndPassword(record.passwordHash, []byte(password)) != nil {
+		return models.User{}, ErrInvalidCredentials
+	}
+	return record.user, nil
+}
+
+// ExternalUser obtiene la cuenta del workspace de ctx vinculada a una
+// identidad externa. La primera vez la vincula a la cuenta con el mismo email
+// o crea una sin contraseña; en ambos casos el proveedor debe haber
+// verificado el email. Una misma identidad tiene una cuenta por workspace
+func (s *UserStore) ExternalUser(ctx context.Context, identity models.Identity) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspace := WorkspaceID(ctx)
+	key := strconv.Itoa(workspace) + " " + identity.Issuer + " " + identity.Subject
+	if id, ok := s.identities[key]; ok {
+		for _, record := range s.users {
+			if record.user.ID == id {
+				return record.user, nil
+			}
+		}
+	}
+	if identity.Email == "" {
+		return models.User{}, ErrUserNotFound
+	}
+	if !identity.EmailVerified {
+		return models.User{}, ErrEmailNotVerified
+	}
+
+	email := normalizeEmail(identity.Email)
+	if i := s.indexOfEmail(workspace, email); i >= 0 {
+		s.identities[key] = s.users[i].user.ID
+		return s.users[i].user, nil
+	}
+	name := identity.Name
+	if name == "" {
+		name = email
+	}
+	user := models.User{
+		ID:          s.nextID,
+		WorkspaceID: workspace,
+		Email:       email,
+		Name:        name,
+		CreatedAt:   s.now(),
+	}
+	s.users = append(s.users, userRecord{user: user})
+	s.identities[key] = user.ID
+	s.nextID++
+	return user, nil
+}
+
+// Get obtiene un usuario del workspace de ctx por ID
+func (s *UserStore) Get(ctx context.Context, id int) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, record := range s.users {
+		if record.user.ID == id && record.user.WorkspaceID == WorkspaceID(ctx) {
+			return record.user, nil
+		}
+	}
+	return models.User{}, ErrUserNotFound
+}
+
+// CreateSession inicia una sesión para el usuario y retorna su token. Solo se
+// guarda el hash del token, así que no se puede recuperar después
+func (s *UserStore) CreateSession(ctx context.Context, userID int) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, sess := range s.sessions {
+		if !now.Before(sess.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+	expiresAt := now.Add(s.sessionTTL)
+	s.sessions[tokenKey(token)] = session{userID: userID, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+// SessionUser obtiene el usuario de una sesión vigente. Una sesión solo vale
+// en el workspace donde se inició
+func (s *UserStore) SessionUser(ctx context.Context, token string) (models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[tokenKey(token)]
+	if !ok || !s.now().Before(sess.expiresAt) {
+		return models.User{}, ErrSessionNotFound
+	}
+	for _, record := range s.users {
+		if record.user.ID == sess.userID && record.user.WorkspaceID == WorkspaceID(ctx) {
+			return record.user, nil
+		}
+	}
+	return models.User{}, ErrSessionNotFound
+}
+
+// DeleteSession cierra una sesión; no hace nada si no existe
+func (s *UserStore) DeleteSession(ctx context.Context, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, tokenKey(token))
+}
+
+// indexOfEmail busca la posición de un usuario del workspace por email;
+// requiere tener el lock tomado
+func (s *UserStore) indexOfEmail(workspace int, email string) int {
+	for i, record := range s.users {
+		if record.user.Email == email && record.user.WorkspaceID == workspace {
+			return i
+		}
+	}
+	return -1
+}
+
+// dummy obtiene el hash de relleno que usa Authenticate con emails desconocidos
+func (s *UserStore) dummy() []byte {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return s.dummyHash
+}
+
+// normalizeEmail compara los emails sin distinguir mayúsculas
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// tokenKey es la clave con la que se guarda una sesión o un token personal: el hash del token
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,474 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"todo-list/models"
+)
+
+// ErrWebhookNotFound se retorna cuando el webhook solicitado no existe
+var ErrWebhookNotFound = errors.New("webhook no encontrado")
+
+// ErrDeliveryNotFound se retorna cuando la entrega solicitada no existe
+var ErrDeliveryNotFound = errors.New("entrega no encontrada")
+
+// ErrDeliveryNotDead se retorna al reentregar algo que no está en dead letters
+var ErrDeliveryNotDead = errors.New("solo se pueden reentregar las entregas en dead letters")
+
+// ErrWebhookTarget se retorna cuando la URL del webhook apunta a un destino
+// que TargetChecker no permite
+var ErrWebhookTarget = errors.New("la URL del webhook apunta a un destino no permitido")
+
+// TargetChecker revisa que la URL de un webhook apunte a un destino permitido
+type TargetChecker func(ctx context.Context, rawURL string) error
+
+// maxDeliveries es cuántas entregas se recuerdan; al pasarse se olvidan las
+// exitosas más antiguas. Las pendientes y las dead letters no se descartan
+const maxDeliveries = 1000
+
+// DeliveryClaim es una entrega lista para enviarse junto con el destino
+type DeliveryClaim struct {
+	Delivery models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// WebhookStore almacena en memoria los webhooks y sus entregas, y hace de cola
+// para el dispatcher. Es seguro para uso concurrente
+type WebhookStore struct {
+	mu             sync.Mutex
+	webhooks       []models.Webhook
+	nextID         int
+	deliveries     []models.WebhookDelivery
+	nextDeliveryID int
+	wake           chan struct{}
+	now            func() time.Time
+	checkTarget    TargetChecker
+}
+
+// NewWebhookStore crea un store de webhooks vacío
+func NewWebhookStore() *WebhookStore {
+	return &WebhookStore{
+		webhooks:       make([]models.Webhook, 0),
+		nextID:         1,
+		deliveries:     make([]models.WebhookDelivery, 0),
+		nextDeliveryID: 1,
+		wake:           make(chan struct{}, 1),
+		now:            time.Now,
+	}
+}
+
+// SetTargetChecker configura la revisión de las URLs al crear y actualizar
+// webhooks; nil las acepta todas
+func (s *WebhookStore) SetTargetChecker(check TargetChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkTarget = check
+}
+
+// List obtiene los webhooks del usuario, sin sus secretos
+func (s *WebhookStore) List(ctx context.Context) []models.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	webhooks := make([]models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		if sc.owns(webhook.WorkspaceID, webhook.OwnerID) {
+			webhooks = append(webhooks, redact(webhook))
+		}
+	}
+	return webhooks
+}
+
+// Get obtiene un webhook del usuario por ID, sin su secreto
+func (s *WebhookStore) Get(ctx context.Context, id int) (models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	return redact(s.webhooks[i]), nil
+}
+
+// Create registra un webhook del usuario. Es la única respuesta que incluye
+// el secreto
+func (s *WebhookStore) Create(ctx context.Context, req models.WebhookRequest) (models.Webhook, error) {
+	if err := s.allowTarget(ctx, req.URL); err != nil {
+		return models.Webhook{}, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return models.Webhook{}, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	now := s.now()
+	webhook := models.Webhook{
+		ID:          s.nextID,
+		WorkspaceID: sc.workspace.ID,
+		OwnerID:     sc.user.ID,
+		URL:         req.URL,
+		Events:      copyTags(req.Events),
+		Active:      req.Active == nil || *req.Active,
+		Secret:      secret,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.webhooks = append(s.webhooks, webhook)
+	s.nextID++
+	return webhook, nil
+}
+
+// Update reemplaza la URL, los eventos y el estado de un webhook; el secreto
+// solo cambia si se envía uno nuevo
+func (s *WebhookStore) Update(ctx context.Context, id int, req models.WebhookRequest) (models.Webhook, error) {
+	if err := s.allowTarget(ctx, req.URL); err != nil {
+		return models.Webhook{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+
+	webhook := &s.webhooks[i]
+	webhook.URL = req.URL
+	webhook.Events = copyTags(req.Events)
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	webhook.UpdatedAt = s.now()
+	return redact(*webhook), nil
+}
+
+// Delete elimina un webhook junto con sus entregas
+func (s *WebhookStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(scopeOf(ctx), id)
+	if i < 0 {
+		return ErrWebhookNotFound
+	}
+	s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+
+	kept := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID != id {
+			kept = append(kept, delivery)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// Deliveries obtiene el registro de entregas de un webhook, las más recientes primero
+func (s *WebhookStore) Deliveries(ctx context.Context, webhookID int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.indexOf(scopeOf(ctx), webhookID) < 0 {
+		return nil, ErrWebhookNotFound
+	}
+	return s.filterDeliveries(func(d models.WebhookDelivery) bool {
+		return d.WebhookID == webhookID
+	}), nil
+}
+
+// DeadLetters obtiene las entregas de los webhooks del usuario que agotaron
+// sus reintentos, las más recientes primero
+func (s *WebhookStore) DeadLetters(ctx context.Context) []models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc := scopeOf(ctx)
+	return s.filterDeliveries(func(d models.WebhookDelivery) bool {
+		return d.Status == models.DeliveryDead && s.indexOf(sc, d.WebhookID) >= 0
+	})
+}
+
+// Redeliver vuelve a poner en cola una entrega de dead letters, con todos sus
+// reintentos disponibles
+func (s *WebhookStore) Redeliver(ctx context.Context, id int) (models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.deliveryIndex(id)
+	if i < 0 || s.indexOf(scopeOf(ctx), s.deliveries[i].WebhookID) < 0 {
+		return models.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	delivery := &s.deliveries[i]
+	if delivery.Status != models.DeliveryDead {
+		return models.WebhookDelivery{}, ErrDeliveryNotDead
+	}
+
+	now := s.now()
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.UpdatedAt = now
+	s.notify()
+	return copyDelivery(*delivery), nil
+}
+
+// Enqueue crea una entrega por cada webhook activo suscrito al tipo del cambio
+// cuyo dueño puede ver el todo, dentro del mismo workspace, y retorna cuántas
+// se crearon
+func (s *WebhookStore) Enqueue(event models.ChangeEvent) (int, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	created := 0
+	for _, webhook := range s.webhooks {
+		if webhook.WorkspaceID != event.WorkspaceID || !event.VisibleTo(webhook.OwnerID) ||
+			!webhook.Active || !subscribed(webhook, event.Type) {
+			continue
+		}
+		s.deliveries = append(s.deliveries, models.WebhookDelivery{
+			ID:            s.nextDeliveryID,
+			WebhookID:     webhook.ID,
+			Event:         event.Type,
+			EventID:       event.ID,
+			TodoID:        event.TodoID,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+			Log:           []models.WebhookAttempt{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			Payload:       payload,
+		})
+		s.nextDeliveryID++
+		created++
+	}
+	if created > 0 {
+		s.prune()
+		s.notify()
+	}
+	return created, nil
+}
+
+// ClaimDue marca como en curso las entregas cuyo próximo intento ya llegó y
+// las retorna con su destino
+func (s *WebhookStore) ClaimDue(now time.Time) []DeliveryClaim {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claims []DeliveryClaim
+	for i := range s.deliveries {
+		delivery := &s.deliveries[i]
+		if delivery.Status != models.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		w := s.webhookIndex(delivery.WebhookID)
+		if w < 0 {
+			continue
+		}
+		delivery.Status = models.DeliverySending
+		delivery.NextAttemptAt = nil
+		claims = append(claims, DeliveryClaim{
+			Delivery: copyDelivery(*delivery),
+			URL:      s.webhooks[w].URL,
+			Secret:   s.webhooks[w].Secret,
+		})
+	}
+	return claims
+}
+
+// RecordAttempt guarda el resultado de un intento. Si falló, la entrega vuelve
+// a la cola para retryAt, o pasa a dead letters si retryAt es nil
+func (s *WebhookStore) RecordAttempt(id int, attempt models.WebhookAttempt, retryAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.deliveryIndex(id)
+	if i < 0 {
+		return ErrDeliveryNotFound
+	}
+
+	delivery := &s.deliveries[i]
+	delivery.Attempts++
+	delivery.Log = append(delivery.Log, attempt)
+	delivery.UpdatedAt = s.now()
+	switch {
+	case attempt.Error == "":
+		delivery.Status = models.DeliverySucceeded
+	case retryAt != nil:
+		delivery.Status = models.DeliveryPending
+		delivery.NextAttemptAt = retryAt
+		s.notify()
+	default:
+		delivery.Status = models.DeliveryDead
+	}
+	return nil
+}
+
+// NextDue obtiene cuándo toca el próximo intento pendiente; ok es false si no hay
+func (s *WebhookStore) NextDue() (next time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivery := range s.deliveries {
+		if delivery.Status != models.DeliveryPending {
+			continue
+		}
+		if !ok || delivery.NextAttemptAt.Before(next) {
+			next, ok = *delivery.NextAttemptAt, true
+		}
+	}
+	return next, ok
+}
+
+// Wake avisa cuando hay entregas nuevas o reprogramadas
+func (s *WebhookStore) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// notify despierta al dispatcher sin bloquear; requiere tener el lock tomado
+func (s *WebhookStore) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// prune olvida las entregas exitosas más antiguas si hay demasiadas; requiere
+// tener el lock tomado
+func (s *WebhookStore) prune() {
+	excess := len(s.deliveries) - maxDeliveries
+	if excess <= 0 {
+		return
+	}
+
+	kept := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if excess > 0 && delivery.Status == models.DeliverySucceeded {
+			excess--
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	s.deliveries = kept
+}
+
+// filterDeliveries copia las entregas que cumplen keep, las más recientes
+// primero; requiere tener el lock tomado
+func (s *WebhookStore) filterDeliveries(keep func(models.WebhookDelivery) bool) []models.WebhookDelivery {
+	deliveries := make([]models.WebhookDelivery, 0)
+	for i := len(s.deliveries) - 1; i >= 0; i-- {
+		if keep(s.deliveries[i]) {
+			deliveries = append(deliveries, copyDelivery(s.deliveries[i]))
+		}
+	}
+	return deliveries
+}
+
+// indexOf busca la posición de un webhook del usuario de sc; requiere tener
+// el lock tomado
+func (s *WebhookStore) indexOf(sc scope, id int) int {
+	i := s.webhookIndex(id)
+	if i < 0 || !sc.owns(s.webhooks[i].WorkspaceID, s.webhooks[i].OwnerID) {
+		return -1
+	}
+	return i
+}
+
+// webhookIndex busca la posición de un webhook de cualquier usuario; requiere
+// tener el lock tomado
+func (s *WebhookStore) webhookIndex(id int) int {
+	for i, webhook := range s.webhooks {
+		if webhook.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// deliveryIndex busca la posición de una entrega; requiere tener el lock tomado
+func (s *WebhookStore) deliveryIndex(id int) int {
+	for i, delivery := range s.deliveries {
+		if delivery.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// subscribed indica si el webhook escucha el tipo de cambio
+func subscribed(webhook models.Webhook, eventType string) bool {
+	for _, name := range webhook.Events {
+		if name == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// redact copia un webhook sin su secreto
+func redact(webhook models.Webhook) models.Webhook {
+	webhook.Secret = ""
+	webhook.Events = copyTags(webhook.Events)
+	return webhook
+}
+
+// copyDelivery copia una entrega para que el llamador no comparta su registro
+func copyDelivery(delivery models.WebhookDelivery) models.WebhookDelivery {
+	delivery.Log = append([]models.WebhookAttempt{}, delivery.Log...)
+	if delivery.NextAttemptAt != nil {
+		next := *delivery.NextAttemptAt
+		delivery.NextAttemptAt = &next
+	}
+	return delivery
+}
+
+// newSecret genera un secreto aleatorio para firmar las entregas
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// allowTarget pasa la URL por el TargetChecker configurado. Se llama sin el
+// lock tomado, porque la revisión puede resolver DNS
+func (s *WebhookStore) allowTarget(ctx context.Context, rawURL string) error {
+	s.mu.Lock()
+	check := s.checkTarget
+	s.mu.Unlock()
+
+	if check == nil {
+		return nil
+	}
+	if err := check(ctx, rawURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookTarget, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"todo-list/models"
+)
+
+// ErrWorkspaceNotFound se retorna cuando la petición no indica un workspace
+// configurado
+var ErrWorkspaceNotFound = errors.New("workspace no encontrado")
+
+// ErrQuotaExceeded se retorna al crear todos por encima del límite del workspace
+var ErrQuotaExceeded = errors.New("el workspace alcanzó su límite de todos")
+
+// Workspaces es el registro de los workspaces configurados. No cambia
+// después de crearse, así que es seguro para uso concurrente
+type Workspaces struct {
+	list     []models.Workspace
+	domain   string
+	fallback string
+}
+
+// NewWorkspaces crea el registro con los workspaces de list; sus IDs se
+// asignan en orden desde 1. domain es el dominio base para reconocer el
+// workspace por subdominio (vacío lo desactiva) y fallback el slug que se usa
+// cuando la petición no indica ninguno (vacío lo exige siempre)
+func NewWorkspaces(list []models.Workspace, domain, fallback string) *Workspaces {
+	workspaces := &Workspaces{
+		list:     make([]models.Workspace, len(list)),
+		domain:   strings.ToLower(strings.Trim(domain, ".")),
+		fallback: strings.ToLower(fallback),
+	}
+	for i, workspace := range list {
+		workspace.ID = i + 1
+		workspace.Slug = strings.ToLower(workspace.Slug)
+		workspaces.list[i] = workspace
+	}
+	return workspaces
+}
+
+// Resolve obtiene el workspace de una petición. name es el indicado
+// explícitamente (header X-Workspace o metadata x-workspace) y tiene
+// prioridad; si viene vacío se usa el subdominio de host y, si tampoco hay,
+// el workspace por defecto
+func (w *Workspaces) Resolve(name, host string) (models.Workspace, error) {
+	slug := strings.ToLower(strings.TrimSpace(name))
+	if slug == "" {
+		slug = w.subdomain(host)
+	}
+	if slug == "" {
+		slug = w.fallback
+	}
+	for _, workspace := range w.list {
+		if slug != "" && workspace.Slug == slug {
+			return workspace, nil
+		}
+	}
+	return models.Workspace{}, ErrWorkspaceNotFound
+}
+
+// subdomain obtiene el primer nivel de host por debajo del dominio base, por
+// ejemplo "acme" en acme.todo.example.com; vacío si host no es un subdominio
+func (w *Workspaces) subdomain(host string) string {
+	if w.domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+w.domain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// Workspace obtiene el workspace de la petición con la cantidad de todos que
+// usa de su límite
+func (s *TodoStore) Workspace(ctx context.Context) models.Workspace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	workspace, _ := WorkspaceFrom(ctx)
+	workspace.Todos = s.countIn(workspace.ID)
+	return workspace
+}
+
+// hasRoom indica si el workspace puede sumar n todos sin pasarse de su
+// límite; requiere tener el lock tomado
+func (s *TodoStore) hasRoom(workspace models.Workspace, n int) bool {
+	return workspace.MaxTodos <= 0 || s.countIn(workspace.ID)+n <= workspace.MaxTodos
+}
+
+// countIn cuenta los todos del workspace, incluidos los archivados; requiere
+// tener el lock tomado
+func (s *TodoStore) countIn(workspace int) int {
+	count := 0
+	for _, todo := range s.todos {
+		if todo.WorkspaceID == workspace {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,85 @@
+package templates
+
+import (
+	"html/template"
+)
+
+// AuthPageData representa los datos de la página de inicio de sesión o registro
+type AuthPageData struct {
+	Title     string
+	CSRFToken string
+	Register  bool
+	Email     string
+	Name      string
+	Errors    []string
+	// SSO muestra el botón para entrar con el proveedor OIDC
+	SSO bool
+}
+
+// userNav muestra en el encabezado quién inició sesión y el botón para salir.
+// Es un formulario POST con el token CSRF para que otro sitio no pueda cerrar la sesión
+const userNav = `
+                <span class="header-user"><i class="fas fa-user"></i> {{.User.Name}}</span>
+                <form method="post" action="/logout" class="logout-form">
+                    <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+                    <button type="submit"><i class="fas fa-right-from-bracket"></i> Salir</button>
+                </form>`
+
+// GetAuthTemplate retorna el template de las páginas de inicio de sesión y registro
+func GetAuthTemplate() *template.Template {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="/static/styles.css">
+    <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.4.0/css/all.min.css" rel="stylesheet">
+</head>
+<body>
+    <div class="container auth-container">
+        <header class="header">
+            <h1><i class="fas fa-tasks"></i> Todo List</h1>
+            <p>{{if .Register}}Crea tu cuenta para guardar tus tareas{{else}}Inicia sesión para ver tus tareas{{end}}</p>
+        </header>
+
+        <div class="todo-form">
+            {{if .Errors}}
+                <div class="auth-errors">
+                    {{range .Errors}}<p><i class="fas fa-circle-exclamation"></i> {{.}}</p>{{end}}
+                </div>
+            {{end}}
+            <form method="post" action="{{if .Register}}/register{{else}}/login{{end}}">
+                <input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+                {{if .Register}}
+                    <div class="form-group">
+                        <input type="text" name="name" value="{{.Name}}" placeholder="Tu nombre" autocomplete="name" required>
+                    </div>
+                {{end}}
+                <div class="form-group">
+                    <input type="email" name="email" value="{{.Email}}" placeholder="Email" autocomplete="email" required>
+                </div>
+                <div class="form-group">
+                    <input type="password" name="password" placeholder="Contraseña{{if .Register}} (mínimo 8 caracteres){{end}}"
+                           autocomplete="{{if .Register}}new-password{{else}}current-password{{end}}" required>
+                </div>
+                <div class="form-actions">
+                    <button type="submit">
+                        {{if .Register}}<i class="fas fa-user-plus"></i> Crear cuenta{{else}}<i class="fas fa-right-to-bracket"></i> Entrar{{end}}
+                    </button>
+                </div>
+            </form>
+            {{if .SSO}}
+                <a href="/auth/oidc/login" class="sso-button"><i class="fas fa-building"></i> Entrar con la cuenta de la empresa</a>
+            {{end}}
+            <p class="auth-switch">
+                {{if .Register}}¿Ya tienes cuenta? <a href="/login">Inicia sesión</a>{{else}}¿No tienes cuenta? <a href="/register">Crea una</a>{{end}}
+            </p>
+        </div>
+    </div>
+</body>
+</html>`
+
+	return template.Must(template.New("auth").Parse(tmpl))
+}
@@ -0,0 +1,74 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"todo-list/models"
+)
+
+// Dimensiones del gráfico de burndown en pixeles
+const (
+	chartWidth   = 600
+	chartHeight  = 200
+	chartPadding = 24
+)
+
+// BurndownChart representa los datos para dibujar el burndown como SVG
+type BurndownChart struct {
+	Width     int
+	Height    int
+	Remaining string
+	Completed string
+	Max       int
+	From      string
+	To        string
+	Empty     bool
+}
+
+// NewBurndownChart calcula las coordenadas de las series del burndown
+func NewBurndownChart(points []models.BurndownPoint) BurndownChart {
+	chart := BurndownChart{
+		Width:  chartWidth,
+		Height: chartHeight,
+		Empty:  true,
+	}
+	if len(points) == 0 {
+		return chart
+	}
+
+	for _, point := range points {
+		if point.Remaining > chart.Max {
+			chart.Max = point.Remaining
+		}
+		if point.Completed > chart.Max {
+			chart.Max = point.Completed
+		}
+	}
+	chart.Empty = chart.Max == 0
+	chart.From = points[0].Date.Format("02/01")
+	chart.To = points[len(points)-1].Date.Format("02/01")
+
+	scaleMax := chart.Max
+	if scaleMax == 0 {
+		scaleMax = 1
+	}
+	step := 0.0
+	if len(points) > 1 {
+		step = float64(chartWidth-2*chartPadding) / float64(len(points)-1)
+	}
+	plotHeight := float64(chartHeight - 2*chartPadding)
+
+	remaining := make([]string, 0, len(points))
+	completed := make([]string, 0, len(points))
+	for i, point := range points {
+		x := float64(chartPadding) + step*float64(i)
+		yRemaining := float64(chartHeight-chartPadding) - plotHeight*float64(point.Remaining)/float64(scaleMax)
+		yCompleted := float64(chartHeight-chartPadding) - plotHeight*float64(point.Completed)/float64(scaleMax)
+		remaining = append(remaining, fmt.Sprintf("%.1f,%.1f", x, yRemaining))
+		completed = append(completed, fmt.Sprintf("%.1f,%.1f", x, yCompleted))
+	}
+	chart.Remaining = strings.Join(remaining, " ")
+	chart.Completed = strings.Join(completed, " ")
+
+	return chart
+}
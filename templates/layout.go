@@ -1,6 +1,8 @@
 package templates
 
 import (
+	"encoding/json"
+	"fmt"
 	"html/template"
 	"time"
 	"todo-list/models"
@@ -18,6 +20,96 @@ func formatDate(t time.Time) string {
 	return t.Format("02/01/2006 15:04")
 }
 
+// safeHTML marca como seguro el HTML ya sanitizado por el paquete markdown
+func safeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+// formatDay formatea una fecha sin hora para mostrar
+func formatDay(t time.Time) string {
+	return t.Format("02/01/2006")
+}
+
+// checklistProgress retorna el avance del checklist, por ejemplo "2/5"
+func checklistProgress(items []models.ChecklistItem) string {
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d", done, len(items))
+}
+
+// todoStatsTemplate define el bloque de estadísticas; se recarga solo cuando
+// llega un cambio por SSE
+const todoStatsTemplate = `
+{{define "todoStats"}}
+        <div class="todo-stats" id="todoStats" hx-get="/api/stats" hx-trigger="sse:change, sse:reset" hx-swap="outerHTML">
+            <div class="stat">
+                <span class="stat-number">{{.Total}}</span>
+                <span class="stat-label">Total</span>
+            </div>
+            <div class="stat">
+                <span class="stat-number">{{.Pending}}</span>
+                <span class="stat-label">Pendientes</span>
+            </div>
+            <div class="stat">
+                <span class="stat-number">{{.Completed}}</span>
+                <span class="stat-label">Completadas</span>
+            </div>
+        </div>
+{{end}}`
+
+// GetStatsTemplate retorna el template del bloque de estadísticas (HTMX)
+func GetStatsTemplate() *template.Template {
+	return template.Must(template.New("stats").Parse(todoStatsTemplate)).Lookup("todoStats")
+}
+
+// htmxHead carga htmx y el script de la aplicación. Las páginas no tienen
+// scripts inline (la Content-Security-Policy no los permite): el
+// comportamiento vive en /static/htmx-app.js, que toma el token CSRF del
+// <meta> y lo envía en cada petición de htmx
+const htmxHead = `<meta name="csrf-token" content="{{.CSRFToken}}">
+    <meta name="htmx-config" content='{"includeIndicatorStyles": false, "allowEval": false, "allowScriptTags": false}'>
+    <script src="https://unpkg.com/htmx.org@1.9.10/dist/htmx.min.js"></script>`
+
+// appScript define el comportamiento de la aplicación; va después de htmx y
+// sus extensiones
+const appScript = `<script src="/static/htmx-app.js"></script>`
+
+// toggleVals arma el JSON de hx-vals con el que el botón completa o desmarca
+// un todo. Se codifica con encoding/json para que una comilla en el título no
+// rompa el JSON; html/template lo escapa después para el atributo
+func toggleVals(todo models.Todo) (string, error) {
+	return attrJSON(map[string]any{
+		"title":       todo.Title,
+		"description": todo.Description,
+		"completed":   !todo.Completed,
+		"estimate":    todo.Estimate,
+	})
+}
+
+// ifMatchHeaders arma el JSON de hx-headers con el If-Match de la versión
+func ifMatchHeaders(version int) (string, error) {
+	return attrJSON(map[string]string{"If-Match": fmt.Sprintf(`"v%d"`, version)})
+}
+
+// attrJSON codifica v para usarlo en un atributo como hx-vals o hx-headers
+func attrJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// todoFuncs son las funciones que usan las plantillas de todos
+var todoFuncs = template.FuncMap{
+	"formatDate":        formatDate,
+	"formatDay":         formatDay,
+	"checklistProgress": checklistProgress,
+	"safeHTML":          safeHTML,
+	"toggleVals":        toggleVals,
+}
+
 // GetLayoutTemplate retorna el template principal
 func GetLayoutTemplate() *template.Template {
 	tmpl := `
@@ -29,85 +121,110 @@ func GetLayoutTemplate() *template.Template {
     <title>{{.Title}}</title>
     <link rel="stylesheet" href="/static/styles.css">
     <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.4.0/css/all.min.css" rel="stylesheet">
-    <script src="https://unpkg.com/htmx.org@1.9.10"></script>
-    <script>
-        // Configurar HTMX para enviar JSON automáticamente
-        document.addEventListener('htmx:configRequest', function(event) {
-            if (event.detail.headers && event.detail.headers['Content-Type'] === 'application/json') {
-                const form = event.detail.elt;
-                const formData = new FormData(form);
-                const jsonData = {};
-                
-                // Convertir FormData a JSON
-                for (let [key, value] of formData.entries()) {
-                    if (key === 'completed') {
-                        const checkbox = form.querySelector('input[name="' + key + '"]');
-                        jsonData[key] = checkbox ? checkbox.checked : false;
-                    } else {
-                        jsonData[key] = value;
-                    }
-                }
-                
-                // Enviar como JSON
-                event.detail.xhr.send(JSON.stringify(jsonData));
-                event.preventDefault();
-            }
-        });
-    </script>
+    ` + htmxHead + `
+    <script src="https://unpkg.com/htmx.org@1.9.10/dist/ext/sse.js"></script>
+    ` + appScript + `
 </head>
-<body>
+<body hx-ext="sse" sse-connect="/api/events">
     <div class="container">
         <header class="header">
             <h1><i class="fas fa-tasks"></i> Todo List</h1>
             <p>Gestiona tus tareas de manera eficiente</p>
+            <nav class="header-nav">
+                <a href="/lists"><i class="fas fa-users"></i> Listas</a>
+                <a href="/archive"><i class="fas fa-box-archive"></i> Archivo</a>` + userNav + `
+            </nav>
         </header>
 
         <div class="todo-form">
             <form hx-post="/api/todos/flexible" 
                   hx-target="#todoList" 
                   hx-swap="outerHTML"
-                  hx-headers='{"Content-Type": "application/json"}'>
+                  hx-ext="json-form">
                 <div class="form-group">
-                    <input type="text" name="title" placeholder="Título de la tarea" required>
+                    <input type="text" name="title" placeholder="Título de la tarea" maxlength="200" required>
                 </div>
                 <div class="form-group">
-                    <textarea name="description" placeholder="Descripción (opcional)"></textarea>
+                    <textarea name="description" maxlength="10000" placeholder="Descripción (opcional)"></textarea>
                 </div>
+                <div class="form-group">
+                    <input type="number" name="estimate" min="0" placeholder="Estimación en puntos (opcional)">
+                </div>
+                {{if .Lists}}
+                <div class="form-group">
+                    <select name="list_id">
+                        <option value="">Tarea personal</option>
+                        {{range .Lists}}<option value="{{.ID}}">Lista: {{.Name}}</option>{{end}}
+                    </select>
+                </div>
+                {{end}}
                 <div class="form-actions">
                     <button type="submit">
                         <i class="fas fa-plus"></i> Agregar Tarea
                     </button>
                 </div>
             </form>
+
+            <details class="template-picker">
+                <summary><i class="fas fa-layer-group"></i> Crear desde plantilla</summary>
+                {{if .Templates}}
+                    <div class="form-group">
+                        <select name="template_id" hx-get="/api/templates/form" hx-target="#templateForm" hx-trigger="change">
+                            <option value="">Selecciona una plantilla</option>
+                            {{range .Templates}}
+                                <option value="{{.ID}}">{{.Name}} ({{len .Items}} tareas)</option>
+                            {{end}}
+                        </select>
+                    </div>
+                    <div id="templateForm"></div>
+                {{else}}
+                    <p class="template-empty">Aún no hay plantillas. Crea una con <code>POST /api/templates</code>.</p>
+                {{end}}
+            </details>
         </div>
 
         <div class="filters">
-            <button class="filter-btn active" hx-get="/api/todos" hx-target="#todoList">
+            <button class="filter-btn active" data-filter="" hx-get="/api/todos" hx-target="#todoList">
                 <i class="fas fa-list"></i> Todas
             </button>
-            <button class="filter-btn" hx-get="/api/todos?filter=pending" hx-target="#todoList">
+            <button class="filter-btn" data-filter="pending" hx-get="/api/todos?filter=pending" hx-target="#todoList">
                 <i class="fas fa-clock"></i> Pendientes
             </button>
-            <button class="filter-btn" hx-get="/api/todos?filter=completed" hx-target="#todoList">
+            <button class="filter-btn" data-filter="completed" hx-get="/api/todos?filter=completed" hx-target="#todoList">
                 <i class="fas fa-check"></i> Completadas
             </button>
+            <button class="filter-btn" data-filter="mine" hx-get="/api/todos?filter=mine" hx-target="#todoList">
+                <i class="fas fa-user-check"></i> Mis tareas
+            </button>
         </div>
 
-        <div class="todo-stats">
-            <div class="stat">
-                <span class="stat-number">{{.Stats.Total}}</span>
-                <span class="stat-label">Total</span>
-            </div>
-            <div class="stat">
-                <span class="stat-number">{{.Stats.Pending}}</span>
-                <span class="stat-label">Pendientes</span>
-            </div>
-            <div class="stat">
-                <span class="stat-number">{{.Stats.Completed}}</span>
-                <span class="stat-label">Completadas</span>
-            </div>
+        {{template "todoStats" .Stats}}
+
+        <div class="burndown">
+            <h3><i class="fas fa-chart-line"></i> Burndown ({{.Burndown.From}} - {{.Burndown.To}})</h3>
+            {{if .Burndown.Empty}}
+                <p class="burndown-empty">Agrega estimaciones a tus tareas para ver el burndown</p>
+            {{else}}
+                <svg class="burndown-chart" viewBox="0 0 {{.Burndown.Width}} {{.Burndown.Height}}" role="img" aria-label="Burndown de puntos">
+                    <line x1="24" y1="24" x2="24" y2="176" stroke="#ccc"/>
+                    <line x1="24" y1="176" x2="576" y2="176" stroke="#ccc"/>
+                    <text x="28" y="20" font-size="12" fill="#666">{{.Burndown.Max}} pts</text>
+                    <polyline points="{{.Burndown.Remaining}}" fill="none" stroke="#dc3545" stroke-width="2"/>
+                    <polyline points="{{.Burndown.Completed}}" fill="none" stroke="#28a745" stroke-width="2"/>
+                    <text x="400" y="196" font-size="12" fill="#dc3545">Restante</text>
+                    <text x="480" y="196" font-size="12" fill="#28a745">Completado</text>
+                </svg>
+            {{end}}
         </div>
 
+        <!-- Recarga la lista, con el filtro activo, cuando llega un cambio por SSE -->
+        <input type="hidden" id="currentFilter" name="filter" value="">
+        <div hidden
+             hx-get="/api/todos"
+             hx-trigger="sse:change, sse:reset"
+             hx-include="#currentFilter"
+             hx-target="#todoList"></div>
+
         <div id="todoList">
             {{if .Todos}}
                 <div class="todo-list">
@@ -117,20 +234,26 @@ func GetLayoutTemplate() *template.Template {
                                 <div>
                                     <div class="todo-title">{{.Title}}</div>
                                     {{if .Description}}
-                                        <div class="todo-description">{{.Description}}</div>
+                                        <div class="todo-description">{{safeHTML .DescriptionHTML}}</div>
                                     {{end}}
                                 </div>
                             </div>
                             <div class="todo-meta">
                                 <span><i class="fas fa-calendar"></i> {{formatDate .CreatedAt}}</span>
                                 <span><i class="fas fa-clock"></i> {{formatDate .UpdatedAt}}</span>
+                                {{if .Estimate}}<span><i class="fas fa-weight-hanging"></i> {{.Estimate}} pts</span>{{end}}
+                                {{if .DueDate}}<span><i class="fas fa-flag"></i> {{formatDay .DueDate}}</span>{{end}}
+                                {{if .Checklist}}<span><i class="fas fa-list-check"></i> {{checklistProgress .Checklist}}</span>{{end}}
+                                {{range .Tags}}<span class="todo-tag">#{{.}}</span>{{end}}
+                                {{with index $.ListNames .ListID}}<span class="todo-list-badge"><i class="fas fa-users"></i> {{.}}</span>{{end}}
+                                {{with index $.Assignees .AssigneeID}}<span class="todo-assignee"><i class="fas fa-user-check"></i> {{.}}</span>{{end}}
                             </div>
                             <div class="todo-actions">
                                 <button 
                                     class="btn {{if .Completed}}btn-secondary{{else}}btn-success{{end}}" 
                                     hx-put="/api/todos/{{.ID}}" 
-                                    hx-headers='{"Content-Type": "application/json"}'
-                                    hx-vals='{"title": "{{.Title}}", "description": "{{.Description}}", "completed": {{if .Completed}}false{{else}}true{{end}}}'
+                                    hx-ext="json-form"
+                                    hx-vals='{{toggleVals .}}'
                                     hx-target="#todoList"
                                     hx-swap="outerHTML"
                                 >
@@ -145,6 +268,16 @@ func GetLayoutTemplate() *template.Template {
                                 >
                                     <i class="fas fa-edit"></i> Editar
                                 </button>
+                                {{if .Completed}}
+                                <button 
+                                    class="btn btn-secondary" 
+                                    hx-post="/api/todos/{{.ID}}/archive"
+                                    hx-target="#todoList"
+                                    hx-swap="outerHTML"
+                                >
+                                    <i class="fas fa-box-archive"></i> Archivar
+                                </button>
+                                {{end}}
                                 <button 
                                     class="btn btn-danger" 
                                     hx-delete="/api/todos/{{.ID}}"
@@ -170,9 +303,7 @@ func GetLayoutTemplate() *template.Template {
 </body>
 </html>`
 
-	return template.Must(template.New("layout").Funcs(template.FuncMap{
-		"formatDate": formatDate,
-	}).Parse(tmpl))
+	return template.Must(template.New("layout").Funcs(todoFuncs).Parse(tmpl + todoStatsTemplate))
 }
 
 // GetTodoListTemplate retorna el template para la lista de todos (HTMX)
@@ -186,19 +317,26 @@ func GetTodoListTemplate() *template.Template {
                     <div>
                         <div class="todo-title">{{.Title}}</div>
                         {{if .Description}}
-                            <div class="todo-description">{{.Description}}</div>
+                            <div class="todo-description">{{safeHTML .DescriptionHTML}}</div>
                         {{end}}
                     </div>
                 </div>
                 <div class="todo-meta">
                     <span><i class="fas fa-calendar"></i> {{formatDate .CreatedAt}}</span>
                     <span><i class="fas fa-clock"></i> {{formatDate .UpdatedAt}}</span>
+                    {{if .Estimate}}<span><i class="fas fa-weight-hanging"></i> {{.Estimate}} pts</span>{{end}}
+                    {{if .DueDate}}<span><i class="fas fa-flag"></i> {{formatDay .DueDate}}</span>{{end}}
+                    {{if .Checklist}}<span><i class="fas fa-list-check"></i> {{checklistProgress .Checklist}}</span>{{end}}
+                    {{range .Tags}}<span class="todo-tag">#{{.}}</span>{{end}}
+                    {{with index $.ListNames .ListID}}<span class="todo-list-badge"><i class="fas fa-users"></i> {{.}}</span>{{end}}
+                    {{with index $.Assignees .AssigneeID}}<span class="todo-assignee"><i class="fas fa-user-check"></i> {{.}}</span>{{end}}
                 </div>
                 <div class="todo-actions">
                     <button 
                         class="btn {{if .Completed}}btn-secondary{{else}}btn-success{{end}}" 
                         hx-put="/api/todos/{{.ID}}" 
-                        hx-vals='{"completed": {{if .Completed}}false{{else}}true{{end}}}'
+                        hx-ext="json-form"
+                        hx-vals='{{toggleVals .}}'
                         hx-target="#todoList"
                         hx-swap="outerHTML"
                     >
@@ -213,6 +351,16 @@ func GetTodoListTemplate() *template.Template {
                     >
                         <i class="fas fa-edit"></i> Editar
                     </button>
+                    {{if .Completed}}
+                    <button 
+                        class="btn btn-secondary" 
+                        hx-post="/api/todos/{{.ID}}/archive"
+                        hx-target="#todoList"
+                        hx-swap="outerHTML"
+                    >
+                        <i class="fas fa-box-archive"></i> Archivar
+                    </button>
+                    {{end}}
                     <button 
                         class="btn btn-danger" 
                         hx-delete="/api/todos/{{.ID}}"
@@ -234,9 +382,7 @@ func GetTodoListTemplate() *template.Template {
     </div>
 {{end}}`
 
-	return template.Must(template.New("todoList").Funcs(template.FuncMap{
-		"formatDate": formatDate,
-	}).Parse(tmpl))
+	return template.Must(template.New("todoList").Funcs(todoFuncs).Parse(tmpl))
 }
 
 // GetEditModalTemplate retorna el template para el modal de edición
@@ -254,14 +400,27 @@ func GetEditModalTemplate() *template.Template {
             <form hx-put="/api/todos/{{.ID}}" 
                   hx-target="#todoList" 
                   hx-swap="outerHTML"
-                  hx-headers='{"Content-Type": "application/json"}'>
+                  hx-ext="json-form"
+                  hx-headers='{{ifMatchHeaders .Version}}'>
                 <div class="form-group">
                     <label for="editTitle">Título:</label>
-                    <input type="text" id="editTitle" name="title" value="{{.Title}}" required>
+                    <input type="text" id="editTitle" name="title" value="{{.Title}}" maxlength="200" required>
                 </div>
                 <div class="form-group">
                     <label for="editDescription">Descripción:</label>
-                    <textarea id="editDescription" name="description">{{.Description}}</textarea>
+                    <textarea id="editDescription" name="description" maxlength="10000">{{.Description}}</textarea>
+                </div>
+                <div class="form-group">
+                    <label for="editEstimate">Estimación (puntos):</label>
+                    <input type="number" id="editEstimate" name="estimate" min="0" value="{{.Estimate}}">
+                </div>
+                <div class="form-group">
+                    <label for="editAssignee">Responsable:</label>
+                    <select id="editAssignee" name="assignee_id">
+                        <option value="0">Sin responsable</option>
+                        {{$assignee := .AssigneeID}}
+                        {{range .Assignees}}<option value="{{.UserID}}" {{if eq .UserID $assignee}}selected{{end}}>{{.Name}}</option>{{end}}
+                    </select>
                 </div>
                 <div class="form-group">
                     <label class="checkbox-label">
@@ -279,17 +438,172 @@ func GetEditModalTemplate() *template.Template {
     </div>
 </div>`
 
-	return template.Must(template.New("editModal").Parse(tmpl))
+	return template.Must(template.New("editModal").Funcs(template.FuncMap{
+		"ifMatchHeaders": ifMatchHeaders,
+	}).Parse(tmpl))
+}
+
+// GetArchiveTemplate retorna el template de la página de tareas archivadas
+func GetArchiveTemplate() *template.Template {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="/static/styles.css">
+    <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.4.0/css/all.min.css" rel="stylesheet">
+    ` + htmxHead + `
+    ` + appScript + `
+</head>
+<body>
+    <div class="container">
+        <header class="header">
+            <h1><i class="fas fa-box-archive"></i> Archivo</h1>
+            <p>Tareas completadas que ya no aparecen en la lista</p>
+            <nav class="header-nav">
+                <a href="/"><i class="fas fa-arrow-left"></i> Volver a la lista</a>` + userNav + `
+            </nav>
+        </header>
+
+        <div class="todo-form">
+            <input type="search" name="q" value="{{.Query}}" placeholder="Buscar en el archivo..."
+                   hx-get="/api/archive"
+                   hx-trigger="keyup changed delay:300ms, search"
+                   hx-target="#archiveList">
+        </div>
+
+        <div id="archiveList">
+            {{template "archiveList" .}}
+        </div>
+    </div>
+</body>
+</html>`
+
+	page := template.Must(template.New("archive").Funcs(template.FuncMap{
+		"formatDate": formatDate,
+		"safeHTML":   safeHTML,
+	}).Parse(tmpl))
+	template.Must(page.New("archiveList").Parse(archiveListTemplate))
+	return page
+}
+
+// GetArchiveListTemplate retorna el template para la lista de archivados (HTMX)
+func GetArchiveListTemplate() *template.Template {
+	return template.Must(template.New("archiveList").Funcs(template.FuncMap{
+		"formatDate": formatDate,
+		"safeHTML":   safeHTML,
+	}).Parse(archiveListTemplate))
+}
+
+// archiveListTemplate es el fragmento compartido por la página y la búsqueda
+const archiveListTemplate = `
+{{if .Todos}}
+    <div class="todo-list">
+        {{range .Todos}}
+            <div class="todo-item todo-item-completed">
+                <div class="todo-header">
+                    <div>
+                        <div class="todo-title">{{.Title}}</div>
+                        {{if .Description}}
+                            <div class="todo-description">{{safeHTML .DescriptionHTML}}</div>
+                        {{end}}
+                    </div>
+                </div>
+                <div class="todo-meta">
+                    <span><i class="fas fa-calendar"></i> {{formatDate .CreatedAt}}</span>
+                    {{if .ArchivedAt}}<span><i class="fas fa-box-archive"></i> {{formatDate .ArchivedAt}}</span>{{end}}
+                </div>
+                <div class="todo-actions">
+                    <button 
+                        class="btn btn-primary" 
+                        hx-post="/api/todos/{{.ID}}/unarchive"
+                        hx-target="#archiveList"
+                    >
+                        <i class="fas fa-box-open"></i> Desarchivar
+                    </button>
+                </div>
+            </div>
+        {{end}}
+    </div>
+{{else}}
+    <div class="empty-state">
+        <i class="fas fa-box-archive"></i>
+        <h3>No hay tareas archivadas</h3>
+        <p>Las tareas completadas se archivan automáticamente con el tiempo</p>
+    </div>
+{{end}}`
+
+// GetTemplateFormTemplate retorna el formulario para instanciar una plantilla (HTMX)
+func GetTemplateFormTemplate() *template.Template {
+	tmpl := `
+<form class="template-form"
+      hx-post="/api/templates/{{.Template.ID}}/instantiate"
+      hx-target="#todoList"
+      hx-swap="outerHTML">
+    {{if .Template.Description}}<p class="template-description">{{.Template.Description}}</p>{{end}}
+    {{range .Placeholders}}
+        <div class="form-group">
+            <input type="text" name="values[{{.}}]" placeholder="{{.}}" required>
+        </div>
+    {{end}}
+    <div class="form-group">
+        <label>Fecha de inicio (para las fechas límite):</label>
+        <input type="date" name="start_date">
+    </div>
+    <div class="form-actions">
+        <button type="submit">
+            <i class="fas fa-layer-group"></i> Crear {{len .Template.Items}} tareas
+        </button>
+    </div>
+</form>`
+
+	return template.Must(template.New("templateForm").Parse(tmpl))
 }
 
 // PageData representa los datos para la página
 type PageData struct {
-	Title string
-	Todos []models.Todo
-	Stats TodoStats
+	Title     string
+	CSRFToken string
+	User      models.User
+	Todos     []models.Todo
+	Stats     TodoStats
+	Burndown  BurndownChart
+	Templates []models.TodoTemplate
+	// Lists son las listas compartidas en las que el usuario puede crear todos
+	Lists []models.List
+	// ListNames es el nombre de cada lista compartida, para etiquetar los todos
+	ListNames map[int]string
+	// Assignees es el nombre de cada persona que puede ser responsable
+	Assignees map[int]string
 }
 
 // TodoListData representa los datos para la lista de todos
 type TodoListData struct {
-	Todos []models.Todo
+	Todos     []models.Todo
+	ListNames map[int]string
+	Assignees map[int]string
+}
+
+// EditModalData representa los datos del modal de edición; Assignees son
+// las personas a las que se puede asignar la tarea
+type EditModalData struct {
+	models.Todo
+	Assignees []models.ListMember
+}
+
+// ArchivePageData representa los datos para la página de archivados
+type ArchivePageData struct {
+	Title     string
+	CSRFToken string
+	User      models.User
+	Query     string
+	Todos     []models.Todo
+}
+
+// TemplateFormData representa los datos para instanciar una plantilla
+type TemplateFormData struct {
+	Template     models.TodoTemplate
+	Placeholders []string
 }
@@ -0,0 +1,178 @@
+package templates
+
+import (
+	"html/template"
+	"todo-list/models"
+)
+
+// ListsPageData representa los datos para la página de listas compartidas
+type ListsPageData struct {
+	Title       string
+	CSRFToken   string
+	User        models.User
+	Lists       []models.List
+	Invitations []models.Invitation
+	// NewInvitation es la invitación recién creada, con su código; solo se
+	// muestra en la respuesta de Invitar
+	NewInvitation *models.Invitation
+}
+
+// roleLabel traduce un rol para mostrarlo
+func roleLabel(role string) string {
+	switch role {
+	case models.RoleOwner:
+		return "Owner"
+	case models.RoleEditor:
+		return "Editor"
+	default:
+		return "Lector"
+	}
+}
+
+// roleOptions son los roles que se pueden elegir al invitar o cambiar un rol
+var roleOptions = []string{models.RoleViewer, models.RoleEditor, models.RoleOwner}
+
+// listsPanelTemplate es el panel de listas; las acciones lo reemplazan entero
+const listsPanelTemplate = `
+{{define "listsPanel"}}
+<div id="listsPanel">
+    {{if .Invitations}}
+        <section class="invitations">
+            <h3><i class="fas fa-envelope-open-text"></i> Invitaciones pendientes</h3>
+            {{range .Invitations}}
+                <div class="invitation">
+                    <span><strong>{{.ListName}}</strong> · {{.InvitedBy}} te invitó como <span class="role-badge role-{{.Role}}">{{roleLabel .Role}}</span></span>
+                    <span class="invitation-actions">
+                        <form hx-post="/api/invitations/{{.ID}}/accept" hx-target="#listsPanel" hx-swap="outerHTML">
+                            <input type="text" name="token" placeholder="Código de la invitación" maxlength="100" required>
+                            <button type="submit" class="btn btn-success">
+                                <i class="fas fa-check"></i> Aceptar
+                            </button>
+                        </form>
+                        <button class="btn btn-secondary" hx-delete="/api/invitations/{{.ID}}" hx-target="#listsPanel" hx-swap="outerHTML">
+                            <i class="fas fa-times"></i> Rechazar
+                        </button>
+                    </span>
+                </div>
+            {{end}}
+        </section>
+    {{end}}
+
+    <form class="list-create" hx-post="/api/lists" hx-target="#listsPanel" hx-swap="outerHTML">
+        <input type="text" name="name" placeholder="Nombre de la nueva lista" maxlength="100" required>
+        <button type="submit"><i class="fas fa-plus"></i> Crear lista</button>
+    </form>
+
+    {{range $list := .Lists}}
+        <div class="list-card">
+            <div class="list-header">
+                <h3><i class="fas fa-users"></i> {{.Name}}</h3>
+                <span class="role-badge role-{{.Role}}">{{roleLabel .Role}}</span>
+                {{if eq .Role "owner"}}
+                    <button class="btn btn-danger" hx-delete="/api/lists/{{.ID}}" hx-target="#listsPanel" hx-swap="outerHTML"
+                            hx-confirm="¿Eliminar la lista y todas sus tareas?">
+                        <i class="fas fa-trash"></i> Eliminar
+                    </button>
+                {{else}}
+                    <button class="btn btn-secondary" hx-delete="/api/lists/{{.ID}}/members/{{$.User.ID}}" hx-target="#listsPanel" hx-swap="outerHTML"
+                            hx-confirm="¿Salir de la lista?">
+                        <i class="fas fa-right-from-bracket"></i> Salir
+                    </button>
+                {{end}}
+            </div>
+            <ul class="list-members">
+                {{range .Members}}
+                    <li>
+                        <span class="member-name"><i class="fas fa-user"></i> {{.Name}} <small>{{.Email}}</small></span>
+                        {{if and (eq $list.Role "owner") (ne .UserID $.User.ID)}}
+                            <select name="role" hx-put="/api/lists/{{$list.ID}}/members/{{.UserID}}" hx-trigger="change" hx-target="#listsPanel" hx-swap="outerHTML">
+                                {{$role := .Role}}
+                                {{range roleOptions}}<option value="{{.}}" {{if eq . $role}}selected{{end}}>{{roleLabel .}}</option>{{end}}
+                            </select>
+                            <button class="btn btn-danger" hx-delete="/api/lists/{{$list.ID}}/members/{{.UserID}}" hx-target="#listsPanel" hx-swap="outerHTML"
+                                    hx-confirm="¿Quitar a {{.Name}} de la lista?">
+                                <i class="fas fa-user-minus"></i>
+                            </button>
+                        {{else}}
+                            <span class="role-badge role-{{.Role}}">{{roleLabel .Role}}</span>
+                        {{end}}
+                    </li>
+                {{end}}
+                {{range .Invitations}}
+                    <li class="member-pending">
+                        <span class="member-name"><i class="fas fa-envelope"></i> {{.Email}} <small>invitación pendiente</small></span>
+                        <span class="role-badge role-{{.Role}}">{{roleLabel .Role}}</span>
+                        <button class="btn btn-secondary" hx-delete="/api/invitations/{{.ID}}" hx-target="#listsPanel" hx-swap="outerHTML">
+                            <i class="fas fa-times"></i> Cancelar
+                        </button>
+                    </li>
+                {{end}}
+            </ul>
+            {{if and $.NewInvitation (eq $.NewInvitation.ListID .ID)}}
+                <div class="invitation-code">
+                    <i class="fas fa-key"></i> Comparte este código con {{$.NewInvitation.Email}}; solo se muestra ahora:
+                    <code>{{$.NewInvitation.Token}}</code>
+                </div>
+            {{end}}
+            {{if eq .Role "owner"}}
+                <form class="list-invite" hx-post="/api/lists/{{.ID}}/invitations" hx-target="#listsPanel" hx-swap="outerHTML">
+                    <input type="email" name="email" placeholder="Email de la persona a invitar" maxlength="254" required>
+                    <select name="role">
+                        {{range roleOptions}}<option value="{{.}}" {{if eq . "editor"}}selected{{end}}>{{roleLabel .}}</option>{{end}}
+                    </select>
+                    <button type="submit"><i class="fas fa-user-plus"></i> Invitar</button>
+                </form>
+            {{end}}
+        </div>
+    {{else}}
+        <div class="empty-state">
+            <i class="fas fa-users"></i>
+            <h3>No tienes listas compartidas</h3>
+            <p>Crea una lista e invita a tu equipo como lector, editor u owner</p>
+        </div>
+    {{end}}
+</div>
+{{end}}`
+
+// listsFuncs son las funciones que usan las plantillas de listas
+var listsFuncs = template.FuncMap{
+	"roleLabel":   roleLabel,
+	"roleOptions": func() []string { return roleOptions },
+}
+
+// GetListsTemplate retorna el template de la página de listas compartidas
+func GetListsTemplate() *template.Template {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="es">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="/static/styles.css">
+    <link href="https://cdnjs.cloudflare.com/ajax/libs/font-awesome/6.4.0/css/all.min.css" rel="stylesheet">
+    ` + htmxHead + `
+    ` + appScript + `
+</head>
+<body>
+    <div class="container">
+        <header class="header">
+            <h1><i class="fas fa-users"></i> Listas compartidas</h1>
+            <p>Comparte tareas con tu equipo y decide quién puede verlas o editarlas</p>
+            <nav class="header-nav">
+                <a href="/"><i class="fas fa-arrow-left"></i> Volver a la lista</a>` + userNav + `
+            </nav>
+        </header>
+
+        {{template "listsPanel" .}}
+    </div>
+</body>
+</html>`
+
+	return template.Must(template.New("lists").Funcs(listsFuncs).Parse(tmpl + listsPanelTemplate))
+}
+
+// GetListsPanelTemplate retorna el template del panel de listas (HTMX)
+func GetListsPanelTemplate() *template.Template {
+	return template.Must(template.New("lists").Funcs(listsFuncs).Parse(listsPanelTemplate)).Lookup("listsPanel")
+}
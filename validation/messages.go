@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Idiomas soportados para los mensajes de error
+const (
+	LangES = "es"
+	LangEN = "en"
+)
+
+// DefaultLang es el idioma de los mensajes cuando el cliente no pide otro
+const DefaultLang = LangES
+
+// Language elige el idioma de los mensajes a partir del header Accept-Language
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		lang := strings.SplitN(tag, "-", 2)[0]
+		if _, ok := messages[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLang
+}
+
+// labels traduce los nombres JSON de los campos a un nombre legible
+var labels = map[string]map[string]string{
+	LangES: {
+		"title":           "título",
+		"description":     "descripción",
+		"estimate":        "estimación",
+		"tags":            "etiquetas",
+		"checklist":       "checklist",
+		"text":            "texto",
+		"name":            "nombre",
+		"items":           "tareas",
+		"values":          "valores",
+		"operations":      "operaciones",
+		"op":              "operación",
+		"todo":            "tarea",
+		"due_offset_days": "días hasta el vencimiento",
+		"events":          "eventos",
+		"secret":          "secreto",
+		"password":        "contraseña",
+		"expires_in_days": "días hasta el vencimiento",
+		"role":            "rol",
+		"list_id":         "lista",
+		"assignee_id":     "responsable",
+		"watchers":        "observadores",
+	},
+	LangEN: {
+		"due_offset_days": "due offset days",
+		"expires_in_days": "expires in days",
+		"list_id":         "list",
+		"assignee_id":     "assignee",
+	},
+}
+
+// messages contiene los mensajes de cada regla; la clave de min y max incluye
+// el tipo de campo porque el mensaje cambia para textos, números y listas
+var messages = map[string]map[string]string{
+	LangES: {
+		"required":   "El campo %s es requerido",
+		"min.string": "El campo %s debe tener al menos %s caracteres",
+		"max.string": "El campo %s admite como máximo %s caracteres",
+		"min.number": "El campo %s no puede ser menor que %s",
+		"max.number": "El campo %s no puede ser mayor que %s",
+		"min.list":   "El campo %s requiere al menos %s elemento(s)",
+		"max.list":   "El campo %s admite como máximo %s elementos",
+		"oneof":      "El campo %s debe ser uno de: %s",
+		"http_url":   "El campo %s debe ser una URL http o https",
+		"email":      "El campo %s debe ser un email válido",
+		"default":    "El campo %s no es válido",
+	},
+	LangEN: {
+		"required":   "The %s field is required",
+		"min.string": "The %s field must be at least %s characters long",
+		"max.string": "The %s field must be at most %s characters long",
+		"min.number": "The %s field must be at least %s",
+		"max.number": "The %s field must be at most %s",
+		"min.list":   "The %s field requires at least %s item(s)",
+		"max.list":   "The %s field allows at most %s items",
+		"oneof":      "The %s field must be one of: %s",
+		"http_url":   "The %s field must be an http or https URL",
+		"email":      "The %s field must be a valid email address",
+		"default":    "The %s field is not valid",
+	},
+}
+
+// message genera el mensaje localizado de un error de validación
+func message(fe validator.FieldError, lang string) string {
+	catalog, ok := messages[lang]
+	if !ok {
+		catalog = messages[DefaultLang]
+	}
+
+	label := fieldLabel(fe.Field(), lang)
+	switch fe.Tag() {
+	case "required", "required_if", "notblank":
+		return fmt.Sprintf(catalog["required"], label)
+	case "min", "max":
+		return fmt.Sprintf(catalog[fe.Tag()+"."+kindName(fe.Kind())], label, fe.Param())
+	case "oneof":
+		return fmt.Sprintf(catalog["oneof"], label, strings.Join(strings.Fields(fe.Param()), ", "))
+	case "http_url", "email":
+		return fmt.Sprintf(catalog[fe.Tag()], label)
+	default:
+		return fmt.Sprintf(catalog["default"], label)
+	}
+}
+
+// fieldLabel traduce el nombre de un campo, conservando el índice si es un elemento de una lista
+func fieldLabel(field, lang string) string {
+	name, index := field, ""
+	if i := strings.Index(field, "["); i >= 0 {
+		name, index = field[:i], field[i:]
+	}
+	if label, ok := labels[lang][name]; ok {
+		return label + index
+	}
+	return field
+}
+
+// kindName agrupa los tipos de Go según el mensaje que les corresponde
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Normalize aplica las transformaciones de los tags `mod` a v, que debe ser un
+// puntero a struct. Por ahora la única transformación es `mod:"trim"`, que
+// quita los espacios al inicio y al final de strings y listas de strings
+func Normalize(v interface{}) {
+	normalizeValue(reflect.ValueOf(v), false)
+}
+
+// normalizeValue recorre el valor y recorta los strings cuando trim es true
+func normalizeValue(v reflect.Value, trim bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			normalizeValue(v.Elem(), trim)
+		}
+	case reflect.String:
+		if trim && v.CanSet() {
+			v.SetString(strings.TrimSpace(v.String()))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalizeValue(v.Index(i), trim)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			normalizeValue(v.Field(i), t.Field(i).Tag.Get("mod") == "trim")
+		}
+	}
+}
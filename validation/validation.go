@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"todo-list/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validate aplica las reglas declaradas en los tags `validate` de los modelos
+var validate = newValidator()
+
+// newValidator crea el validador y hace que los errores usen los nombres JSON
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	// notblank: como required, pero para punteros exige también que el valor no esté vacío
+	v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return !fl.Field().IsZero()
+	})
+	return v
+}
+
+// Struct normaliza v (un puntero a struct) según sus tags `mod` y luego lo
+// valida según sus tags `validate`. Retorna los errores por campo en el idioma
+// lang, o nil si v es válido
+func Struct(v interface{}, lang string) []models.FieldError {
+	Normalize(v)
+
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []models.FieldError{{Field: "", Code: models.FieldInvalid, Message: err.Error()}}
+	}
+
+	errs := make([]models.FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		errs = append(errs, models.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Code:    fieldCode(fe),
+			Message: message(fe, lang),
+		})
+	}
+	return errs
+}
+
+// fieldPath quita el nombre del struct raíz: "TodoRequest.items[0].title" -> "items[0].title"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldCode traduce la regla que falló a un código estable de models
+func fieldCode(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "notblank":
+		return models.FieldRequired
+	case "min":
+		return models.FieldMin
+	case "max":
+		if fe.Kind() == reflect.String {
+			return models.FieldTooLong
+		}
+		return models.FieldMax
+	case "oneof":
+		return models.FieldEnum
+	default:
+		return models.FieldInvalid
+	}
+}
@@ -0,0 +1,64 @@
+// Comportamiento de la versión HTMX. Vive en un archivo propio porque la
+// Content-Security-Policy de las páginas no permite scripts inline
+
+// Token CSRF de la página; el servidor lo exige en toda petición que cambia datos
+const csrfToken = document.querySelector('meta[name="csrf-token"]').content;
+
+// Enviar el token CSRF en cada petición de htmx
+document.addEventListener('htmx:configRequest', function(event) {
+    event.detail.headers['X-CSRF-Token'] = csrfToken;
+});
+
+// Mostrar al usuario los errores problem+json que devuelve la API
+document.addEventListener('htmx:responseError', function(event) {
+    let message = 'Error inesperado del servidor';
+    try {
+        const problem = JSON.parse(event.detail.xhr.responseText);
+        const fields = (problem.errors || []).map(function(error) { return error.message; });
+        message = fields.length > 0 ? fields.join('\n') : problem.detail;
+    } catch (e) {}
+    alert(message);
+});
+
+// Recordar el filtro activo para que las recargas en vivo lo respeten
+document.addEventListener('click', function(event) {
+    const button = event.target.closest('.filter-btn');
+    const current = document.getElementById('currentFilter');
+    if (button && current) {
+        current.value = button.dataset.filter;
+    }
+});
+
+// Campos numéricos de un todo; vacíos no se envían
+const integerFields = ['estimate', 'list_id', 'assignee_id'];
+
+// Extensión json-form: envía los valores del formulario y de hx-vals como
+// JSON, con los números y el checkbox de completada con su tipo
+htmx.defineExtension('json-form', {
+    onEvent: function(name, event) {
+        if (name === 'htmx:configRequest') {
+            event.detail.headers['Content-Type'] = 'application/json';
+        }
+    },
+    encodeParameters: function(xhr, parameters, elt) {
+        xhr.overrideMimeType('text/json');
+        const data = {};
+        for (const key in parameters) {
+            const value = parameters[key];
+            if (key === 'completed') {
+                data[key] = value === true || value === 'on' || value === 'true';
+            } else if (integerFields.includes(key) && typeof value === 'string') {
+                if (value !== '') {
+                    data[key] = parseInt(value, 10) || 0;
+                }
+            } else {
+                data[key] = value;
+            }
+        }
+        // Un checkbox desmarcado no viaja en el formulario
+        if (elt.tagName === 'FORM' && elt.querySelector('input[name="completed"]') && !('completed' in data)) {
+            data.completed = false;
+        }
+        return JSON.stringify(data);
+    }
+});
@@ -5,11 +5,25 @@ const API_BASE_URL = 'http://localhost:8080/api/v1';
 let todos = [];
 let currentFilter = 'all';
 let editingTodoId = null;
+let selectedIds = new Set();
+
+// Sesión: usuario actual y si el formulario está en modo registro
+let currentUser = null;
+let registering = false;
+
+// Estado del canal de colaboración (WebSocket)
+const LOCK_RENEW_MS = 15000;
+let collabSocket = null;
+let collabClientId = null;
+let participants = new Map();
+let editLocks = new Map();
+let lockRenewal = null;
 
 // Elementos del DOM
 const todoForm = document.getElementById('todoForm');
 const todoTitle = document.getElementById('todoTitle');
 const todoDescription = document.getElementById('todoDescription');
+const todoEstimate = document.getElementById('todoEstimate');
 const submitBtn = document.getElementById('submitBtn');
 const cancelBtn = document.getElementById('cancelBtn');
 const todoList = document.getElementById('todoList');
@@ -26,18 +40,143 @@ const editForm = document.getElementById('editForm');
 const editTitle = document.getElementById('editTitle');
 const editDescription = document.getElementById('editDescription');
 const editCompleted = document.getElementById('editCompleted');
+const editEstimate = document.getElementById('editEstimate');
 const closeModal = document.getElementById('closeModal');
 const cancelEdit = document.getElementById('cancelEdit');
 const saveEdit = document.getElementById('saveEdit');
+const bulkActions = document.getElementById('bulkActions');
+const selectedCount = document.getElementById('selectedCount');
+const bulkComplete = document.getElementById('bulkComplete');
+const bulkDelete = document.getElementById('bulkDelete');
+const bulkClear = document.getElementById('bulkClear');
+const editLockWarning = document.getElementById('editLockWarning');
+const appPanel = document.getElementById('appPanel');
+const authPanel = document.getElementById('authPanel');
+const authForm = document.getElementById('authForm');
+const authNameGroup = document.getElementById('authNameGroup');
+const authName = document.getElementById('authName');
+const authEmail = document.getElementById('authEmail');
+const authPassword = document.getElementById('authPassword');
+const authSubmit = document.getElementById('authSubmit');
+const authSwitch = document.getElementById('authSwitch');
+const authSwitchText = document.getElementById('authSwitchText');
+const userNav = document.getElementById('userNav');
+const userName = document.getElementById('userName');
+const logoutBtn = document.getElementById('logoutBtn');
 
-// Inicialización
+// Inicialización: la lista solo se carga si hay una sesión iniciada
 document.addEventListener('DOMContentLoaded', function() {
-    loadTodos();
     setupEventListeners();
+    checkSession();
 });
 
+// Consultar la sesión actual (la cookie es HttpOnly, así que se pregunta a la API)
+async function checkSession() {
+    try {
+        const response = await fetch(`${API_BASE_URL}/auth/me`);
+        const data = await response.json();
+        if (data.success) {
+            startApp(data.data);
+            return;
+        }
+    } catch (error) {
+        showError('Error de conexión: ' + error.message);
+    }
+    showAuth();
+}
+
+// Mostrar la lista del usuario y abrir los canales en tiempo real
+function startApp(user) {
+    currentUser = user;
+    userName.textContent = user.name;
+    userNav.style.display = 'block';
+    authPanel.style.display = 'none';
+    appPanel.style.display = 'block';
+    
+    loadTodos();
+    subscribeToChanges();
+    connectCollab();
+}
+
+// Mostrar el formulario de inicio de sesión
+function showAuth() {
+    currentUser = null;
+    userNav.style.display = 'none';
+    appPanel.style.display = 'none';
+    authPanel.style.display = 'block';
+}
+
+// Alternar entre iniciar sesión y crear cuenta
+function toggleRegistering(e) {
+    e.preventDefault();
+    registering = !registering;
+    authNameGroup.style.display = registering ? 'block' : 'none';
+    authName.required = registering;
+    authPassword.autocomplete = registering ? 'new-password' : 'current-password';
+    authPassword.placeholder = registering ? 'Contraseña (mínimo 8 caracteres)' : 'Contraseña';
+    authSubmit.innerHTML = registering
+        ? '<i class="fas fa-user-plus"></i> Crear cuenta'
+        : '<i class="fas fa-right-to-bracket"></i> Entrar';
+    authSwitchText.textContent = registering ? '¿Ya tienes cuenta?' : '¿No tienes cuenta?';
+    authSwitch.textContent = registering ? 'Inicia sesión' : 'Crea una';
+}
+
+// Iniciar sesión o crear la cuenta
+async function handleAuth(e) {
+    e.preventDefault();
+    hideError();
+    
+    const body = { email: authEmail.value.trim(), password: authPassword.value };
+    if (registering) {
+        body.name = authName.value.trim();
+    }
+    
+    try {
+        const response = await fetch(`${API_BASE_URL}/auth/${registering ? 'register' : 'login'}`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify(body)
+        });
+        const data = await response.json();
+        
+        if (data.success) {
+            authForm.reset();
+            startApp(data.data);
+        } else {
+            showError(problemMessage(data));
+        }
+    } catch (error) {
+        showError('Error de conexión: ' + error.message);
+    }
+}
+
+// Cerrar la sesión; recargar la página cierra también el feed y el WebSocket
+async function handleLogout() {
+    try {
+        await fetch(`${API_BASE_URL}/auth/logout`, { method: 'POST' });
+    } finally {
+        window.location.reload();
+    }
+}
+
+// fetch a la API; si la sesión venció vuelve al formulario de inicio de sesión
+async function apiFetch(url, options) {
+    const response = await fetch(url, options);
+    if (response.status === 401 && currentUser) {
+        showAuth();
+    }
+    return response;
+}
+
 // Configurar event listeners
 function setupEventListeners() {
+    // Sesión
+    authForm.addEventListener('submit', handleAuth);
+    authSwitch.addEventListener('click', toggleRegistering);
+    logoutBtn.addEventListener('click', handleLogout);
+    
     // Formulario principal
     todoForm.addEventListener('submit', handleSubmit);
     cancelBtn.addEventListener('click', cancelEditMode);
@@ -52,6 +191,11 @@ function setupEventListeners() {
     cancelEdit.addEventListener('click', closeEditModal);
     saveEdit.addEventListener('click', handleSaveEdit);
     
+    // Acciones en lote
+    bulkComplete.addEventListener('click', () => runBatch('complete'));
+    bulkDelete.addEventListener('click', () => runBatch('delete'));
+    bulkClear.addEventListener('click', clearSelection);
+    
     // Cerrar modal al hacer clic fuera
     editModal.addEventListener('click', (e) => {
         if (e.target === editModal) {
@@ -66,7 +210,7 @@ async function loadTodos() {
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos`);
+        const response = await apiFetch(`${API_BASE_URL}/todos`);
         const data = await response.json();
         
         if (data.success) {
@@ -74,7 +218,7 @@ async function loadTodos() {
             renderTodos();
             updateStats();
         } else {
-            showError('Error al cargar las tareas: ' + data.message);
+            showError('Error al cargar las tareas: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -83,12 +227,139 @@ async function loadTodos() {
     }
 }
 
+// Escuchar los cambios hechos desde otras pestañas o dispositivos (Server-Sent
+// Events). EventSource reconecta solo y envía Last-Event-ID para no perder cambios
+function subscribeToChanges() {
+    if (!window.EventSource) {
+        return;
+    }
+    
+    const events = new EventSource(`${API_BASE_URL}/events`);
+    events.addEventListener('change', (e) => applyChange(JSON.parse(e.data)));
+    events.addEventListener('reset', () => loadTodos());
+}
+
+// Aplicar un cambio recibido por el feed de eventos
+function applyChange(change) {
+    const index = todos.findIndex(t => t.id === change.todo_id);
+    const visible = change.todo && !change.todo.archived;
+    
+    if (!visible) {
+        if (index < 0) return;
+        todos.splice(index, 1);
+    } else if (index < 0) {
+        todos.push(change.todo);
+    } else if (todos[index].version < change.todo.version) {
+        todos[index] = change.todo;
+    } else {
+        // Ya teníamos esta versión (por ejemplo, el cambio lo hicimos nosotros)
+        return;
+    }
+    
+    renderTodos();
+    updateStats();
+    updateBulkActions();
+}
+
+// Conectar al canal de colaboración; reconecta con espera creciente mientras
+// haya sesión. Las otras pestañas nos ven con el nombre de la cuenta
+function connectCollab(delay = 1000) {
+    if (!window.WebSocket || !currentUser) {
+        return;
+    }
+    
+    const url = API_BASE_URL.replace(/^http/, 'ws') + '/ws';
+    collabSocket = new WebSocket(url);
+    collabSocket.onopen = () => {
+        delay = 1000;
+        if (editingTodoId) {
+            sendCollab({ type: 'edit', todo_id: editingTodoId });
+        }
+    };
+    collabSocket.onmessage = (e) => handleCollabMessage(JSON.parse(e.data));
+    collabSocket.onclose = () => {
+        participants.clear();
+        editLocks.clear();
+        renderTodos();
+        setTimeout(() => connectCollab(Math.min(delay * 2, 30000)), delay);
+    };
+}
+
+// Enviar un mensaje por el canal de colaboración si está abierto
+function sendCollab(message) {
+    if (collabSocket && collabSocket.readyState === WebSocket.OPEN) {
+        collabSocket.send(JSON.stringify(message));
+    }
+}
+
+// Procesar un mensaje del canal de colaboración
+function handleCollabMessage(message) {
+    switch (message.type) {
+        case 'welcome':
+            collabClientId = message.client_id;
+            participants = new Map((message.participants || []).map(p => [p.client_id, p]));
+            editLocks = new Map((message.locks || []).map(lock => [lock.todo_id, lock]));
+            break;
+        case 'presence':
+            if (message.participant.state === 'left') {
+                participants.delete(message.participant.client_id);
+            } else {
+                participants.set(message.participant.client_id, message.participant);
+            }
+            break;
+        case 'lock':
+        case 'lock_denied':
+            editLocks.set(message.todo_id, message.lock);
+            break;
+        case 'unlock':
+            editLocks.delete(message.todo_id);
+            break;
+        case 'error':
+            console.warn('Colaboración:', message.message);
+            return;
+        default:
+            // Los cambios ya llegan por Server-Sent Events
+            return;
+    }
+    
+    renderTodos();
+    updateEditLockWarning();
+}
+
+// Quién más está mirando o editando un todo
+function presenceBadges(id) {
+    const lock = editLocks.get(id);
+    if (lock && lock.client_id !== collabClientId) {
+        return `<span class="todo-presence editing"><i class="fas fa-pen"></i> ${escapeHtml(lock.name)} está editando</span>`;
+    }
+    
+    const viewers = [...participants.values()]
+        .filter(p => p.todo_id === id && p.client_id !== collabClientId)
+        .map(p => escapeHtml(p.name));
+    if (viewers.length === 0) {
+        return '';
+    }
+    return `<span class="todo-presence"><i class="fas fa-eye"></i> ${viewers.join(', ')}</span>`;
+}
+
+// Avisar en el modal si otra persona está editando la misma tarea
+function updateEditLockWarning() {
+    const lock = editingTodoId ? editLocks.get(editingTodoId) : null;
+    if (lock && lock.client_id !== collabClientId) {
+        editLockWarning.innerHTML = `<i class="fas fa-user-pen"></i> ${escapeHtml(lock.name)} está editando esta tarea; si guardas, podrías pisar sus cambios.`;
+        editLockWarning.style.display = 'block';
+    } else {
+        editLockWarning.style.display = 'none';
+    }
+}
+
 // Manejar envío del formulario
 async function handleSubmit(e) {
     e.preventDefault();
     
     const title = todoTitle.value.trim();
     const description = todoDescription.value.trim();
+    const estimate = parseInt(todoEstimate.value, 10) || 0;
     
     if (!title) {
         showError('El título es requerido');
@@ -96,19 +367,19 @@ async function handleSubmit(e) {
     }
     
     if (editingTodoId) {
-        await updateTodo(editingTodoId, title, description);
+        await updateTodo(editingTodoId, title, description, estimate);
     } else {
-        await createTodo(title, description);
+        await createTodo(title, description, estimate);
     }
 }
 
 // Crear nuevo todo
-async function createTodo(title, description) {
+async function createTodo(title, description, estimate) {
     showLoading(true);
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos`, {
+        const response = await apiFetch(`${API_BASE_URL}/todos`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
@@ -116,7 +387,8 @@ async function createTodo(title, description) {
             body: JSON.stringify({
                 title: title,
                 description: description,
-                completed: false
+                completed: false,
+                estimate: estimate
             })
         });
         
@@ -129,7 +401,7 @@ async function createTodo(title, description) {
             resetForm();
             showSuccess('Tarea creada exitosamente');
         } else {
-            showError('Error al crear la tarea: ' + data.message);
+            showError('Error al crear la tarea: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -139,25 +411,32 @@ async function createTodo(title, description) {
 }
 
 // Actualizar todo
-async function updateTodo(id, title, description) {
+async function updateTodo(id, title, description, estimate) {
     showLoading(true);
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos/${id}`, {
+        const response = await apiFetch(`${API_BASE_URL}/todos/${id}`, {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
+                'If-Match': etagFor(id),
             },
             body: JSON.stringify({
                 title: title,
                 description: description,
-                completed: false
+                completed: false,
+                estimate: estimate
             })
         });
         
         const data = await response.json();
         
+        if (response.status === 412) {
+            await handleConflict(data.detail);
+            return;
+        }
+        
         if (data.success) {
             const index = todos.findIndex(todo => todo.id === id);
             if (index !== -1) {
@@ -168,7 +447,7 @@ async function updateTodo(id, title, description) {
             resetForm();
             showSuccess('Tarea actualizada exitosamente');
         } else {
-            showError('Error al actualizar la tarea: ' + data.message);
+            showError('Error al actualizar la tarea: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -187,19 +466,28 @@ async function deleteTodo(id) {
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos/${id}`, {
-            method: 'DELETE'
+        const response = await apiFetch(`${API_BASE_URL}/todos/${id}`, {
+            method: 'DELETE',
+            headers: {
+                'If-Match': etagFor(id),
+            }
         });
         
         const data = await response.json();
         
+        if (response.status === 412) {
+            await handleConflict(data.detail);
+            return;
+        }
+        
         if (data.success) {
             todos = todos.filter(todo => todo.id !== id);
             renderTodos();
             updateStats();
+            updateBulkActions();
             showSuccess('Tarea eliminada exitosamente');
         } else {
-            showError('Error al eliminar la tarea: ' + data.message);
+            showError('Error al eliminar la tarea: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -217,20 +505,27 @@ async function toggleTodo(id) {
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos/${id}`, {
+        const response = await apiFetch(`${API_BASE_URL}/todos/${id}`, {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
+                'If-Match': etagFor(id),
             },
             body: JSON.stringify({
                 title: todo.title,
                 description: todo.description,
-                completed: !todo.completed
+                completed: !todo.completed,
+                estimate: todo.estimate
             })
         });
         
         const data = await response.json();
         
+        if (response.status === 412) {
+            await handleConflict(data.detail);
+            return;
+        }
+        
         if (data.success) {
             const index = todos.findIndex(t => t.id === id);
             if (index !== -1) {
@@ -239,7 +534,7 @@ async function toggleTodo(id) {
             renderTodos();
             updateStats();
         } else {
-            showError('Error al actualizar la tarea: ' + data.message);
+            showError('Error al actualizar la tarea: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -257,9 +552,15 @@ function editTodo(id) {
     editTitle.value = todo.title;
     editDescription.value = todo.description;
     editCompleted.checked = todo.completed;
+    editEstimate.value = todo.estimate || 0;
     
     editModal.style.display = 'block';
     document.body.style.overflow = 'hidden';
+    
+    // Avisar a los demás que estamos editando y renovar el bloqueo mientras el modal esté abierto
+    sendCollab({ type: 'edit', todo_id: id });
+    lockRenewal = setInterval(() => sendCollab({ type: 'edit', todo_id: id }), LOCK_RENEW_MS);
+    updateEditLockWarning();
 }
 
 // Guardar edición
@@ -267,6 +568,7 @@ async function handleSaveEdit() {
     const title = editTitle.value.trim();
     const description = editDescription.value.trim();
     const completed = editCompleted.checked;
+    const estimate = parseInt(editEstimate.value, 10) || 0;
     
     if (!title) {
         showError('El título es requerido');
@@ -277,20 +579,27 @@ async function handleSaveEdit() {
     hideError();
     
     try {
-        const response = await fetch(`${API_BASE_URL}/todos/${editingTodoId}`, {
+        const response = await apiFetch(`${API_BASE_URL}/todos/${editingTodoId}`, {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
+                'If-Match': etagFor(editingTodoId),
             },
             body: JSON.stringify({
                 title: title,
                 description: description,
-                completed: completed
+                completed: completed,
+                estimate: estimate
             })
         });
         
         const data = await response.json();
         
+        if (response.status === 412) {
+            await handleConflict(data.detail);
+            return;
+        }
+        
         if (data.success) {
             const index = todos.findIndex(todo => todo.id === editingTodoId);
             if (index !== -1) {
@@ -301,7 +610,7 @@ async function handleSaveEdit() {
             closeEditModal();
             showSuccess('Tarea actualizada exitosamente');
         } else {
-            showError('Error al actualizar la tarea: ' + data.message);
+            showError('Error al actualizar la tarea: ' + problemMessage(data));
         }
     } catch (error) {
         showError('Error de conexión: ' + error.message);
@@ -341,14 +650,22 @@ function renderTodos() {
     todoList.innerHTML = filteredTodos.map(todo => `
         <div class="todo-item ${todo.completed ? 'completed' : ''}" data-id="${todo.id}">
             <div class="todo-header">
+                <input type="checkbox" class="todo-select" title="Seleccionar"
+                       onchange="toggleSelection(${todo.id}, this.checked)"
+                       ${selectedIds.has(todo.id) ? 'checked' : ''}>
                 <div>
                     <div class="todo-title">${escapeHtml(todo.title)}</div>
-                    ${todo.description ? `<div class="todo-description">${escapeHtml(todo.description)}</div>` : ''}
+                    ${todo.description_html ? `<div class="todo-description">${todo.description_html}</div>` : ''}
                 </div>
             </div>
             <div class="todo-meta">
                 <span><i class="fas fa-calendar"></i> ${formatDate(todo.created_at)}</span>
                 <span><i class="fas fa-clock"></i> ${formatDate(todo.updated_at)}</span>
+                ${todo.estimate ? `<span><i class="fas fa-weight-hanging"></i> ${todo.estimate} pts</span>` : ''}
+                ${todo.due_date ? `<span><i class="fas fa-flag"></i> ${formatDay(todo.due_date)}</span>` : ''}
+                ${todo.checklist && todo.checklist.length ? `<span><i class="fas fa-list-check"></i> ${todo.checklist.filter(item => item.done).length}/${todo.checklist.length}</span>` : ''}
+                ${(todo.tags || []).map(tag => `<span class="todo-tag">#${escapeHtml(tag)}</span>`).join('')}
+                ${presenceBadges(todo.id)}
             </div>
             <div class="todo-actions">
                 <button class="btn ${todo.completed ? 'btn-secondary' : 'btn-success'}" 
@@ -367,6 +684,76 @@ function renderTodos() {
     `).join('');
 }
 
+// Seleccionar o deseleccionar un todo para acciones en lote
+function toggleSelection(id, selected) {
+    if (selected) {
+        selectedIds.add(id);
+    } else {
+        selectedIds.delete(id);
+    }
+    updateBulkActions();
+}
+
+// Limpiar la selección
+function clearSelection() {
+    selectedIds.clear();
+    renderTodos();
+    updateBulkActions();
+}
+
+// Mostrar la barra de acciones en lote según la selección
+function updateBulkActions() {
+    // Descartar ids de todos que ya no existen
+    selectedIds.forEach(id => {
+        if (!todos.some(todo => todo.id === id)) {
+            selectedIds.delete(id);
+        }
+    });
+    
+    selectedCount.textContent = `${selectedIds.size} seleccionadas`;
+    bulkActions.style.display = selectedIds.size > 0 ? 'flex' : 'none';
+}
+
+// Ejecutar una operación sobre todos los seleccionados en una sola petición
+async function runBatch(op) {
+    if (selectedIds.size === 0) return;
+    
+    if (op === 'delete' && !confirm(`¿Eliminar ${selectedIds.size} tareas?`)) {
+        return;
+    }
+    
+    showLoading(true);
+    hideError();
+    
+    try {
+        const response = await apiFetch(`${API_BASE_URL}/todos/batch`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({
+                operations: Array.from(selectedIds).map(id => ({ op: op, id: id }))
+            })
+        });
+        
+        const data = await response.json();
+        
+        if (data.success) {
+            selectedIds.clear();
+            await loadTodos();
+            updateBulkActions();
+            showSuccess(op === 'delete' ? 'Tareas eliminadas exitosamente' : 'Tareas completadas exitosamente');
+        } else {
+            const failed = (data.results || []).find(result => result.status !== 424 && !result.success);
+            showError('Error en la operación en lote: ' + (failed ? `#${failed.id}: ${failed.message}` : problemMessage(data)));
+        }
+    } catch (error) {
+        showError('Error de conexión: ' + error.message);
+    } finally {
+        showLoading(false);
+    }
+}
+
 // Obtener todos filtrados
 function getFilteredTodos() {
     switch (currentFilter) {
@@ -407,7 +794,14 @@ function cancelEditMode() {
 function closeEditModal() {
     editModal.style.display = 'none';
     document.body.style.overflow = 'auto';
+    
+    if (editingTodoId) {
+        sendCollab({ type: 'release', todo_id: editingTodoId });
+        sendCollab({ type: 'view', todo_id: 0 });
+    }
+    clearInterval(lockRenewal);
     editingTodoId = null;
+    updateEditLockWarning();
 }
 
 // Mostrar/ocultar loading
@@ -454,6 +848,26 @@ function showSuccess(message) {
     }, 3000);
 }
 
+// Obtener el ETag de un todo para enviar en If-Match
+function etagFor(id) {
+    const todo = todos.find(t => t.id === id);
+    return todo ? `"v${todo.version}"` : '*';
+}
+
+// Otra persona modificó la tarea: avisar y recargar la lista
+async function handleConflict(message) {
+    showError(message || 'La tarea fue modificada por otra persona');
+    await loadTodos();
+}
+
+// Mensaje legible de un error problem+json, con los errores por campo si los hay
+function problemMessage(problem) {
+    if (problem.errors && problem.errors.length > 0) {
+        return problem.errors.map(error => error.message).join(', ');
+    }
+    return problem.detail || problem.title || 'Error desconocido';
+}
+
 // Utilidades
 function escapeHtml(text) {
     const div = document.createElement('div');
@@ -472,6 +886,15 @@ function formatDate(dateString) {
     });
 }
 
+function formatDay(dateString) {
+    const date = new Date(dateString);
+    return date.toLocaleDateString('es-ES', {
+        year: 'numeric',
+        month: 'short',
+        day: 'numeric'
+    });
+}
+
 // Agregar estilos para animaciones
 const style = document.createElement('style');
 style.textContent = `
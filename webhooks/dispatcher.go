@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-list/events"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// Headers que acompañan a cada entrega
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxConcurrent es cuántas entregas se envían a la vez
+const maxConcurrent = 8
+
+// Policy define los tiempos de entrega y reintento
+type Policy struct {
+	// MaxAttempts es cuántos intentos se hacen antes de pasar a dead letters
+	MaxAttempts int
+	// BaseDelay es la espera antes del primer reintento; se duplica en cada uno
+	BaseDelay time.Duration
+	// MaxDelay es la espera máxima entre reintentos
+	MaxDelay time.Duration
+	// Timeout es cuánto se espera la respuesta del receptor
+	Timeout time.Duration
+	// AllowPrivate permite entregar a direcciones privadas, locales o de
+	// metadatos; solo para desarrollo y pruebas
+	AllowPrivate bool
+}
+
+// Backoff calcula la espera antes del reintento que sigue al intento attempt
+// (1 para el primero): BaseDelay, 2×BaseDelay, 4×BaseDelay... hasta MaxDelay
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Dispatcher envía los cambios publicados en el broker a los webhooks
+// suscritos, firmados con el secreto de cada uno, y reintenta los que fallan
+type Dispatcher struct {
+	webhooks *store.WebhookStore
+	broker   *events.Broker
+	policy   Policy
+	client   *http.Client
+	slots    chan struct{}
+	now      func() time.Time
+}
+
+// NewDispatcher crea un dispatcher que lee los cambios de broker y usa
+// webhooks como registro y cola de entregas
+func NewDispatcher(webhooks *store.WebhookStore, broker *events.Broker, policy Policy) *Dispatcher {
+	return &Dispatcher{
+		webhooks: webhooks,
+		broker:   broker,
+		policy:   policy,
+		client: &http.Client{
+			Timeout:   policy.Timeout,
+			Transport: newTransport(policy.AllowPrivate),
+			// Una redirección cuenta como fallo: el receptor debe dar la URL final
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		slots: make(chan struct{}, maxConcurrent),
+		now:   time.Now,
+	}
+}
+
+// Start ejecuta el dispatcher en segundo plano hasta que se cancele ctx
+func (d *Dispatcher) Start(ctx context.Context) {
+	go d.run(ctx)
+}
+
+// run encola los cambios nuevos y envía las entregas cuando les toca
+func (d *Dispatcher) run(ctx context.Context) {
+	sub := d.broker.Subscribe(0)
+	lastID := sub.LastID
+	timer := time.NewTimer(time.Hour)
+	defer func() {
+		timer.Stop()
+		d.broker.Unsubscribe(sub)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// El broker nos desconectó por lentos: retomar desde el último cambio
+				sub = d.broker.Subscribe(lastID)
+				if sub.Reset {
+					log.Printf("webhooks: se perdieron cambios posteriores a %d", lastID)
+				}
+				for _, missed := range sub.Missed {
+					d.enqueue(missed)
+					lastID = missed.ID
+				}
+				continue
+			}
+			d.enqueue(event)
+			lastID = event.ID
+		case <-d.webhooks.Wake():
+		case <-timer.C:
+		}
+
+		d.dispatch(ctx)
+		d.schedule(timer)
+	}
+}
+
+// enqueue crea las entregas de un cambio
+func (d *Dispatcher) enqueue(event models.ChangeEvent) {
+	if _, err := d.webhooks.Enqueue(event); err != nil {
+		log.Printf("webhooks: no se pudo encolar el cambio %d: %v", event.ID, err)
+	}
+}
+
+// dispatch envía en segundo plano las entregas que ya deben intentarse
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for _, claim := range d.webhooks.ClaimDue(d.now()) {
+		go func(claim store.DeliveryClaim) {
+			select {
+			case d.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-d.slots }()
+			d.deliver(ctx, claim)
+		}(claim)
+	}
+}
+
+// schedule programa el timer para el próximo reintento pendiente
+func (d *Dispatcher) schedule(timer *time.Timer) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	if next, ok := d.webhooks.NextDue(); ok {
+		wait := next.Sub(d.now())
+		if wait < 0 {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// deliver hace un intento de entrega y guarda el resultado: éxito, reintento
+// con backoff exponencial o dead letter si ya no quedan intentos
+func (d *Dispatcher) deliver(ctx context.Context, claim store.DeliveryClaim) {
+	start := d.now()
+	status, err := d.send(ctx, claim, start)
+	attempt := models.WebhookAttempt{
+		Attempt:    len(claim.Delivery.Log) + 1,
+		At:         start,
+		StatusCode: status,
+		DurationMs: d.now().Sub(start).Milliseconds(),
+	}
+
+	var retryAt *time.Time
+	if err != nil {
+		attempt.Error = err.Error()
+		if claim.Delivery.Attempts+1 < d.policy.MaxAttempts {
+			next := d.now().Add(d.policy.Backoff(claim.Delivery.Attempts + 1))
+			retryAt = &next
+		}
+	}
+	// Si el webhook se eliminó mientras se enviaba ya no hay dónde guardarlo
+	d.webhooks.RecordAttempt(claim.Delivery.ID, attempt, retryAt)
+}
+
+// send envía el payload firmado; cualquier respuesta fuera de 2xx es un error
+func (d *Dispatcher) send(ctx context.Context, claim store.DeliveryClaim, at time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, claim.URL, bytes.NewReader(claim.Delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-list-webhooks/1.0")
+	req.Header.Set(HeaderEvent, claim.Delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(claim.Delivery.ID))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(claim.Secret, timestamp, claim.Delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("el receptor respondió %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-list/events"
+	"todo-list/models"
+	"todo-list/store"
+)
+
+// received es una entrega tal como la vio el receptor de prueba
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver es un endpoint de prueba que guarda lo que recibe y responde status
+type receiver struct {
+	mu       sync.Mutex
+	requests []received
+	status   atomic.Int32
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rec := &receiver{}
+	rec.status.Store(int32(status))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, received{header: r.Header.Clone(), body: body})
+		rec.mu.Unlock()
+		w.WriteHeader(int(rec.status.Load()))
+	}))
+	t.Cleanup(server.Close)
+	return rec, server
+}
+
+func (rec *receiver) all() []received {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]received(nil), rec.requests...)
+}
+
+// setup arranca un broker, un store de webhooks y su dispatcher
+func setup(t *testing.T, policy Policy) (*events.Broker, *store.WebhookStore) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	broker := events.NewBroker(100, time.Minute)
+	webhookStore := store.NewWebhookStore()
+	NewDispatcher(webhookStore, broker, policy).Start(ctx)
+	// Dar tiempo a que el dispatcher se suscriba al broker
+	time.Sleep(20 * time.Millisecond)
+	return broker, webhookStore
+}
+
+// waitFor espera hasta que cond se cumpla o falla el test
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("tiempo agotado esperando %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDeliverySignedAndFiltered(t *testing.T) {
+	rec, server := newReceiver(t, http.StatusNoContent)
+	broker, webhookStore := setup(t, Policy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, Timeout: time.Second, AllowPrivate: true})
+
+	webhook, err := webhookStore.Create(context.Background(), models.WebhookRequest{
+		URL:    server.URL,
+		Events: []string{models.EventTodoCompleted, models.EventTodoDeleted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if webhook.Secret == "" {
+		t.Fatal("se esperaba un secreto generado")
+	}
+
+	todoStore := store.NewTodoStore()
+	todoStore.SetPublisher(broker)
+	ctx := context.Background()
+	todo, _ := todoStore.Create(ctx, models.TodoRequest{Title: "Publicar release"})
+	todoStore.Update(ctx, todo.ID, models.TodoRequest{Title: "Publicar release v2"})
+	todoStore.Update(ctx, todo.ID, models.TodoRequest{Title: "Publicar release v2", Completed: true})
+
+	waitFor(t, "la entrega de todo.completed", func() bool { return len(rec.all()) == 1 })
+	// Ni todo.created ni todo.updated deben llegar
+	time.Sleep(50 * time.Millisecond)
+	requests := rec.all()
+	if len(requests) != 1 {
+		t.Fatalf("se esperaba 1 entrega, llegaron %d", len(requests))
+	}
+
+	got := requests[0]
+	if event := got.header.Get(HeaderEvent); event != models.EventTodoCompleted {
+		t.Fatalf("%s = %q, se esperaba %q", HeaderEvent, event, models.EventTodoCompleted)
+	}
+	timestamp := got.header.Get(HeaderTimestamp)
+	signature := got.header.Get(HeaderSignature)
+	if !Verify(webhook.Secret, timestamp, got.body, signature) {
+		t.Fatalf("firma inválida: %q", signature)
+	}
+	if Verify("otro-secreto-cualquiera", timestamp, got.body, signature) {
+		t.Fatal("la firma no debería validar con otro secreto")
+	}
+
+	var event models.ChangeEvent
+	if err := json.Unmarshal(got.body, &event); err != nil {
+		t.Fatalf("payload inválido: %v", err)
+	}
+	if event.Type != models.EventTodoCompleted || event.TodoID != todo.ID || event.Todo == nil || !event.Todo.Completed {
+		t.Fatalf("payload inesperado: %+v", event)
+	}
+
+	waitFor(t, "el registro de la entrega", func() bool {
+		deliveries, _ := webhookStore.Deliveries(ctx, webhook.ID)
+		return len(deliveries) == 1 && deliveries[0].Status == models.DeliverySucceeded
+	})
+	deliveries, _ := webhookStore.Deliveries(ctx, webhook.ID)
+	if log := deliveries[0].Log; len(log) != 1 || log[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("registro inesperado: %+v", log)
+	}
+}
+
+func TestRetriesThenDeadLetterAndRedeliver(t *testing.T) {
+	rec, server := newReceiver(t, http.StatusInternalServerError)
+	broker, webhookStore := setup(t, Policy{MaxAttempts: 3, BaseDelay: 20 * time.Millisecond, MaxDelay: time.Second, Timeout: time.Second, AllowPrivate: true})
+	ctx := context.Background()
+
+	webhook, err := webhookStore.Create(ctx, models.WebhookRequest{
+		URL:    server.URL,
+		Events: []string{models.EventTodoCreated},
+		Secret: "un-secreto-de-prueba-largo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.Publish(models.ChangeEvent{Type: models.EventTodoCreated, TodoID: 1, Audience: []int{0}, At: time.Now()})
+
+	waitFor(t, "la dead letter", func() bool { return len(webhookStore.DeadLetters(ctx)) == 1 })
+	dead := webhookStore.DeadLetters(ctx)[0]
+	if dead.WebhookID != webhook.ID || dead.Attempts != 3 || len(dead.Log) != 3 {
+		t.Fatalf("dead letter inesperada: %+v", dead)
+	}
+	for _, attempt := range dead.Log {
+		if attempt.StatusCode != http.StatusInternalServerError || attempt.Error == "" {
+			t.Fatalf("intento inesperado: %+v", attempt)
+		}
+	}
+	// El backoff es exponencial: 20ms y luego 40ms
+	if gap := dead.Log[2].At.Sub(dead.Log[1].At); gap < 40*time.Millisecond {
+		t.Fatalf("el segundo reintento llegó tras %s, se esperaban al menos 40ms", gap)
+	}
+	if len(rec.all()) != 3 {
+		t.Fatalf("el receptor vio %d intentos, se esperaban 3", len(rec.all()))
+	}
+
+	if _, err := webhookStore.Redeliver(ctx, dead.ID+100); err != store.ErrDeliveryNotFound {
+		t.Fatalf("se esperaba ErrDeliveryNotFound, se obtuvo %v", err)
+	}
+
+	rec.status.Store(http.StatusOK)
+	if _, err := webhookStore.Redeliver(ctx, dead.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "la reentrega", func() bool {
+		deliveries, _ := webhookStore.Deliveries(ctx, webhook.ID)
+		return deliveries[0].Status == models.DeliverySucceeded
+	})
+	if n := len(webhookStore.DeadLetters(ctx)); n != 0 {
+		t.Fatalf("quedaron %d dead letters", n)
+	}
+	if _, err := webhookStore.Redeliver(ctx, dead.ID); err != store.ErrDeliveryNotDead {
+		t.Fatalf("se esperaba ErrDeliveryNotDead, se obtuvo %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{BaseDelay: time.Second, MaxDelay: 5 * time.Second, AllowPrivate: true}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		if got := policy.Backoff(i + 1); got != expected {
+			t.Errorf("Backoff(%d) = %s, se esperaba %s", i+1, got, expected)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// signaturePrefix identifica el algoritmo en el header de la firma
+const signaturePrefix = "sha256="
+
+// Sign firma una entrega: HMAC-SHA256 con el secreto del webhook sobre
+// "<timestamp>.<cuerpo>", en hexadecimal y con el prefijo sha256=
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify comprueba la firma de una entrega en tiempo constante. Los receptores
+// escritos en Go pueden usarla tal cual
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
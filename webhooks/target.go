@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedTarget se retorna cuando el destino de un webhook es una
+// dirección privada, local o de metadatos de la nube
+var ErrBlockedTarget = errors.New("el destino es una dirección privada, local o de metadatos")
+
+// blockedNets son los rangos que no cubren los métodos de net.IP: "esta red",
+// CGNAT (metadatos de Alibaba), protocolos IETF (metadatos de Oracle),
+// pruebas de rendimiento y NAT64, que puede envolver una IPv4 privada
+var blockedNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),
+	mustCIDR("100.64.0.0/10"),
+	mustCIDR("192.0.0.0/24"),
+	mustCIDR("198.18.0.0/15"),
+	mustCIDR("64:ff9b::/96"),
+}
+
+// mustCIDR interpreta un rango fijo de blockedNets; falla al iniciar si es inválido
+func mustCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// blockedIP indica si ip es una dirección a la que no se envían webhooks:
+// loopback, privadas, link-local (incluye 169.254.169.254), multicast y los
+// rangos de blockedNets
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNets {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTarget revisa al registrar un webhook que su URL no apunte a una
+// dirección bloqueada: resuelve el host y revisa todas sus IPs. Un host que
+// todavía no resuelve se acepta; el dial de cada entrega lo vuelve a revisar
+func CheckTarget(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedIP(ip) {
+			return fmt.Errorf("%s: %w", host, ErrBlockedTarget)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if blockedIP(addr.IP) {
+			return fmt.Errorf("%s resuelve a %s: %w", host, addr.IP, ErrBlockedTarget)
+		}
+	}
+	return nil
+}
+
+// dialControl revisa la IP a la que se conecta cada entrega, ya resuelta.
+// Así un DNS que cambia de respuesta después de CheckTarget (DNS rebinding)
+// tampoco llega a la red interna
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+		return fmt.Errorf("%s: %w", host, ErrBlockedTarget)
+	}
+	return nil
+}
+
+// newTransport crea el transporte de las entregas. Salvo con allowPrivate,
+// el dial rechaza las direcciones bloqueadas y no se usa el proxy del
+// entorno, que resolvería el host por su cuenta
+func newTransport(allowPrivate bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if allowPrivate {
+		return transport
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return transport
+}
@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-list/models"
+)
+
+func TestCheckTargetRejectsInternalAddresses(t *testing.T) {
+	blocked := []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.100.100.200/",
+		"http://0.0.0.0/",
+		"http://[::1]/hook",
+		"http://[fd00:ec2::254]/",
+		"http://[::ffff:127.0.0.1]/",
+	}
+	for _, url := range blocked {
+		if err := CheckTarget(context.Background(), url); !errors.Is(err, ErrBlockedTarget) {
+			t.Errorf("%s: err = %v, se esperaba ErrBlockedTarget", url, err)
+		}
+	}
+	for _, url := range []string{"https://93.184.216.34/hook", "https://[2606:4700::1111]/hook"} {
+		if err := CheckTarget(context.Background(), url); err != nil {
+			t.Errorf("%s: %v", url, err)
+		}
+	}
+}
+
+func TestDispatcherDoesNotDialInternalAddresses(t *testing.T) {
+	// Sin AllowPrivate el receptor, que escucha en loopback, nunca recibe nada
+	// aunque la URL haya llegado al store sin pasar por CheckTarget
+	rec, server := newReceiver(t, http.StatusOK)
+	broker, webhookStore := setup(t, Policy{MaxAttempts: 1, Timeout: time.Second})
+	ctx := context.Background()
+	hook, err := webhookStore.Create(ctx, models.WebhookRequest{URL: server.URL, Events: []string{models.EventTodoCreated}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.Publish(models.ChangeEvent{Type: models.EventTodoCreated, TodoID: 1, Audience: []int{0}, At: time.Now()})
+
+	var deliveries []models.WebhookDelivery
+	waitFor(t, "la entrega fallida", func() bool {
+		deliveries, _ = webhookStore.Deliveries(ctx, hook.ID)
+		return len(deliveries) == 1 && deliveries[0].Status == models.DeliveryDead
+	})
+	if got := len(rec.all()); got != 0 {
+		t.Fatalf("el receptor recibió %d entregas", got)
+	}
+	if log := deliveries[0].Log; len(log) != 1 || log[0].StatusCode != 0 || log[0].Error == "" {
+		t.Fatalf("intentos inesperados: %+v", log)
+	}
+}